@@ -0,0 +1,220 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	pb "napster"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// Bitfield records which chunks of a file a peer holds: bit i, most
+// significant first, is set when chunk i is held.
+type Bitfield []byte
+
+func newBitfield(numChunks int) Bitfield {
+	return make(Bitfield, (numChunks+7)/8)
+}
+
+func fullBitfield(numChunks int) Bitfield {
+	have := newBitfield(numChunks)
+	for i := range numChunks {
+		have.Set(i)
+	}
+	return have
+}
+
+func (b Bitfield) Has(i int) bool {
+	return i >= 0 && i/8 < len(b) && b[i/8]&(0x80>>(i%8)) != 0
+}
+
+func (b Bitfield) Set(i int) {
+	b[i/8] |= 0x80 >> (i % 8)
+}
+
+// count returns how many of the first numChunks chunks are held.
+func (b Bitfield) count(numChunks int) int {
+	n := 0
+	for i := range numChunks {
+		if b.Has(i) {
+			n++
+		}
+	}
+	return n
+}
+
+// bitfield returns the chunks this download has verified so far.
+func (c *ChunkCoordinator) bitfield() Bitfield {
+	c.chunkMutex.Lock()
+	defer c.chunkMutex.Unlock()
+	have := newBitfield(c.numChunks)
+	for chunkID := range c.chunkData {
+		have.Set(chunkID)
+	}
+	return have
+}
+
+// addHolder records the chunks a peer holds. A peer holding them all joins
+// the ring as a seeder; one holding some is asked only for those. Peers that
+// failed earlier are left out until their cooldown is over. It reports
+// whether the peer was new to this download.
+func (c *ChunkCoordinator) addHolder(peer string, have Bitfield) bool {
+	held := have.count(c.numChunks)
+	if held == c.numChunks {
+		c.chunkMutex.Lock()
+		delete(c.holders, peer)
+		c.chunkMutex.Unlock()
+		return c.admit(peer)
+	}
+
+	c.chunkMutex.Lock()
+	defer c.chunkMutex.Unlock()
+	if _, dropped := c.dropped[peer]; dropped || held == 0 || slices.Contains(c.hashRing.Members(), peer) {
+		return false
+	}
+	_, known := c.holders[peer]
+	c.holders[peer] = have
+	return !known
+}
+
+// sources returns the seeders and partial holders of this download.
+func (c *ChunkCoordinator) sources() []string {
+	peers := c.hashRing.Members()
+	c.chunkMutex.Lock()
+	defer c.chunkMutex.Unlock()
+	for peer := range c.holders {
+		peers = append(peers, peer)
+	}
+	return peers
+}
+
+// GetBitfield tells a downloader which chunks of a file this peer holds,
+// and learns the downloader's in return when downloading the file too.
+func (p *PeerServer) GetBitfield(ctx context.Context, req *pb.BitfieldRequest) (*pb.BitfieldResponse, error) {
+	metadata := ParseTorrent(torrentPath(req.FileName))
+	if metadata.FileName == "" || (req.Checksum != "" && metadata.Checksum != req.Checksum) {
+		return &pb.BitfieldResponse{Status: 404}, nil
+	}
+
+	chunkCoordinator := p.activeDownload(req.FileName)
+	if chunkCoordinator != nil && req.Sender != "" && req.Sender != p.PeerAddress {
+		if chunkCoordinator.addHolder(req.Sender, Bitfield(req.Bitfield)) && debug_mode {
+			log.Printf("%s holds chunks of %s", req.Sender, req.FileName)
+		}
+	}
+
+	if slices.Contains(seededFiles(), req.FileName) {
+		return &pb.BitfieldResponse{Status: 200, Bitfield: fullBitfield(len(metadata.ChunkChecksums)), Seeding: true}, nil
+	}
+	if chunkCoordinator != nil {
+		if have := chunkCoordinator.bitfield(); have.count(chunkCoordinator.numChunks) > 0 {
+			return &pb.BitfieldResponse{Status: 200, Bitfield: have}, nil
+		}
+	}
+	return &pb.BitfieldResponse{Status: 404}, nil
+}
+
+// queryBitfield asks a peer which chunks of a download it holds, telling it
+// the chunks held here.
+func (p *PeerServer) queryBitfield(ctx context.Context, metadata TorrentMetadata, chunkCoordinator *ChunkCoordinator, addr string) (Bitfield, error) {
+	conn, err := grpc.NewClient(addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithTimeout(ctx, pexTimeout)
+	defer cancel()
+	res, err := pb.NewPeerServiceClient(conn).GetBitfield(ctx, &pb.BitfieldRequest{
+		FileName: metadata.FileName,
+		Checksum: metadata.Checksum,
+		Sender:   p.PeerAddress,
+		Bitfield: chunkCoordinator.bitfield(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	if res.Status != 200 {
+		return nil, fmt.Errorf("%s holds no chunk of %s", addr, metadata.FileName)
+	}
+	if res.Seeding {
+		return fullBitfield(chunkCoordinator.numChunks), nil
+	}
+	return Bitfield(res.Bitfield), nil
+}
+
+// exchangeBitfields swaps bitfields with the given peers at once, adding
+// those holding chunks to the download. Seeders already in the ring are
+// skipped; they hold everything.
+func (p *PeerServer) exchangeBitfields(ctx context.Context, metadata TorrentMetadata, chunkCoordinator *ChunkCoordinator, peers []string) {
+	seeders := chunkCoordinator.hashRing.Members()
+	var wg sync.WaitGroup
+	for _, addr := range peers {
+		if addr == p.PeerAddress || slices.Contains(seeders, addr) {
+			continue
+		}
+		wg.Add(1)
+		go func(addr string) {
+			defer wg.Done()
+			have, err := p.queryBitfield(ctx, metadata, chunkCoordinator, addr)
+			if err != nil {
+				if debug_mode {
+					log.Printf("Bitfield of %s from %s: %v", metadata.FileName, addr, err)
+				}
+				return
+			}
+			if chunkCoordinator.addHolder(addr, have) && debug_mode {
+				log.Printf("%s holds %d chunks of %s", addr, have.count(chunkCoordinator.numChunks), metadata.FileName)
+			}
+		}(addr)
+	}
+	wg.Wait()
+}
+
+// activeDownload returns the coordinator of a file being downloaded, nil
+// if there is none.
+func (p *PeerServer) activeDownload(fileName string) *ChunkCoordinator {
+	p.downloadsMu.Lock()
+	defer p.downloadsMu.Unlock()
+	return p.downloads[fileName]
+}
+
+func (p *PeerServer) trackDownload(fileName string, chunkCoordinator *ChunkCoordinator) {
+	p.downloadsMu.Lock()
+	defer p.downloadsMu.Unlock()
+	if p.downloads == nil {
+		p.downloads = make(map[string]*ChunkCoordinator)
+	}
+	p.downloads[fileName] = chunkCoordinator
+}
+
+func (p *PeerServer) untrackDownload(fileName string) {
+	p.downloadsMu.Lock()
+	defer p.downloadsMu.Unlock()
+	delete(p.downloads, fileName)
+}
+
+// heldChunk returns a verified chunk of a file still being downloaded, so
+// downloaders can serve each other before they become seeders.
+func (p *PeerServer) heldChunk(chunkName string) ([]byte, bool) {
+	fileName := getFileName(chunkName)
+	chunkID, err := strconv.Atoi(strings.TrimPrefix(chunkName, fileName+"_chunk_"))
+	if fileName == "" || err != nil {
+		return nil, false
+	}
+	chunkCoordinator := p.activeDownload(fileName)
+	if chunkCoordinator == nil {
+		return nil, false
+	}
+	chunkCoordinator.chunkMutex.Lock()
+	defer chunkCoordinator.chunkMutex.Unlock()
+	data, held := chunkCoordinator.chunkData[chunkID]
+	return data, held
+}
@@ -0,0 +1,128 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"slices"
+	"testing"
+
+	pb "napster"
+)
+
+func TestBitfield(t *testing.T) {
+	// 10 chunks take two bytes, the last six bits unused.
+	have := newBitfield(10)
+	if len(have) != 2 {
+		t.Fatalf("len(newBitfield(10)) = %d, want 2", len(have))
+	}
+	for _, chunkID := range []int{0, 7, 8, 9} {
+		have.Set(chunkID)
+	}
+	if !bytes.Equal(have, []byte{0x81, 0xc0}) {
+		t.Errorf("bitfield = %x, want 81c0", []byte(have))
+	}
+	if full := fullBitfield(10); !bytes.Equal(full, []byte{0xff, 0xc0}) || full.count(10) != 10 {
+		t.Errorf("fullBitfield(10) = %x", []byte(full))
+	}
+
+	short := Bitfield{0xff} // sent by a peer for the first 8 chunks only
+	tests := []struct {
+		name      string
+		have      Bitfield
+		chunkID   int
+		held      bool
+		numChunks int
+		count     int
+	}{
+		{"first chunk", have, 0, true, 10, 4},
+		{"last chunk", have, 9, true, 10, 4},
+		{"unset chunk", have, 1, false, 10, 4},
+		{"unused bit", have, 10, false, 16, 4},
+		{"past the end", have, 16, false, 24, 4},
+		{"negative", have, -1, false, 0, 0},
+		{"short bitfield", short, 9, false, 10, 8},
+		{"short bitfield, counted in part", short, 7, true, 4, 4},
+		{"empty bitfield", nil, 0, false, 10, 0},
+	}
+	for _, tt := range tests {
+		if got := tt.have.Has(tt.chunkID); got != tt.held {
+			t.Errorf("%s: Has(%d) = %v, want %v", tt.name, tt.chunkID, got, tt.held)
+		}
+		if got := tt.have.count(tt.numChunks); got != tt.count {
+			t.Errorf("%s: count(%d) = %d, want %d", tt.name, tt.numChunks, got, tt.count)
+		}
+	}
+}
+
+func TestAddHolderPromotes(t *testing.T) {
+	c := newTestCoordinator(t, 10, nil, nil)
+	partial := newBitfield(10)
+	partial.Set(3)
+
+	if !c.addHolder("p1", partial) || slices.Contains(c.hashRing.Members(), "p1") {
+		t.Fatal("partial holder not added as a holder")
+	}
+	// A peer holding only the bits a short bitfield covers is no seeder.
+	if !c.addHolder("p2", Bitfield{0xff}) || slices.Contains(c.hashRing.Members(), "p2") {
+		t.Fatal("holder of a short bitfield added as a seeder")
+	}
+
+	// Once it holds every chunk, the holder becomes a seeder in the ring.
+	if !c.addHolder("p1", fullBitfield(10)) {
+		t.Error("promotion of p1 not reported")
+	}
+	c.chunkMutex.Lock()
+	_, holding := c.holders["p1"]
+	c.chunkMutex.Unlock()
+	if holding || !slices.Contains(c.hashRing.Members(), "p1") {
+		t.Errorf("p1 still a holder (%v) or not in the ring %v", holding, c.hashRing.Members())
+	}
+	if c.addHolder("p1", fullBitfield(10)) || c.addHolder("p1", partial) {
+		t.Error("seeder p1 added again")
+	}
+	if sources := c.sources(); len(sources) != 2 {
+		t.Errorf("sources = %v, want p1 and p2 once each", sources)
+	}
+}
+
+func TestHeldChunk(t *testing.T) {
+	inTempDir(t)
+	c := newTestCoordinator(t, 4, nil, nil)
+	c.chunkData[1] = []byte("chunk 1") // verified so far
+	c.chunkData[3] = []byte("chunk 3")
+	p := &PeerServer{PeerAddress: "self:1"}
+	p.trackDownload("song.mp3", c)
+
+	tests := []struct {
+		chunkName string
+		want      string // "" if not held
+	}{
+		{GetChunkName("song.mp3", 1), "chunk 1"},
+		{GetChunkName("song.mp3", 3), "chunk 3"},
+		{GetChunkName("song.mp3", 0), ""},
+		{GetChunkName("other.mp3", 1), ""},
+		{"song.mp3_chunk_x", ""},
+		{"song.mp3", ""},
+	}
+	for _, tt := range tests {
+		data, held := p.heldChunk(tt.chunkName)
+		if held != (tt.want != "") || string(data) != tt.want {
+			t.Errorf("heldChunk(%q) = %q, %v, want %q", tt.chunkName, data, held, tt.want)
+		}
+
+		// Chunks not in CHUNKS_DIR are served from the download.
+		res, err := p.RequestChunk(context.Background(), &pb.ChunkRequest{ChunkName: tt.chunkName})
+		if tt.want == "" {
+			if err != nil || res.Status != 404 {
+				t.Errorf("RequestChunk(%q) = %v, %v, want 404", tt.chunkName, res, err)
+			}
+		} else if err != nil || res.Status != 200 || string(res.ChunkData) != tt.want {
+			t.Errorf("RequestChunk(%q) = %v, %v, want %q", tt.chunkName, res, err, tt.want)
+		}
+	}
+
+	p.untrackDownload("song.mp3")
+	if _, held := p.heldChunk(GetChunkName("song.mp3", 1)); held {
+		t.Error("chunk served after the download ended")
+	}
+}
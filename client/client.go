@@ -15,13 +15,15 @@ import (
 	"path/filepath"
 	"sort"
 	"strings"
+	"sync"
 	"time"
 	"github.com/tcolgate/mp3"
 	pb "napster"
 
 	"github.com/dhowden/tag"
 	"google.golang.org/grpc"
-	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 var debug_mode = true
@@ -41,6 +43,7 @@ type TorrentMetadata struct {
 	CreatedAt      string         `json:"created_at"`  // Creation timestamp
 	Duration       int64          `json:"duration"`    
 	Status 		   string 		  `json:"status"`
+	Erasure        *ErasureLayout `json:"erasure,omitempty"` // set for erasure-coded files
 }
 
 
@@ -48,8 +51,18 @@ type TorrentMetadata struct {
 type PeerServer struct {
 	pb.UnimplementedPeerServiceServer
 	PeerAddress 	string
+	ListenAddress	string					// where the peer server listens, PeerAddress if empty
 	Client			pb.CentralServerClient
+	DHT				*DHT					// finds torrents and seeders without a central server, nil if off
 	EventEmitter 	func (eventName string, returnObject any)
+
+	searchesMu		sync.Mutex
+	searches		map[string]time.Time	// flooded search IDs already answered
+	pexMu			sync.Mutex
+	pexPeers		map[string]map[string]time.Time	// file name -> seeder or partial holder -> when heard of through peer exchange
+	downloadsMu		sync.Mutex
+	downloads		map[string]*ChunkCoordinator	// active downloads by file name, whose verified chunks are served
+	bandwidth		bandwidth						// upload and download limits
 }
 
 // HealthCheck returns alive status.
@@ -59,7 +72,11 @@ func (p *PeerServer) HealthCheck(ctx context.Context, req *pb.HealthCheckRequest
 
 // startPeerServer starts a local gRPC server so that other peers can communicate with this peer.
 func StartPeerServer(peerServer *PeerServer) error {
-	listener, err := net.Listen("tcp", peerServer.PeerAddress)
+	listenAddress := peerServer.ListenAddress
+	if listenAddress == "" {
+		listenAddress = peerServer.PeerAddress
+	}
+	listener, err := net.Listen("tcp", listenAddress)
 	if err != nil {
 		log.Fatalf("Failed to listen: %v", err)
 		return err
@@ -67,8 +84,11 @@ func StartPeerServer(peerServer *PeerServer) error {
 	
 	server := grpc.NewServer()
 	pb.RegisterPeerServiceServer(server, peerServer)
+	if peerServer.DHT != nil {
+		pb.RegisterDHTServer(server, peerServer.DHT)
+	}
 
-	log.Printf("Peer listening on %s...", peerServer.PeerAddress)
+	log.Printf("Peer listening on %s...", listenAddress)
 	if err := server.Serve(listener); err != nil {
 		log.Fatalf("Failed to serve: %v", err)
 		return err
@@ -77,21 +97,35 @@ func StartPeerServer(peerServer *PeerServer) error {
 	return nil
 }
 
-func GetIndexingClient(serverAddr string) (*grpc.ClientConn, pb.CentralServerClient) {
-	conn, err := grpc.NewClient(serverAddr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+func computeDataChecksum(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// computeFileChecksum streams a file through SHA-256, returning its size and checksum.
+func computeFileChecksum(filePath string) (int64, string, error) {
+	file, err := os.Open(filePath)
 	if err != nil {
-		log.Fatalf("Failed to connect to server: %v", err)
+		return 0, "", err
 	}
+	defer file.Close()
 
-	client := pb.NewCentralServerClient(conn)
-
-	log.Print("Connected to server!")
-	return conn, client
+	hasher := sha256.New()
+	size, err := io.Copy(hasher, file)
+	if err != nil {
+		return 0, "", err
+	}
+	return size, hex.EncodeToString(hasher.Sum(nil)), nil
 }
 
-func computeDataChecksum(data []byte) string {
-	sum := sha256.Sum256(data)
-	return hex.EncodeToString(sum[:])
+// readChunk fills buffer from r, so every chunk but the last is exactly
+// ChunkSize bytes. It returns 0, nil at end of file.
+func readChunk(r io.Reader, buffer []byte) (int, error) {
+	bytesRead, err := io.ReadFull(r, buffer)
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		return bytesRead, nil
+	}
+	return bytesRead, err
 }
 
 // Get duration (in seconds) of MP3 file
@@ -120,8 +154,65 @@ func getMP3Duration(filePath string) (int, error) {
 	return int(duration.Seconds()), nil
 }
 
+// sendFile streams a file to the central server chunk by chunk. Chunk 0
+// carries the file's details, taken from first.
+func (p *PeerServer) sendFile(file *os.File, fileName string, first *pb.FileChunk) (*pb.UploadResponse, error) {
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+
+	// Start the client-streaming RPC
+	stream, err := p.Client.UploadFile(context.Background())
+	if err != nil {
+		log.Printf("Error starting upload: %v", err)
+		return nil, err
+	}
+
+	buffer := make([]byte, ChunkSize)
+
+	// Send chunks to server
+	chunkIndex := 0
+	for {
+		bytesRead, err := readChunk(file, buffer)
+		if err != nil {
+			log.Printf("Error reading file: %v", err)
+			return nil, err
+		}
+		if bytesRead == 0 {
+			break
+		}
+
+		req := &pb.FileChunk{
+			FileName:  fileName,
+			ChunkData: buffer[:bytesRead],
+		}
+		log.Println(chunkIndex, computeDataChecksum(buffer[:bytesRead]))
+
+		if chunkIndex == 0 {
+			req.PeerAddress = first.PeerAddress
+			req.AlbumArtist = first.AlbumArtist
+			req.Duration = first.Duration
+			req.DeclaredSize = first.DeclaredSize
+			req.Checksum = first.Checksum
+		}
+		if err := stream.Send(req); err == io.EOF {
+			// The server closed the stream early (rejected or deduplicated);
+			// its response is read below.
+			break
+		} else if err != nil {
+			log.Printf("Error sending chunk: %v", err)
+			return nil, err
+		}
+		chunkIndex++
+	}
+
+	// Close stream and receive final response
+	return stream.CloseAndRecv()
+}
+
 // uploadFile streams the file to the central server chunk by chunk,
-// receives renamed file name from server, then saves chunks locally with the new name.
+// receives renamed file name from server, then saves chunks locally with the new name,
+// which is returned.
 func (p *PeerServer) UploadFile(localFilePath string, peerAddress string) (string, error) {
 
 	file, err := os.Open(localFilePath)
@@ -151,115 +242,131 @@ func (p *PeerServer) UploadFile(localFilePath string, peerAddress string) (strin
 	}
 	fmt.Printf("Duration: %d\n", duration)
 
-	// Start the client-streaming RPC
-	stream, err := p.Client.UploadFile(context.Background())
+	// The server verifies the received bytes against these.
+	fileSize, fileChecksum, err := computeFileChecksum(localFilePath)
 	if err != nil {
-		log.Printf("Error starting upload: %v", err)
-		return "", err
+		return "", fmt.Errorf("failed to hash file: %v", err)
+	}
+	if fileSize == 0 {
+		return "", fmt.Errorf("cannot upload an empty file")
 	}
 
-	buffer := make([]byte, ChunkSize)
+	first := &pb.FileChunk{
+		PeerAddress:  peerAddress,
+		AlbumArtist:  albumArtist,
+		Duration:     int32(duration),
+		DeclaredSize: fileSize,
+		Checksum:     fileChecksum,
+	}
 	originalBaseName := filepath.Base(localFilePath)
-
-	// Send chunks to server
-	chunkIndex := 0
-	for {
-		bytesRead, err := file.Read(buffer)
-		if err != nil && err != io.EOF {
-			log.Printf("Error reading file: %v", err)
-			return "", err
-		}
-		if bytesRead == 0 {
-			break
-		}
-
-		req := &pb.FileChunk{
-			FileName:  originalBaseName,
-			ChunkData: buffer[:bytesRead],
-		}
-		log.Println(chunkIndex, computeDataChecksum(buffer[:bytesRead]))
-
-		if chunkIndex == 0 {
-			req.PeerAddress = peerAddress
-			req.AlbumArtist = albumArtist
-			req.Duration = int32(duration)
-		}
-		if err := stream.Send(req); err != nil {
-			log.Printf("Error sending chunk: %v", err)
-			return "", err
-		}
-		chunkIndex++
+	res, err := p.sendFile(file, originalBaseName, first)
+	if status.Code(err) == codes.Unavailable {
+		// The central server failed over mid-upload; send the file to the new one.
+		res, err = p.sendFile(file, originalBaseName, first)
 	}
-
-	// Close stream and receive final response
-	res, err := stream.CloseAndRecv()
 	if err != nil {
 		log.Printf("Upload failed: %v", err)
 		return "", err
 	}
 	if res.Status != 200 {
 		log.Printf("Server rejected upload: %s", res.Message)
-		return "", err
+		return "", fmt.Errorf("server rejected upload: %s", res.Message)
 	}
 	log.Printf("Upload successful: %s", res.Message)
 	file.Close()
 
 	fmt.Printf("Upload completed.\nTorrent file: %s\n", res.TorrentFileName)
 
-	file, err = os.Open(localFilePath)
+	// The server may index the file under another name, e.g. when the same
+	// content had already been uploaded.
+	storedName := originalBaseName
+	if res.RenamedFileName != "" {
+		storedName = res.RenamedFileName
+	}
+
+	torrent_path := GetTorrent(p.Client, storedName)
+	if torrent_path == "" {
+		return "", fmt.Errorf("failed to fetch torrent for %s", storedName)
+	}
+	
+	var metadata_ TorrentMetadata
+	metadata_ = ParseTorrent(torrent_path)
+	if metadata_.FileName == "" {
+		return "", fmt.Errorf("failed to parse torrent for %s", storedName)
+	}
+
+	if res.Deduplicated && hasVerifiedChunks(metadata_) {
+		log.Printf("Chunks of %s already stored, skipping chunking", storedName)
+	} else if err := storeChunks(localFilePath, storedName); err != nil {
+		return "", err
+	}
+	if metadata_.Erasure != nil {
+		if err := storeParityChunks(metadata_); err != nil {
+			log.Printf("Failed to compute parity chunks of %s: %v", storedName, err)
+		}
+	}
+	
+	p.EventEmitter("upload-status", metadata_)
+	go p.Announce(metadata_)
+	mergeChunks(storedName, CHUNKS_DIR, filepath.Join(DOWNLOAD_PATH, storedName))
+
+	fmt.Printf("Chunks stored locally as: %s_chunk_* in ./chunks/\n", storedName)
+	return storedName, nil
+}
+
+// storeChunks splits a local file into CHUNKS_DIR under the given file name
+// so that this peer can serve it.
+func storeChunks(localFilePath string, fileName string) error {
+	file, err := os.Open(localFilePath)
 	if err != nil {
 		log.Printf("Failed to reopen file: %v", err)
-		return "", err
+		return err
 	}
 	defer file.Close()
 
 	chunksDir := CHUNKS_DIR
 	os.MkdirAll(chunksDir, os.ModePerm)
 	
-	buffer = make([]byte, ChunkSize)
+	buffer := make([]byte, ChunkSize)
 
-	chunkIndex = 0
+	chunkIndex := 0
 	for {
-		bytesRead, err := file.Read(buffer)
-		if err != nil && err != io.EOF {
+		bytesRead, err := readChunk(file, buffer)
+		if err != nil {
 			log.Printf("Error reading renamed file: %v", err)
-			return "", err
+			return err
 		}
 		if bytesRead == 0 {
 			break
 		}
-		chunkFileName := fmt.Sprintf("%s_chunk_%d", originalBaseName, chunkIndex)
+		chunkFileName := GetChunkName(fileName, chunkIndex)
 		chunkFilePath := filepath.Join(chunksDir, chunkFileName)
 
 		err = os.WriteFile(chunkFilePath, buffer[:bytesRead], 0644)
 		if err != nil {
 			log.Printf("Error writing chunk file %s: %v", chunkFileName, err)
-			return "", err
+			return err
 		}
 
 		chunkIndex++
 	}
-
-	torrent_path := GetTorrent(p.Client, originalBaseName)
-	if torrent_path == "" {
-		return "", err
-	}
-	
-	var metadata_ TorrentMetadata
-	metadata_ = ParseTorrent(torrent_path)
-	if metadata_.FileName == "" {
-		return "", err
-	}
-	
-	p.EventEmitter("upload-status", metadata_)
-	mergeChunks(originalBaseName, CHUNKS_DIR, filepath.Join(DOWNLOAD_PATH, originalBaseName))
-
-	fmt.Printf("Chunks stored locally as: %s_chunk_* in ./chunks/\n", originalBaseName)
-	return "", nil
+	return nil
 }
 
+// DownloadThisFile is how the server places a replica on a contributor. It
+// succeeds only once the file is verified on disk and announced as seeded,
+// including when it was already here from an earlier replication.
 func (p *PeerServer) DownloadThisFile(ctx context.Context, req *pb.SearchRequest) (*pb.GenResponse, error) {
 	p.DownloadFile(req.Query)
+
+	torrentPath := filepath.Join(TORRENTS_DIR, strings.TrimSuffix(req.Query, filepath.Ext(req.Query))+".torrent")
+	metadata := ParseTorrent(torrentPath)
+	if metadata.FileName == "" || !IsExisting(metadata, req.Query) {
+		return &pb.GenResponse{Status: 500}, nil
+	}
+	if err := p.EnableSeeding(req.Query); err != nil {
+		return &pb.GenResponse{Status: 500}, nil
+	}
 	return &pb.GenResponse{Status: 200}, nil
 }
 
@@ -282,8 +389,12 @@ func searchFileOnServer(client pb.CentralServerClient) {
 
 	fmt.Println("Matching songs:")
 	for _, song := range res.Results {
-		fmt.Printf("- Title: %s\n  Artist: %s\n  Created: %s\n  Peers: %d\n\n",
-			song.FileName, song.ArtistName, song.CreatedAt, len(song.PeerAddresses))
+		fmt.Printf("- Title: %s\n  Artist: %s\n  Created: %s\n  Live Seeders: %d\n  Contributors: %d\n  Available: %t\n",
+			song.FileName, song.ArtistName, song.CreatedAt, song.LiveSeeders, song.Contributors, song.Available)
+		if song.Origin != "" {
+			fmt.Printf("  Index: %s\n", song.Origin)
+		}
+		fmt.Println()
 	}
 }
 
@@ -385,6 +496,12 @@ func (peer *PeerServer) RequestChunk(ctx context.Context, req *pb.ChunkRequest)
 	data, err := os.ReadFile(chunkPath)
 	if err != nil {
 		if os.IsNotExist(err) {
+			if data, held := peer.heldChunk(req.ChunkName); held {
+				if err := peer.throttleUpload(ctx, getFileName(req.ChunkName), len(data)); err != nil {
+					return nil, err
+				}
+				return &pb.ChunkResponse{Status: 200, ChunkData: data}, nil
+			}
 			return &pb.ChunkResponse{
 				Status:    404,
 				ChunkData: nil,
@@ -394,6 +511,14 @@ func (peer *PeerServer) RequestChunk(ctx context.Context, req *pb.ChunkRequest)
 		return nil, fmt.Errorf("failed to read chunk: %v", err)
 	}
 
+	fileName := getFileName(req.ChunkName)
+	if fileName == "" {
+		fileName, _, _ = strings.Cut(req.ChunkName, "_parity_")
+	}
+	if err := peer.throttleUpload(ctx, fileName, len(data)); err != nil {
+		return nil, err
+	}
+
 	return &pb.ChunkResponse{
 		Status:    200,
 		ChunkData: data,
@@ -401,12 +526,15 @@ func (peer *PeerServer) RequestChunk(ctx context.Context, req *pb.ChunkRequest)
 }
 
 
-// SearchFile queries the central server for files matching the query.
-func (c *PeerServer) SearchFile(query string) ([]*pb.SongInfo, error) {
+// SearchFile queries the central server for the most relevant files matching the query.
+// With onlyAvailable set, songs that no live peer can serve are left out.
+// Servers that federate with other indexes include their matches, with the
+// server to download them from in Origin.
+func (c *PeerServer) SearchFile(query string, onlyAvailable bool) ([]*pb.SongInfo, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 5 * time.Second)
 	defer cancel()
 
-	res, err := c.Client.SearchFile(ctx, &pb.SearchRequest{Query: query})
+	res, err := c.Client.SearchFile(ctx, &pb.SearchRequest{Query: query, OnlyAvailable: onlyAvailable, MaxResults: 100})
 	if err != nil {
 		log.Printf("search error: %v", err)
 		return nil, fmt.Errorf("search error: %v", err)
@@ -414,18 +542,34 @@ func (c *PeerServer) SearchFile(query string) ([]*pb.SongInfo, error) {
 	return res.Results, nil
 }
 
+// RankedSearch fetches one page of ranked, filtered search results.
+func (c *PeerServer) RankedSearch(req *pb.RankedSearchRequest) (*pb.RankedSearchResponse, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5 * time.Second)
+	defer cancel()
+
+	res, err := c.Client.RankedSearch(ctx, req)
+	if err != nil {
+		log.Printf("search error: %v", err)
+		return nil, fmt.Errorf("search error: %v", err)
+	}
+	return res, nil
+}
+
 var peerAddress string;
 
 func main() {
-	serverAddr := flag.String("server", "localhost:50051", "Central server address")
+	serverAddrs := flag.String("server", "localhost:50051", "Central server addresses, comma-separated")
 	peerPort := flag.String("port", "50054", "Port for peer server")
 	flag.Parse()
 
 	peerAddress = fmt.Sprintf("localhost:%s", *peerPort)
 	// Register this peer with the central server.
 	 
-	conn, indexingClient := GetIndexingClient(*serverAddr)
-	defer conn.Close()
+	indexingClient, err := GetIndexingClient(ParseServerList(*serverAddrs))
+	if err != nil {
+		log.Fatalf("Failed to connect to server: %v", err)
+	}
+	defer indexingClient.Close()
 
 	// Start a peer server concurrently.
 	// go StartPeerServer(peerAddress, indexingClient)
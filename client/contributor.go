@@ -0,0 +1,134 @@
+package client
+
+import (
+	"context"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	pb "napster"
+)
+
+// CONTRIBUTOR_CAPACITY is the storage a contributor offers to the swarm.
+var CONTRIBUTOR_CAPACITY int64 = 10 << 30
+
+// MaintainContribution registers this peer as a contributor and heartbeats
+// its free space until ctx is cancelled, then deregisters so the server
+// moves its replicas elsewhere.
+func (p *PeerServer) MaintainContribution(ctx context.Context) {
+	lease := p.registerContributor()
+	for {
+		select {
+		case <-ctx.Done():
+			p.deregisterContributor()
+			return
+		case <-time.After(lease / 3):
+		}
+
+		hctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		res, err := p.Client.ContributorHeartbeat(hctx, p.contributorRequest())
+		cancel()
+
+		if err != nil {
+			if debug_mode {
+				log.Printf("Contributor heartbeat failed: %v", err)
+			}
+			continue
+		}
+		if res.Reregister {
+			lease = p.registerContributor()
+			continue
+		}
+		if res.LeaseSeconds > 0 {
+			lease = time.Duration(res.LeaseSeconds) * time.Second
+		}
+	}
+}
+
+// registerContributor offers this peer's storage to the server, returning the lease length.
+func (p *PeerServer) registerContributor() time.Duration {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	req := p.contributorRequest()
+	res, err := p.Client.RegisterContributor(ctx, req)
+	if err != nil || (res.Status != 200 && res.Status != 204) {
+		log.Printf("Contributor registration failed: %v", err)
+		return defaultLease
+	}
+
+	log.Printf("Registered as contributor with %d of %d bytes free", req.FreeBytes, req.CapacityBytes)
+	return defaultLease
+}
+
+func (p *PeerServer) deregisterContributor() {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if _, err := p.Client.DeregisterContributor(ctx, &pb.ContributorRequest{ContriAddr: p.PeerAddress}); err != nil {
+		log.Printf("Contributor deregistration failed: %v", err)
+	}
+}
+
+func (p *PeerServer) contributorRequest() *pb.ContributorRequest {
+	return &pb.ContributorRequest{
+		ContriAddr:    p.PeerAddress,
+		CapacityBytes: CONTRIBUTOR_CAPACITY,
+		FreeBytes:     max(CONTRIBUTOR_CAPACITY-storageUsed(), 0),
+	}
+}
+
+// DropFile deletes this contributor's copy of a file the server moved to
+// another owner: its served chunks, the merged download and the torrent.
+func (p *PeerServer) DropFile(ctx context.Context, req *pb.SearchRequest) (*pb.GenResponse, error) {
+	filename := req.Query
+	if filename == "" || filename != filepath.Base(filename) {
+		return &pb.GenResponse{Status: 400}, nil
+	}
+	if status := getTorrentStatus(filename); status != "" && status != "Seeding" && status != "Downloaded" {
+		// Still downloading; the server will retry on its next round.
+		return &pb.GenResponse{Status: 409}, nil
+	}
+
+	for _, dir := range []string{CHUNKS_DIR, CACHE_DIR} {
+		entries, _ := os.ReadDir(dir)
+		for _, entry := range entries {
+			if strings.HasPrefix(entry.Name(), filename+"_chunk_") || strings.HasPrefix(entry.Name(), filename+"_parity_") {
+				os.Remove(filepath.Join(dir, entry.Name()))
+			}
+		}
+	}
+	os.Remove(filepath.Join(DOWNLOAD_PATH, filename))
+	os.Remove(filepath.Join(TORRENTS_DIR, strings.TrimSuffix(filename, filepath.Ext(filename))+".torrent"))
+
+	torrentStatus.Lock()
+	delete(torrentStatus.status, filename)
+	torrentStatus.Unlock()
+
+	log.Printf("Dropped %s, it moved to another contributor", filename)
+	p.EventEmitter("download-status", DownloadStatus{
+		Filename: filename,
+		Status:   "Removed",
+	})
+	return &pb.GenResponse{Status: 200}, nil
+}
+
+// storageUsed sums the size of served chunks and downloads on disk.
+func storageUsed() int64 {
+	var used int64
+	for _, dir := range []string{CHUNKS_DIR, DOWNLOAD_PATH} {
+		filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+			if err != nil || d.IsDir() {
+				return nil
+			}
+			if info, err := d.Info(); err == nil {
+				used += info.Size()
+			}
+			return nil
+		})
+	}
+	return used
+}
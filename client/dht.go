@@ -0,0 +1,568 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha1"
+	"errors"
+	"log"
+	"math/bits"
+	"slices"
+	"sync"
+	"time"
+
+	pb "napster"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	dhtIDBits       = 160
+	dhtK            = 20 // bucket size, and how many nodes store each value
+	dhtAlpha        = 3  // lookup requests in flight
+	dhtRPCTimeout   = 2 * time.Second
+	dhtValueTTL     = time.Hour // stored values expire unless republished
+	dhtRepublish    = 20 * time.Minute
+	dhtRefresh      = 10 * time.Minute
+	dhtMaxValues    = 256     // values kept per key, the oldest are dropped first
+	dhtMaxValueSize = 1 << 20 // bytes
+)
+
+var errDHTEmpty = errors.New("no DHT node reachable")
+
+// NodeID identifies DHT nodes and keys. Distances between them are their XOR.
+type NodeID [sha1.Size]byte
+
+// nodeID returns the ID of the node at addr.
+func nodeID(addr string) NodeID {
+	return sha1.Sum([]byte(addr))
+}
+
+// dhtKey hashes name into the key space.
+func dhtKey(name string) NodeID {
+	return sha1.Sum([]byte(name))
+}
+
+// closer reports whether a is closer to target than b.
+func closer(target, a, b NodeID) bool {
+	for i := range target {
+		da, db := a[i]^target[i], b[i]^target[i]
+		if da != db {
+			return da < db
+		}
+	}
+	return false
+}
+
+func sortByDistance(addrs []string, target NodeID) {
+	slices.SortFunc(addrs, func(a, b string) int {
+		ia, ib := nodeID(a), nodeID(b)
+		if closer(target, ia, ib) {
+			return -1
+		}
+		if closer(target, ib, ia) {
+			return 1
+		}
+		return 0
+	})
+}
+
+type storedValue struct {
+	data    []byte
+	expires time.Time
+}
+
+// DHT is a Kademlia node. It keeps contacts in k-buckets by the length of
+// the ID prefix they share with this node, finds nodes and values with
+// iterative parallel lookups, and stores each value on the dhtK nodes whose
+// IDs are closest to its key. Values expire after dhtValueTTL, so publishers
+// republish them every dhtRepublish. A key may hold several values, e.g. the
+// addresses of every seeder of a file.
+//
+// Serve it next to the PeerService with pb.RegisterDHTServer. Nothing else
+// is global, so many nodes can run in one process.
+type DHT struct {
+	pb.UnimplementedDHTServer
+	address string
+	id      NodeID
+
+	mu      sync.Mutex
+	buckets [dhtIDBits][]string               // contacts, least recently seen first
+	values  map[NodeID]map[string]storedValue // key -> value -> expiry
+	pinging map[string]bool                   // bucket heads being checked before eviction
+
+	connsMu sync.Mutex
+	conns   map[string]*grpc.ClientConn
+}
+
+// NewDHT creates the DHT node of the peer other peers reach at address.
+func NewDHT(address string) *DHT {
+	return &DHT{
+		address: address,
+		id:      nodeID(address),
+		values:  make(map[NodeID]map[string]storedValue),
+		pinging: make(map[string]bool),
+		conns:   make(map[string]*grpc.ClientConn),
+	}
+}
+
+// Address returns the address this node is reached at.
+func (d *DHT) Address() string {
+	return d.address
+}
+
+// Contacts returns how many nodes the routing table holds.
+func (d *DHT) Contacts() int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	n := 0
+	for _, bucket := range d.buckets {
+		n += len(bucket)
+	}
+	return n
+}
+
+// Close drops the connections to other nodes.
+func (d *DHT) Close() {
+	d.connsMu.Lock()
+	defer d.connsMu.Unlock()
+	for addr, conn := range d.conns {
+		conn.Close()
+		delete(d.conns, addr)
+	}
+}
+
+// Run expires stored values and refreshes the routing table. It never
+// returns; run it in its own goroutine.
+func (d *DHT) Run() {
+	expiry := time.NewTicker(time.Minute)
+	refresh := time.NewTicker(dhtRefresh)
+	for {
+		select {
+		case <-expiry.C:
+			d.expireValues()
+		case <-refresh.C:
+			ctx, cancel := context.WithTimeout(context.Background(), 4*dhtRPCTimeout)
+			d.lookup(ctx, d.id, false)
+			cancel()
+		}
+	}
+}
+
+// Bootstrap joins the network through the nodes at addrs, then looks up this
+// node's own ID to fill the routing table with its neighbours.
+func (d *DHT) Bootstrap(ctx context.Context, addrs []string) error {
+	var wg sync.WaitGroup
+	for _, addr := range addrs {
+		if addr == d.address {
+			continue
+		}
+		wg.Add(1)
+		go func(addr string) {
+			defer wg.Done()
+			d.ping(ctx, addr)
+		}(addr)
+	}
+	wg.Wait()
+
+	if d.Contacts() == 0 {
+		return errDHTEmpty
+	}
+	d.lookup(ctx, d.id, false)
+	return nil
+}
+
+// AddContacts checks the nodes at addrs in the background and adds those
+// that answer to the routing table.
+func (d *DHT) AddContacts(addrs []string) {
+	for _, addr := range addrs {
+		if addr == d.address || d.known(addr) {
+			continue
+		}
+		go func(addr string) {
+			ctx, cancel := context.WithTimeout(context.Background(), dhtRPCTimeout)
+			defer cancel()
+			d.ping(ctx, addr)
+		}(addr)
+	}
+}
+
+// Put stores value under key on the dhtK nodes closest to it, this one
+// included if it is among them, and returns on how many it was stored.
+func (d *DHT) Put(ctx context.Context, key NodeID, value []byte) (int, error) {
+	if len(value) > dhtMaxValueSize {
+		return 0, errors.New("DHT value too large")
+	}
+	_, nodes := d.lookup(ctx, key, false)
+	nodes = append(nodes, d.address)
+	sortByDistance(nodes, key)
+	nodes = nodes[:min(len(nodes), dhtK)]
+
+	var (
+		wg     sync.WaitGroup
+		mu     sync.Mutex
+		stored int
+	)
+	for _, addr := range nodes {
+		if addr == d.address {
+			d.storeLocal(key, value)
+			mu.Lock()
+			stored++
+			mu.Unlock()
+			continue
+		}
+		wg.Add(1)
+		go func(addr string) {
+			defer wg.Done()
+			err := d.call(ctx, addr, func(c pb.DHTClient, ctx context.Context) error {
+				_, err := c.Store(ctx, &pb.StoreRequest{Sender: d.address, Key: key[:], Value: value})
+				return err
+			})
+			if err == nil {
+				mu.Lock()
+				stored++
+				mu.Unlock()
+			}
+		}(addr)
+	}
+	wg.Wait()
+	if stored == 0 {
+		return 0, errDHTEmpty
+	}
+	return stored, nil
+}
+
+// Get returns the values stored under key, here and on the nodes closest to it.
+func (d *DHT) Get(ctx context.Context, key NodeID) [][]byte {
+	found := d.localValues(key)
+	remote, _ := d.lookup(ctx, key, true)
+	for _, value := range remote {
+		if !slices.ContainsFunc(found, func(v []byte) bool { return bytes.Equal(v, value) }) {
+			found = append(found, value)
+		}
+	}
+	return found
+}
+
+// lookup runs Kademlia's iterative search: it asks the closest nodes it
+// knows for closer ones, dhtAlpha at a time, until the dhtK closest nodes
+// found have all answered. With findValue set it stops at the first round
+// that returns values for target. It returns the values found and the dhtK
+// closest live nodes.
+func (d *DHT) lookup(ctx context.Context, target NodeID, findValue bool) ([][]byte, []string) {
+	shortlist := d.closest(target, dhtK, "")
+	seen := map[string]bool{d.address: true}
+	for _, addr := range shortlist {
+		seen[addr] = true
+	}
+	queried := make(map[string]bool)
+
+	type answer struct {
+		addr   string
+		nodes  []string
+		values [][]byte
+		err    error
+	}
+	var values [][]byte
+	for ctx.Err() == nil {
+		sortByDistance(shortlist, target)
+		var round []string
+		for _, addr := range shortlist[:min(len(shortlist), dhtK)] {
+			if !queried[addr] {
+				round = append(round, addr)
+				if len(round) == dhtAlpha {
+					break
+				}
+			}
+		}
+		if len(round) == 0 {
+			break
+		}
+
+		answers := make(chan answer, len(round))
+		for _, addr := range round {
+			queried[addr] = true
+			go func(addr string) {
+				a := answer{addr: addr}
+				a.err = d.call(ctx, addr, func(c pb.DHTClient, ctx context.Context) error {
+					if findValue {
+						res, err := c.FindValue(ctx, &pb.FindValueRequest{Sender: d.address, Key: target[:]})
+						if err == nil {
+							a.nodes, a.values = res.Nodes, res.Values
+						}
+						return err
+					}
+					res, err := c.FindNode(ctx, &pb.FindNodeRequest{Sender: d.address, Target: target[:]})
+					if err == nil {
+						a.nodes = res.Nodes
+					}
+					return err
+				})
+				answers <- a
+			}(addr)
+		}
+		for range round {
+			a := <-answers
+			if a.err != nil {
+				shortlist = slices.DeleteFunc(shortlist, func(addr string) bool { return addr == a.addr })
+				continue
+			}
+			values = append(values, a.values...)
+			for _, addr := range a.nodes {
+				if !seen[addr] {
+					seen[addr] = true
+					shortlist = append(shortlist, addr)
+				}
+			}
+		}
+		if findValue && len(values) > 0 {
+			break
+		}
+	}
+	sortByDistance(shortlist, target)
+	return values, shortlist[:min(len(shortlist), dhtK)]
+}
+
+// Ping answers liveness checks.
+func (d *DHT) Ping(ctx context.Context, req *pb.DHTPingRequest) (*pb.DHTPingResponse, error) {
+	d.observe(req.Sender)
+	return &pb.DHTPingResponse{}, nil
+}
+
+// FindNode returns the dhtK nodes closest to the target that this node knows.
+func (d *DHT) FindNode(ctx context.Context, req *pb.FindNodeRequest) (*pb.FindNodeResponse, error) {
+	target, err := toNodeID(req.Target)
+	if err != nil {
+		return nil, err
+	}
+	d.observe(req.Sender)
+	return &pb.FindNodeResponse{Nodes: d.closest(target, dhtK, req.Sender)}, nil
+}
+
+// FindValue returns the values stored here under the key, or the closest
+// nodes to it if there are none.
+func (d *DHT) FindValue(ctx context.Context, req *pb.FindValueRequest) (*pb.FindValueResponse, error) {
+	key, err := toNodeID(req.Key)
+	if err != nil {
+		return nil, err
+	}
+	d.observe(req.Sender)
+	if values := d.localValues(key); len(values) > 0 {
+		return &pb.FindValueResponse{Values: values}, nil
+	}
+	return &pb.FindValueResponse{Nodes: d.closest(key, dhtK, req.Sender)}, nil
+}
+
+// Store keeps a value for dhtValueTTL.
+func (d *DHT) Store(ctx context.Context, req *pb.StoreRequest) (*pb.StoreResponse, error) {
+	key, err := toNodeID(req.Key)
+	if err != nil {
+		return nil, err
+	}
+	if len(req.Value) == 0 || len(req.Value) > dhtMaxValueSize {
+		return nil, status.Error(codes.InvalidArgument, "invalid value size")
+	}
+	d.observe(req.Sender)
+	d.storeLocal(key, req.Value)
+	return &pb.StoreResponse{Stored: true}, nil
+}
+
+func toNodeID(b []byte) (NodeID, error) {
+	var id NodeID
+	if len(b) != len(id) {
+		return id, status.Errorf(codes.InvalidArgument, "IDs are %d bytes, got %d", len(id), len(b))
+	}
+	copy(id[:], b)
+	return id, nil
+}
+
+// bucketIndex returns the bucket of id: the length of the prefix it shares
+// with this node's ID.
+func (d *DHT) bucketIndex(id NodeID) int {
+	for i := range id {
+		if x := id[i] ^ d.id[i]; x != 0 {
+			return i*8 + bits.LeadingZeros8(x)
+		}
+	}
+	return dhtIDBits - 1
+}
+
+// observe records that the node at addr is alive. It moves to the tail of
+// its bucket, or joins it if there is room. When the bucket is full, its
+// least recently seen node is pinged and replaced only if it does not
+// answer, since nodes that have been up long tend to stay up.
+func (d *DHT) observe(addr string) {
+	if addr == "" || addr == d.address {
+		return
+	}
+	i := d.bucketIndex(nodeID(addr))
+
+	d.mu.Lock()
+	bucket := d.buckets[i]
+	if j := slices.Index(bucket, addr); j >= 0 {
+		d.buckets[i] = append(slices.Delete(bucket, j, j+1), addr)
+		d.mu.Unlock()
+		return
+	}
+	if len(bucket) < dhtK {
+		d.buckets[i] = append(bucket, addr)
+		d.mu.Unlock()
+		return
+	}
+	oldest := bucket[0]
+	if d.pinging[oldest] {
+		d.mu.Unlock()
+		return
+	}
+	d.pinging[oldest] = true
+	d.mu.Unlock()
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), dhtRPCTimeout)
+		defer cancel()
+		alive := d.ping(ctx, oldest) == nil
+
+		d.mu.Lock()
+		defer d.mu.Unlock()
+		delete(d.pinging, oldest)
+		if !alive && len(d.buckets[i]) < dhtK && !slices.Contains(d.buckets[i], addr) {
+			d.buckets[i] = append(d.buckets[i], addr)
+		}
+	}()
+}
+
+// forget drops a node that failed to answer.
+func (d *DHT) forget(addr string) {
+	i := d.bucketIndex(nodeID(addr))
+	d.mu.Lock()
+	before := len(d.buckets[i])
+	d.buckets[i] = slices.DeleteFunc(d.buckets[i], func(a string) bool { return a == addr })
+	dropped := len(d.buckets[i]) < before
+	d.mu.Unlock()
+
+	if dropped && debug_mode {
+		log.Printf("DHT node %s unreachable, dropped", addr)
+	}
+}
+
+func (d *DHT) known(addr string) bool {
+	i := d.bucketIndex(nodeID(addr))
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return slices.Contains(d.buckets[i], addr)
+}
+
+// closest returns up to n known nodes closest to target, leaving out exclude.
+func (d *DHT) closest(target NodeID, n int, exclude string) []string {
+	d.mu.Lock()
+	var addrs []string
+	for _, bucket := range d.buckets {
+		for _, addr := range bucket {
+			if addr != exclude {
+				addrs = append(addrs, addr)
+			}
+		}
+	}
+	d.mu.Unlock()
+
+	sortByDistance(addrs, target)
+	return addrs[:min(len(addrs), n)]
+}
+
+func (d *DHT) storeLocal(key NodeID, value []byte) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	values, exists := d.values[key]
+	if !exists {
+		values = make(map[string]storedValue)
+		d.values[key] = values
+	}
+	values[string(value)] = storedValue{data: slices.Clone(value), expires: time.Now().Add(dhtValueTTL)}
+	for len(values) > dhtMaxValues {
+		var oldest string
+		for v, stored := range values {
+			if oldest == "" || stored.expires.Before(values[oldest].expires) {
+				oldest = v
+			}
+		}
+		delete(values, oldest)
+	}
+}
+
+// localValues returns the unexpired values stored here under key.
+func (d *DHT) localValues(key NodeID) [][]byte {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	now := time.Now()
+	var found [][]byte
+	for _, stored := range d.values[key] {
+		if now.Before(stored.expires) {
+			found = append(found, stored.data)
+		}
+	}
+	return found
+}
+
+func (d *DHT) expireValues() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	now := time.Now()
+	for key, values := range d.values {
+		for v, stored := range values {
+			if now.After(stored.expires) {
+				delete(values, v)
+			}
+		}
+		if len(values) == 0 {
+			delete(d.values, key)
+		}
+	}
+}
+
+func (d *DHT) ping(ctx context.Context, addr string) error {
+	return d.call(ctx, addr, func(c pb.DHTClient, ctx context.Context) error {
+		_, err := c.Ping(ctx, &pb.DHTPingRequest{Sender: d.address})
+		return err
+	})
+}
+
+// call runs an RPC against the node at addr, adding it to the routing table
+// if it answers and dropping it if it does not (unless ctx ended first).
+func (d *DHT) call(ctx context.Context, addr string, rpc func(pb.DHTClient, context.Context) error) error {
+	c, err := d.client(addr)
+	if err == nil {
+		rctx, cancel := context.WithTimeout(ctx, dhtRPCTimeout)
+		err = rpc(c, rctx)
+		cancel()
+	}
+	if err != nil {
+		if ctx.Err() == nil {
+			d.forget(addr)
+		}
+		return err
+	}
+	d.observe(addr)
+	return nil
+}
+
+func (d *DHT) client(addr string) (pb.DHTClient, error) {
+	d.connsMu.Lock()
+	defer d.connsMu.Unlock()
+
+	conn, exists := d.conns[addr]
+	if !exists {
+		var err error
+		conn, err = grpc.NewClient(addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+		if err != nil {
+			return nil, err
+		}
+		d.conns[addr] = conn
+	}
+	return pb.NewDHTClient(conn), nil
+}
@@ -0,0 +1,104 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"slices"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+
+	pb "napster"
+)
+
+// testDHT is a node of an in-process DHT on 127.0.0.1.
+type testDHT struct {
+	*DHT
+	server *grpc.Server
+}
+
+// newTestDHT starts n nodes, each bootstrapped through the one before it.
+func newTestDHT(t *testing.T, n int) []*testDHT {
+	t.Helper()
+	nodes := make([]*testDHT, n)
+	for i := range nodes {
+		lis, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatal(err)
+		}
+		node := &testDHT{DHT: NewDHT(lis.Addr().String()), server: grpc.NewServer()}
+		pb.RegisterDHTServer(node.server, node.DHT)
+		go node.server.Serve(lis)
+		nodes[i] = node
+	}
+	t.Cleanup(func() {
+		for _, node := range nodes {
+			node.stop()
+		}
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	for i := 1; i < n; i++ {
+		if err := nodes[i].Bootstrap(ctx, []string{nodes[i-1].Address()}); err != nil {
+			t.Fatalf("bootstrapping node %d: %v", i, err)
+		}
+	}
+	return nodes
+}
+
+func (n *testDHT) stop() {
+	n.server.Stop()
+	n.Close()
+}
+
+func TestDHTPutGet(t *testing.T) {
+	nodes := newTestDHT(t, 30)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	keys := []NodeID{dhtKey("name:song.mp3"), dhtKey("seeders:abc"), dhtKey("torrent:def")}
+	for i, key := range keys {
+		// Two publishers per key, from both ends of the chain.
+		for _, publisher := range []*testDHT{nodes[i], nodes[len(nodes)-1-i]} {
+			value := []byte(fmt.Sprintf("%x from %s", key[:4], publisher.Address()))
+			if stored, err := publisher.Put(ctx, key, value); err != nil || stored == 0 {
+				t.Fatalf("Put of %x on %s: stored on %d, %v", key[:4], publisher.Address(), stored, err)
+			}
+		}
+	}
+
+	check := func(stage string, down map[int]bool) {
+		t.Helper()
+		for i, node := range nodes {
+			if down[i] {
+				continue
+			}
+			for k, key := range keys {
+				values := node.Get(ctx, key)
+				if len(values) != 2 {
+					t.Errorf("%s: node %d found %d values of key %d, want 2", stage, i, len(values), k)
+				}
+			}
+		}
+	}
+	check("all up", nil)
+
+	// Stop the publishers and the nodes closest to the first key, which
+	// hold its values.
+	addrs := make([]string, len(nodes))
+	for i, node := range nodes {
+		addrs[i] = node.Address()
+	}
+	sortByDistance(addrs, keys[0])
+	down := map[int]bool{0: true, len(nodes) - 1: true}
+	for _, addr := range addrs[:3] {
+		down[slices.IndexFunc(nodes, func(n *testDHT) bool { return n.Address() == addr })] = true
+	}
+	for i := range down {
+		nodes[i].stop()
+	}
+	check(fmt.Sprintf("%d nodes down", len(down)), down)
+}
@@ -0,0 +1,209 @@
+package client
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+// dhtLookupTimeout bounds one announcement or torrent lookup in the DHT.
+const dhtLookupTimeout = 15 * time.Second
+
+// Keys under which peers publish files in the DHT. Torrents, seeders and
+// holders (peers still downloading) are keyed by the file's content hash
+// (its checksum); the name key lists the content hashes published under a
+// file name, which is all a peer knows before it has the torrent.
+func dhtNameKey(fileName string) NodeID    { return dhtKey("name:" + fileName) }
+func dhtTorrentKey(checksum string) NodeID { return dhtKey("torrent:" + checksum) }
+func dhtSeedersKey(checksum string) NodeID { return dhtKey("seeders:" + checksum) }
+func dhtHoldersKey(checksum string) NodeID { return dhtKey("holders:" + checksum) }
+
+// torrentPath is where the torrent of fileName is kept.
+func torrentPath(fileName string) string {
+	return filepath.Join(TORRENTS_DIR, strings.TrimSuffix(fileName, filepath.Ext(fileName))+".torrent")
+}
+
+// JoinDHT joins the DHT through addrs and the peers listed in local
+// torrents, then keeps the seeded files announced, retrying the join while
+// no other node is known. It never returns; run it in its own goroutine.
+func (p *PeerServer) JoinDHT(addrs []string) {
+	go p.DHT.Run()
+	for {
+		if p.DHT.Contacts() == 0 {
+			ctx, cancel := context.WithTimeout(context.Background(), dhtLookupTimeout)
+			if err := p.DHT.Bootstrap(ctx, append(slices.Clone(addrs), torrentPeers()...)); err != nil && debug_mode {
+				log.Printf("DHT bootstrap failed: %v", err)
+			}
+			cancel()
+		}
+		for _, name := range seededFiles() {
+			metadata := ParseTorrent(torrentPath(name))
+			if metadata.FileName == "" {
+				continue
+			}
+			if err := p.Announce(metadata); err != nil && debug_mode {
+				log.Printf("Announcing %s in the DHT failed: %v", name, err)
+			}
+		}
+		time.Sleep(dhtRepublish)
+	}
+}
+
+// Announce publishes a torrent in the DHT, with this peer as a seeder.
+func (p *PeerServer) Announce(metadata TorrentMetadata) error {
+	if p.DHT == nil {
+		return nil
+	}
+	// Seeders are announced separately and the status is ours alone.
+	torrent := metadata
+	torrent.Peers, torrent.Status = nil, ""
+	data, err := json.Marshal(torrent)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), dhtLookupTimeout)
+	defer cancel()
+	if _, err := p.DHT.Put(ctx, dhtNameKey(metadata.FileName), []byte(metadata.Checksum)); err != nil {
+		return err
+	}
+	if _, err := p.DHT.Put(ctx, dhtTorrentKey(metadata.Checksum), data); err != nil {
+		return err
+	}
+	_, err = p.DHT.Put(ctx, dhtSeedersKey(metadata.Checksum), []byte(p.PeerAddress))
+	return err
+}
+
+// announceHolder publishes in the DHT that this peer is downloading a file,
+// so the other downloaders can ask for the chunks it has.
+func (p *PeerServer) announceHolder(metadata TorrentMetadata) {
+	if p.DHT == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), dhtLookupTimeout)
+	defer cancel()
+	if _, err := p.DHT.Put(ctx, dhtHoldersKey(metadata.Checksum), []byte(p.PeerAddress)); err != nil && debug_mode {
+		log.Printf("Announcing the download of %s in the DHT failed: %v", metadata.FileName, err)
+	}
+}
+
+// holdersOf returns the peers announced in the DHT as downloading a file.
+func (p *PeerServer) holdersOf(ctx context.Context, metadata TorrentMetadata) []string {
+	if p.DHT == nil {
+		return nil
+	}
+	var holders []string
+	for _, holder := range p.DHT.Get(ctx, dhtHoldersKey(metadata.Checksum)) {
+		holders = append(holders, string(holder))
+	}
+	return holders
+}
+
+// lookupTorrent finds the torrent of fileName in the DHT, along with the
+// seeders announced for it, and stores it like GetTorrent does. When several
+// files were published under the name, the one with most seeders wins.
+func (p *PeerServer) lookupTorrent(fileName string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), dhtLookupTimeout)
+	defer cancel()
+
+	var best TorrentMetadata
+	for _, checksum := range p.DHT.Get(ctx, dhtNameKey(fileName)) {
+		var seeders []string
+		for _, seeder := range p.DHT.Get(ctx, dhtSeedersKey(string(checksum))) {
+			if string(seeder) != p.PeerAddress {
+				seeders = append(seeders, string(seeder))
+			}
+		}
+		if best.FileName != "" && len(seeders) <= len(best.Peers) {
+			continue
+		}
+		for _, data := range p.DHT.Get(ctx, dhtTorrentKey(string(checksum))) {
+			var metadata TorrentMetadata
+			if err := json.Unmarshal(data, &metadata); err != nil {
+				continue
+			}
+			// Only a torrent describing the content it is keyed by will do.
+			if metadata.FileName != fileName || metadata.Checksum != string(checksum) {
+				continue
+			}
+			if err := checkTorrent(metadata); err != nil {
+				log.Printf("Ignoring a torrent of %s from the DHT: %v", fileName, err)
+				continue
+			}
+			metadata.Peers = seeders
+			best = metadata
+			break
+		}
+	}
+	if best.FileName == "" {
+		return "", fmt.Errorf("%s not found in the DHT", fileName)
+	}
+
+	data, err := json.MarshalIndent(best, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	os.MkdirAll(TORRENTS_DIR, os.ModePerm)
+	path := torrentPath(fileName)
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// checkTorrent rejects a torrent whose chunk checksums do not describe a file
+// of its declared size. Only the whole-file checksum, verified once the file
+// is assembled, ties the chunks to the content the torrent is keyed by.
+func checkTorrent(metadata TorrentMetadata) error {
+	if metadata.ChunkSize != ChunkSize || metadata.FileSize < 0 {
+		return fmt.Errorf("chunk size %d for %d bytes", metadata.ChunkSize, metadata.FileSize)
+	}
+	chunks := int((metadata.FileSize + ChunkSize - 1) / ChunkSize)
+	if len(metadata.ChunkChecksums) != chunks {
+		return fmt.Errorf("%d chunk checksums for %d bytes", len(metadata.ChunkChecksums), metadata.FileSize)
+	}
+	for chunkID := range chunks {
+		sum, err := hex.DecodeString(metadata.ChunkChecksums[chunkID])
+		if err != nil || len(sum) != sha256.Size {
+			return fmt.Errorf("no valid checksum for chunk %d", chunkID)
+		}
+	}
+	return nil
+}
+
+// torrentPeers lists the peers named in local torrents, the contacts a peer
+// has before it knows any DHT node.
+func torrentPeers() []string {
+	entries, err := os.ReadDir(TORRENTS_DIR)
+	if err != nil {
+		return nil
+	}
+	var peers []string
+	for _, entry := range entries {
+		if filepath.Ext(entry.Name()) != ".torrent" {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(TORRENTS_DIR, entry.Name()))
+		if err != nil {
+			continue
+		}
+		var metadata TorrentMetadata
+		if json.Unmarshal(data, &metadata) != nil {
+			continue
+		}
+		for _, peer := range metadata.Peers {
+			if !slices.Contains(peers, peer) {
+				peers = append(peers, peer)
+			}
+		}
+	}
+	return peers
+}
@@ -0,0 +1,50 @@
+package client
+
+import (
+	"bytes"
+	"testing"
+)
+
+// testTorrent describes data the way the central server would.
+func testTorrent(name string, data []byte) TorrentMetadata {
+	metadata := TorrentMetadata{
+		FileName:       name,
+		FileSize:       int64(len(data)),
+		ChunkSize:      ChunkSize,
+		Checksum:       computeDataChecksum(data),
+		ChunkChecksums: make(map[int]string),
+	}
+	for chunkID := 0; chunkID*ChunkSize < len(data); chunkID++ {
+		chunk := data[chunkID*ChunkSize : min((chunkID+1)*ChunkSize, len(data))]
+		metadata.ChunkChecksums[chunkID] = computeDataChecksum(chunk)
+	}
+	return metadata
+}
+
+func TestCheckTorrent(t *testing.T) {
+	song := bytes.Repeat([]byte("la"), ChunkSize+10) // three chunks
+	tests := []struct {
+		name   string
+		change func(m *TorrentMetadata)
+		ok     bool
+	}{
+		{"as uploaded", func(m *TorrentMetadata) {}, true},
+		{"empty file", func(m *TorrentMetadata) { *m = testTorrent("empty.mp3", nil) }, true},
+		{"size too large", func(m *TorrentMetadata) { m.FileSize += ChunkSize }, false},
+		{"size too small", func(m *TorrentMetadata) { m.FileSize = ChunkSize }, false},
+		{"negative size", func(m *TorrentMetadata) { m.FileSize = -1 }, false},
+		{"missing chunk", func(m *TorrentMetadata) { delete(m.ChunkChecksums, 1) }, false},
+		{"chunk out of range", func(m *TorrentMetadata) { delete(m.ChunkChecksums, 2); m.ChunkChecksums[3] = m.ChunkChecksums[0] }, false},
+		{"bad checksum", func(m *TorrentMetadata) { m.ChunkChecksums[0] = "abc" }, false},
+		{"other chunk size", func(m *TorrentMetadata) { m.ChunkSize = 1 << 30 }, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			metadata := testTorrent("song.mp3", song)
+			tt.change(&metadata)
+			if err := checkTorrent(metadata); (err == nil) != tt.ok {
+				t.Errorf("checkTorrent = %v, want ok %v", err, tt.ok)
+			}
+		})
+	}
+}
@@ -7,8 +7,10 @@ import (
 	"log"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	pb "napster"
@@ -22,7 +24,26 @@ var DOWNLOAD_PATH = "./downloads"			// Folder for .crdownload, downloaded music
 const LIBRARY_DIR = "../torrents"	// Folder for storing torrent files
 var TORRENTS_DIR = "./downloads/torrents"	// Folder for storing torrent files
 var CACHE_DIR = "./downloads/cache"		// cache for downloads, to make it resumable
-var MAX_THREADS = 4							// Max. threads for multi-source downloading
+
+// maxThreads is the number of chunks fetched in parallel by each download,
+// changed from the settings while downloads run; 0 means defaultMaxThreads.
+var maxThreads atomic.Int32
+
+const defaultMaxThreads = 4
+
+// MaxThreads returns the number of chunks each download fetches in parallel.
+func MaxThreads() int {
+	if n := maxThreads.Load(); n > 0 {
+		return int(n)
+	}
+	return defaultMaxThreads
+}
+
+// SetMaxThreads sets the number of chunks downloads starting from now fetch
+// in parallel.
+func SetMaxThreads(n int) {
+	maxThreads.Store(int32(n))
+}
 
 func ParseTorrent(filepath string) (TorrentMetadata) {
 	data, err := os.ReadFile(filepath)
@@ -76,8 +97,26 @@ func IsExisting(metadata TorrentMetadata, filename string) bool {
 }
 
 func (p *PeerServer) DownloadFile(filename string) (string) {
+	return p.DownloadFileFrom(filename, "")
+}
+
+// DownloadFileFrom downloads a file listed by the central server origin, as
+// reported in search results; "" stands for this peer's own servers.
+func (p *PeerServer) DownloadFileFrom(filename string, origin string) (string) {
 
-	torrent_path := GetTorrent(p.Client, filename)
+	index, err := p.indexFor(origin)
+	if err != nil {
+		log.Printf("Cannot reach index %s: %v", origin, err)
+		return ""
+	}
+	torrent_path := GetTorrent(index, filename)
+	if torrent_path == "" && p.DHT != nil {
+		// The index is unreachable or does not know the file; ask the swarm.
+		torrent_path, err = p.lookupTorrent(filename)
+		if err != nil {
+			log.Printf("DHT lookup failed: %v", err)
+		}
+	}
 	if torrent_path == "" {
 		return ""
 	}
@@ -86,6 +125,9 @@ func (p *PeerServer) DownloadFile(filename string) (string) {
 	if metadata.FileName == "" {
 		return ""
 	}
+	if p.DHT != nil {
+		p.DHT.AddContacts(metadata.Peers)
+	}
 
 	p.EventEmitter("download-queue", metadata)
 
@@ -106,7 +148,7 @@ func (p *PeerServer) DownloadFile(filename string) (string) {
 		return ""
 	}
 
-	p.StartDownload(metadata, p.Client, p.PeerAddress)
+	p.StartDownload(metadata, index, p.PeerAddress)
 
 	return ""
 }
@@ -116,24 +158,125 @@ type DownloadTask struct {
 	ChunkName   string 
 	ClientAddr 	string
 	CheckSum	string
+	ctx			context.Context		// ends once any request delivers the chunk
 }
 
 type ChunkCoordinator struct {
 	chunkData   map[int][]byte
 	chunkReady  chan int
 	chunkMutex  *sync.Mutex
-	hashRing	*consistent.Consistent
+	hashRing	*consistent.Consistent	// seeders, holding every chunk
+	holders		map[string]Bitfield		// chunks held by peers still downloading, guarded by chunkMutex
+	numChunks	int
+	metadata	TorrentMetadata
+	pending		map[int]bool			// chunks waiting for a worker, guarded by chunkMutex
+	inFlight	map[int]*chunkRequest	// chunks being requested, guarded by chunkMutex
+	stats		map[string]*peerStat	// measurements of the peers, guarded by chunkMutex
+	limit		func(ctx context.Context, n int) error	// charges n downloaded bytes, waiting while over the limit
+	sequential	bool					// fetch chunks in order rather than rarest first, guarded by chunkMutex
+	dropped		map[string]time.Time	// when peers were removed from the ring after a failed request, guarded by chunkMutex
+	ctx		context.Context			// ends when the download completes or fails
+	fail		context.CancelCauseFunc
+}
+
+// admit adds a seeder to the ring unless it is already there or failed
+// earlier in this download, and reports whether it was added.
+func (c *ChunkCoordinator) admit(peer string) bool {
+	c.chunkMutex.Lock()
+	defer c.chunkMutex.Unlock()
+	if _, dropped := c.dropped[peer]; dropped || slices.Contains(c.hashRing.Members(), peer) {
+		return false
+	}
+	c.hashRing.Add(peer)
+	return true
+}
+
+// drop sets a failing peer aside until its cooldown is over.
+func (c *ChunkCoordinator) drop(peer string) {
+	c.chunkMutex.Lock()
+	defer c.chunkMutex.Unlock()
+	c.dropped[peer] = time.Now()
+	delete(c.holders, peer)
+	c.hashRing.Remove(peer)
+}
+
+// cooledDown returns the dropped peers whose peerCooldown is over.
+func (c *ChunkCoordinator) cooledDown() []string {
+	c.chunkMutex.Lock()
+	defer c.chunkMutex.Unlock()
+	var peers []string
+	for peer, at := range c.dropped {
+		if time.Since(at) >= peerCooldown {
+			peers = append(peers, peer)
+		}
+	}
+	return peers
+}
+
+// readmit takes a dropped peer back with the chunks it holds now.
+func (c *ChunkCoordinator) readmit(peer string, have Bitfield) {
+	c.chunkMutex.Lock()
+	delete(c.dropped, peer)
+	c.chunkMutex.Unlock()
+	c.addHolder(peer, have)
 }
 
 type DownloadStatus struct {
-    Filename string `json:"filename"`
-    Status   string `json:"status"`
+    Filename  string `json:"filename"`
+    Status    string `json:"status"`
+    Progress  int    `json:"progress,omitempty"`   // percent of file_size
+    BytesDone int64  `json:"bytes_done,omitempty"`
+    Error     string `json:"error,omitempty"`    // why a download failed
+    Peers     []PeerStats `json:"peers,omitempty"` // measured peers, the fastest first
 }
 
 func GetChunkName(filename string, chunkId int) string {
 	return fmt.Sprintf("%s_chunk_%d", filename, chunkId)
 }
 
+// chunkLength returns the exact size of a chunk; only the last one may be short.
+func chunkLength(metadata TorrentMetadata, chunkID int) int64 {
+	start := int64(chunkID) * int64(metadata.ChunkSize)
+	return max(0, min(int64(metadata.ChunkSize), metadata.FileSize-start))
+}
+
+// downloadedBytes sums the sizes of the complete chunks of a file found in
+// the download cache or the chunk store.
+func downloadedBytes(metadata TorrentMetadata) int64 {
+	var total int64
+	for chunkID := range len(metadata.ChunkChecksums) {
+		chunkName := GetChunkName(metadata.FileName, chunkID)
+		expected := chunkLength(metadata, chunkID)
+		for _, dir := range []string{CACHE_DIR, CHUNKS_DIR} {
+			if info, err := os.Stat(filepath.Join(dir, chunkName)); err == nil && info.Size() == expected {
+				total += expected
+				break
+			}
+		}
+	}
+	return total
+}
+
+// hasVerifiedChunks reports whether CHUNKS_DIR holds every chunk of a file
+// with a matching checksum.
+func hasVerifiedChunks(metadata TorrentMetadata) bool {
+	for chunkID := range len(metadata.ChunkChecksums) {
+		chunkPath := filepath.Join(CHUNKS_DIR, GetChunkName(metadata.FileName, chunkID))
+		if ok, err := verifyFileChecksum(chunkPath, metadata.ChunkChecksums[chunkID]); err != nil || !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// progressPercent converts a byte count into a percentage of the file size.
+func progressPercent(metadata TorrentMetadata, bytes int64) int {
+	if metadata.FileSize <= 0 {
+		return 0
+	}
+	return int(min(100, bytes*100/metadata.FileSize))
+}
+
 // Check and load already downloaded chunks into memory
 func ImportExistingChunks(metadata TorrentMetadata, chunkCoordinator *ChunkCoordinator) {
 
@@ -208,77 +351,105 @@ func (p *PeerServer) StartDownload(metadata TorrentMetadata, indexingClient pb.C
 	numChunks := len(metadata.ChunkChecksums)
 	// peerCount := len(metadata.Peers)
 
-	tasks := make(chan DownloadTask, numChunks)
-	
+	ctx, fail := context.WithCancelCause(context.Background())
+	defer fail(nil)
 	chunkCoordinator := &ChunkCoordinator{
 		chunkData: make(map[int][]byte),
 		chunkReady: make(chan int, numChunks),
 		chunkMutex: &sync.Mutex{},
 		hashRing: consistent.New(),
+		holders: make(map[string]Bitfield),
+		numChunks: numChunks,
+		metadata: metadata,
+		pending: make(map[int]bool),
+		inFlight: make(map[int]*chunkRequest),
+		stats: make(map[string]*peerStat),
+		limit: func(ctx context.Context, n int) error {
+			return p.throttleDownload(ctx, metadata.FileName, n)
+		},
+		dropped: make(map[string]time.Time),
+		ctx: ctx,
+		fail: fail,
 	}
 
 	p.EventEmitter("download-status", DownloadStatus{
 		Filename: metadata.FileName,
 		Status: "Downloading",
 	})
-	torrentStatus.RLock()
-	changeTorrentStatus(getFileName(metadata.FileName), "Downloading")
-	torrentStatus.RUnlock()
+	changeTorrentStatus(metadata.FileName, "Downloading")
 
-	time.Sleep(5 * time.Second)
+	time.Sleep(downloadStartDelay)
 
 	ImportExistingChunks(metadata, chunkCoordinator)
-
-	// Launch worker goroutines
-	for i := 0; i < MAX_THREADS; i++ {
-		go DownloadWorker(i, tasks, tasks, chunkCoordinator)
-	}
-
-	// chunkCoordinator.hashRing.NumberOfReplicas = 100
-	for _, peer := range metadata.Peers {
-		if peer != peerAddr {
-			chunkCoordinator.hashRing.Add(peer)
-		}
-	}
-
-	// Assign tasks round-robin
-	for chunkID := range numChunks {
-		if _, downloaded := chunkCoordinator.chunkData[chunkID]; downloaded {
-			continue
+	// Verified chunks are served to other downloaders from here on.
+	p.trackDownload(metadata.FileName, chunkCoordinator)
+	defer p.untrackDownload(metadata.FileName)
+
+	if metadata.Erasure != nil {
+		// Erasure-coded files are fetched stripe by stripe from the shard holders.
+		go fetchErasureCoded(metadata, chunkCoordinator, peerAddr)
+	} else {
+		// chunkCoordinator.hashRing.NumberOfReplicas = 100
+		for _, peer := range metadata.Peers {
+			if peer != peerAddr {
+				chunkCoordinator.admit(peer)
+			}
 		}
-		
-		// peerIndex := chunkID % peerCount
-		// clientAddr := metadata.Peers[peerIndex]
-
-		clientAddr, _ := chunkCoordinator.hashRing.Get(GetChunkName(metadata.FileName, chunkID))
-		
-		tasks <- DownloadTask{
-			ChunkID: chunkID, 
-			ChunkName: GetChunkName(metadata.FileName, chunkID), 
-			ClientAddr: clientAddr, 
-			CheckSum: metadata.ChunkChecksums[chunkID],
+		// Peers heard of through peer exchange may hold only part of the file.
+		go p.exchangeBitfields(ctx, metadata, chunkCoordinator, p.knownPeers(metadata.FileName))
+		go p.exchangePeers(ctx, metadata, chunkCoordinator)
+		go p.announceHolder(metadata)
+		go p.refreshSources(metadata, chunkCoordinator, indexingClient)
+		go p.measurePeers(chunkCoordinator)
+
+		// Workers pick the chunks to fetch, rarest first.
+		chunkCoordinator.queueMissing()
+		for i := range MaxThreads() {
+			go DownloadWorker(i, chunkCoordinator)
 		}
 	}
 
 	// writes to a file parallely as chunks are received
-	StreamWriter(metadata, chunkCoordinator)
-	close(tasks)
+	err := StreamWriter(metadata, chunkCoordinator, func(written int64) {
+		p.EventEmitter("download-status", DownloadStatus{
+			Filename: metadata.FileName,
+			Status: "Downloading",
+			Progress: progressPercent(metadata, written),
+			BytesDone: written,
+			Peers: chunkCoordinator.peerStats(),
+		})
+	})
+	// Stops the workers, peer exchange and source refresh.
+	fail(nil)
+	if err != nil {
+		log.Printf("Download of %s failed: %v", metadata.FileName, err)
+		p.EventEmitter("download-status", DownloadStatus{
+			Filename: metadata.FileName,
+			Status: "Failed",
+			Error: err.Error(),
+		})
+		changeTorrentStatus(metadata.FileName, "Failed")
+		return
+	}
 	
 	MoveChunksToStore(metadata.FileName)
+	go p.announceSeeder(metadata, chunkCoordinator.sources())
 
 	p.EventEmitter("download-status", DownloadStatus{
 		Filename: metadata.FileName,
 		Status: "Downloaded",
 	})
 
-	torrentStatus.RLock()
-	changeTorrentStatus(getFileName(metadata.FileName), "Downloaded")
-	torrentStatus.RUnlock()
+	changeTorrentStatus(metadata.FileName, "Downloaded")
 	
-	_, err := indexingClient.EnableSeeding(context.Background(), &pb.SeedingRequest{FileName: metadata.FileName, ClientAddr: peerAddr})
+	go p.Announce(metadata)
+	_, err = indexingClient.EnableSeeding(context.Background(), &pb.SeedingRequest{FileName: metadata.FileName, ClientAddr: peerAddr})
 	if err != nil {
 		log.Printf("Seeding Failed: %v", err)
-		return
+		if p.DHT == nil {
+			return
+		}
+		// Still seeding to the peers that find the file in the DHT.
 	}
 
 	time.Sleep(5 * time.Second)
@@ -288,20 +459,25 @@ func (p *PeerServer) StartDownload(metadata TorrentMetadata, indexingClient pb.C
 		Status: "Seeding",
 	})
 
-	torrentStatus.RLock()
-	changeTorrentStatus(getFileName(metadata.FileName), "Seeding")
-	torrentStatus.RUnlock()
+	changeTorrentStatus(metadata.FileName, "Seeding")
 }
 
-func RetryRequestChunk(task DownloadTask, tasks chan<- DownloadTask, chunkCoordinator *ChunkCoordinator) {
-	chunkCoordinator.hashRing.Remove(task.ClientAddr)
-	clientAddr, _ := chunkCoordinator.hashRing.Get(task.ChunkName)
+// RetryRequestChunk sets aside the peer a chunk request failed on and puts
+// the chunk back for another worker.
+func RetryRequestChunk(task DownloadTask, chunkCoordinator *ChunkCoordinator) {
+	chunkCoordinator.recordFailure(task.ClientAddr)
+	chunkCoordinator.drop(task.ClientAddr)
+	chunkCoordinator.release(task)
+}
 
-	tasks <- DownloadTask{
-		ChunkID: task.ChunkID,
-		ChunkName: task.ChunkName,
-		ClientAddr: clientAddr,
-		CheckSum: task.CheckSum,
+// discardChunks deletes the cached chunks of a failed download so a later
+// attempt does not import them.
+func discardChunks(filename string) {
+	entries, _ := os.ReadDir(CACHE_DIR)
+	for _, entry := range entries {
+		if strings.HasPrefix(entry.Name(), filename+"_chunk_") || strings.HasPrefix(entry.Name(), filename+"_parity_") {
+			os.Remove(filepath.Join(CACHE_DIR, entry.Name()))
+		}
 	}
 }
 
@@ -315,8 +491,8 @@ func getFileName(chunkName string) string {
 }
 
 func changeTorrentStatus(filename string, status string) {
-	torrentStatus.RLock()
-	defer torrentStatus.RUnlock()
+	torrentStatus.Lock()
+	defer torrentStatus.Unlock()
 
 	torrentStatus.status[filename] = status
 
@@ -360,8 +536,21 @@ func (p *PeerServer) PauseDownload(filename string) {
 	changeTorrentStatus(filename, "Paused")
 }
 
-func DownloadWorker(workerID int, tasks <-chan DownloadTask, sendTasks chan<- DownloadTask, chunkCoordinator *ChunkCoordinator) {
-	for task := range tasks {
+func DownloadWorker(workerID int, chunkCoordinator *ChunkCoordinator) {
+	for {
+		// Picked now rather than queued up front, so the order follows the
+		// peers and chunks known at this moment.
+		task, ok := chunkCoordinator.nextTask()
+		if !ok {
+			// The other chunks are being fetched, or no peer holds them;
+			// wait for a retry or the source refresh.
+			select {
+			case <-chunkCoordinator.ctx.Done():
+				return
+			case <-time.After(sourceWait):
+			}
+			continue
+		}
 
 		log.Printf("%d worker %d", workerID, task.ChunkID)
 
@@ -371,6 +560,7 @@ func DownloadWorker(workerID int, tasks <-chan DownloadTask, sendTasks chan<- Do
 			if debug_mode {
 				log.Printf("Pausing Download")
 			}
+			chunkCoordinator.release(task)
  			return
 		}
 
@@ -378,45 +568,57 @@ func DownloadWorker(workerID int, tasks <-chan DownloadTask, sendTasks chan<- Do
 		conn, err := grpc.NewClient(task.ClientAddr, grpc.WithTransportCredentials(insecure.NewCredentials()))
 		if err != nil {
 			log.Printf("Worker %d: Failed to connect to peer %s: %v", workerID, task.ClientAddr, err)
+			RetryRequestChunk(task, chunkCoordinator)
 			continue
 		}
 		client := pb.NewPeerServiceClient(conn)
 
 		// Request chunk
-		resp, err := client.RequestChunk(context.Background(), &pb.ChunkRequest{ChunkName: task.ChunkName})
+		ctx, cancel := context.WithTimeout(task.ctx, chunkTimeout)
+		start := time.Now()
+		resp, err := client.RequestChunk(ctx, &pb.ChunkRequest{ChunkName: task.ChunkName})
+		elapsed := time.Since(start)
+		cancel()
 		conn.Close()
 
+		// Only the bytes received count against the download limit, so
+		// refused, failed and cancelled requests cost nothing; waiting off the
+		// debt paces this worker's next request.
+		if err == nil && chunkCoordinator.limit(chunkCoordinator.ctx, len(resp.ChunkData)) != nil {
+			chunkCoordinator.release(task)
+			return
+		}
+		if chunkCoordinator.ctx.Err() != nil {
+			chunkCoordinator.release(task)
+			return
+		}
+		if task.ctx.Err() != nil {
+			// Another request delivered the chunk first.
+			continue
+		}
 		if err != nil || resp.Status != 200 {
 			log.Printf("Worker %d: Failed to download chunk %s from %s, retrying...", workerID, task.ChunkName, task.ClientAddr)
-			RetryRequestChunk(task, sendTasks, chunkCoordinator)
+			RetryRequestChunk(task, chunkCoordinator)
 			continue
 		}
 
-		os.Mkdir(CACHE_DIR, os.ModePerm)
-		chunkPath := filepath.Join(CACHE_DIR, task.ChunkName)
-		err = os.WriteFile(chunkPath, resp.ChunkData, 0644)
-		if err != nil {
-			log.Printf("Worker %d: Failed to write chunk %s: %v", workerID, task.ChunkName, err)
-		} else if debug_mode {
-			log.Printf("Worker %d: Successfully downloaded chunk %s from %s", workerID, task.ChunkName, task.ClientAddr)
-		}
-
-		res, err := verifyFileChecksum(chunkPath, task.CheckSum)
-		if err != nil || !res {
+		// Verified in memory: in endgame, several workers may hold the chunk.
+		if computeDataChecksum(resp.ChunkData) != task.CheckSum {
 			if debug_mode {
-				log.Printf("Worker %d: Failed to verify checksum %s: %v", workerID, task.ChunkName, err)
+				log.Printf("Worker %d: Failed to verify checksum %s from %s", workerID, task.ChunkName, task.ClientAddr)
 			}
-			return 
+			RetryRequestChunk(task, chunkCoordinator)
+			continue
 		}
 
-		// Save to chunkData and signal
-		chunkCoordinator.chunkMutex.Lock()
-		chunkCoordinator.chunkData[task.ChunkID] = resp.ChunkData
-		chunkCoordinator.chunkMutex.Unlock()
-		chunkCoordinator.chunkReady <- task.ChunkID
+		chunkCoordinator.recordTransfer(task.ClientAddr, len(resp.ChunkData), elapsed)
 
+		// Cache, save to chunkData and signal
+		if !chunkCoordinator.deliver(task.ChunkID, resp.ChunkData) {
+			continue
+		}
 		if debug_mode {
-			log.Printf("Checksum verified: %s", task.ChunkName)
+			log.Printf("Worker %d: Successfully downloaded chunk %s from %s", workerID, task.ChunkName, task.ClientAddr)
 		}
 	}
 }
@@ -432,7 +634,13 @@ func verifyFileChecksum(filePath, expectedChecksum string) (bool, error) {
 	return computedChecksum == expectedChecksum, nil
 }
 
-func StreamWriter(metadata TorrentMetadata, chunkCoordinator *ChunkCoordinator) {
+// StreamWriter appends chunks to the .crdownload file in order as they become
+// ready, reporting the bytes written so far through onProgress. It returns
+// the cause when the download fails first; the chunks already cached stay
+// for a later attempt. A file that does not hash to metadata.Checksum is not
+// moved into place: its chunks each matched the torrent, so the torrent itself
+// is forged, and the file and its chunks are deleted.
+func StreamWriter(metadata TorrentMetadata, chunkCoordinator *ChunkCoordinator, onProgress func(written int64)) error {
 	tempFilePath := filepath.Join(DOWNLOAD_PATH, metadata.FileName+".crdownload")
 	streamFile, err := os.Create(tempFilePath)
 	if err != nil {
@@ -442,6 +650,7 @@ func StreamWriter(metadata TorrentMetadata, chunkCoordinator *ChunkCoordinator)
 
 	expectedChunk := 0
 	totalChunks := len(metadata.ChunkChecksums)
+	var written int64
 	timeout := 10 * time.Millisecond
 
 	for expectedChunk < totalChunks {
@@ -458,6 +667,8 @@ func StreamWriter(metadata TorrentMetadata, chunkCoordinator *ChunkCoordinator)
 						log.Printf("Streamed chunk %d", readyID)
 					}
 					expectedChunk++
+					written += int64(len(data))
+					onProgress(written)
 				} else if debug_mode {
 					log.Printf("Chunk %d ready but missing in map", readyID)
 				}
@@ -466,12 +677,23 @@ func StreamWriter(metadata TorrentMetadata, chunkCoordinator *ChunkCoordinator)
 				// Requeue and wait for the chunk to become ready
 				chunkCoordinator.chunkReady <- readyID
 			}
+		case <-chunkCoordinator.ctx.Done():
+			return context.Cause(chunkCoordinator.ctx)
 		case <-time.After(timeout): 
 		}
 	}
 
 	streamFile.Close()
 
+	if _, checksum, err := computeFileChecksum(tempFilePath); err != nil || checksum != metadata.Checksum {
+		os.Remove(tempFilePath)
+		discardChunks(metadata.FileName)
+		if err != nil {
+			return err
+		}
+		return fmt.Errorf("%s does not match the checksum of its torrent", metadata.FileName)
+	}
+
 	// Once all chunks are streamed, rename the temporary file to the final filename
 	finalFilePath := filepath.Join(DOWNLOAD_PATH, metadata.FileName)
 	err = os.Rename(tempFilePath, finalFilePath)
@@ -480,6 +702,7 @@ func StreamWriter(metadata TorrentMetadata, chunkCoordinator *ChunkCoordinator)
 	}
 
 	log.Println("Streaming complete!")
+	return nil
 }
 
 type TorrentInfo struct {
@@ -528,7 +751,11 @@ func (c *PeerServer) GetLocalTorrents() ([]TorrentInfo, error) {
 				torrent_info.Progress = 100
 				torrent_info.Status = "Seeding"
 			} else {
-				// GetStatus()
+				torrent_info.Progress = progressPercent(meta, downloadedBytes(meta))
+				torrent_info.Status = getTorrentStatus(meta.FileName)
+				if torrent_info.Status == "" {
+					torrent_info.Status = meta.Status
+				}
 			}
 			fmt.Printf("Appending: %+v\n", torrent_info)
 			torrents = append(torrents, torrent_info)
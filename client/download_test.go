@@ -0,0 +1,73 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+// streamChunks runs StreamWriter over data split as metadata describes it,
+// with every chunk cached and ready.
+func streamChunks(t *testing.T, metadata TorrentMetadata, data []byte) error {
+	t.Helper()
+	os.MkdirAll(CACHE_DIR, os.ModePerm)
+	ctx, fail := context.WithCancelCause(context.Background())
+	defer fail(nil)
+	c := &ChunkCoordinator{
+		chunkData:  make(map[int][]byte),
+		chunkReady: make(chan int, len(metadata.ChunkChecksums)),
+		chunkMutex: &sync.Mutex{},
+		ctx:        ctx,
+	}
+	for chunkID := range len(metadata.ChunkChecksums) {
+		chunk := data[chunkID*ChunkSize : min((chunkID+1)*ChunkSize, len(data))]
+		c.chunkData[chunkID] = chunk
+		c.chunkReady <- chunkID
+		if err := os.WriteFile(filepath.Join(CACHE_DIR, GetChunkName(metadata.FileName, chunkID)), chunk, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return StreamWriter(metadata, c, func(int64) {})
+}
+
+func TestStreamWriterVerifiesFile(t *testing.T) {
+	song := bytes.Repeat([]byte("la"), ChunkSize+10)
+	forged := bytes.Repeat([]byte("xx"), ChunkSize+10)
+	// A forged torrent names the song's checksum but the chunks of other data.
+	forgedTorrent := testTorrent("song.mp3", forged)
+	forgedTorrent.Checksum = computeDataChecksum(song)
+
+	tests := []struct {
+		name     string
+		metadata TorrentMetadata
+		data     []byte
+		ok       bool
+	}{
+		{"genuine", testTorrent("song.mp3", song), song, true},
+		{"forged", forgedTorrent, forged, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			inTempDir(t)
+			err := streamChunks(t, tt.metadata, tt.data)
+			if (err == nil) != tt.ok {
+				t.Fatalf("StreamWriter = %v, want ok %v", err, tt.ok)
+			}
+
+			final, _ := os.ReadFile(filepath.Join(DOWNLOAD_PATH, "song.mp3"))
+			if tt.ok != bytes.Equal(final, tt.data) {
+				t.Errorf("downloaded file present: %v, want %v", final != nil, tt.ok)
+			}
+			if _, err := os.Stat(filepath.Join(DOWNLOAD_PATH, "song.mp3.crdownload")); !os.IsNotExist(err) {
+				t.Errorf("partial file left behind: %v", err)
+			}
+			cached, _ := os.ReadDir(CACHE_DIR)
+			if tt.ok != (len(cached) == 3) {
+				t.Errorf("%d chunks cached after the download", len(cached))
+			}
+		})
+	}
+}
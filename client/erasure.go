@@ -0,0 +1,325 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	pb "napster"
+	"napster/erasure"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// ErasureLayout describes how an erasure-coded file is spread over
+// contributors. Data chunks are grouped into stripes of DataShards chunks, the
+// last stripe padded with zero chunks, and every stripe gets ParityShards
+// parity chunks. Shard i is chunk i of every stripe; Holders[i] is the
+// contributor storing it.
+type ErasureLayout struct {
+	DataShards      int            `json:"data_shards"`
+	ParityShards    int            `json:"parity_shards"`
+	ParityChecksums map[int]string `json:"parity_checksums"` // parity chunk number -> checksum
+	Holders         []string       `json:"holders"`
+}
+
+func GetParityChunkName(filename string, parityID int) string {
+	return fmt.Sprintf("%s_parity_%d", filename, parityID)
+}
+
+func stripeCount(metadata TorrentMetadata) int {
+	k := metadata.Erasure.DataShards
+	return (len(metadata.ChunkChecksums) + k - 1) / k
+}
+
+// shardChunk returns the name, checksum and length of a shard's chunk in a
+// stripe. ok is false for the zero chunks padding the last stripe.
+func shardChunk(metadata TorrentMetadata, stripe int, shard int) (name string, checksum string, length int64, ok bool) {
+	k, m := metadata.Erasure.DataShards, metadata.Erasure.ParityShards
+	if shard < k {
+		chunkID := stripe*k + shard
+		if chunkID >= len(metadata.ChunkChecksums) {
+			return "", "", 0, false
+		}
+		return GetChunkName(metadata.FileName, chunkID), metadata.ChunkChecksums[chunkID], chunkLength(metadata, chunkID), true
+	}
+	parityID := stripe*m + shard - k
+	return GetParityChunkName(metadata.FileName, parityID), metadata.Erasure.ParityChecksums[parityID], int64(metadata.ChunkSize), true
+}
+
+// storeParityChunks computes the parity chunks of a file whose data chunks
+// are in CHUNKS_DIR, so the uploader can hand every shard to contributors.
+func storeParityChunks(metadata TorrentMetadata) error {
+	layout := metadata.Erasure
+	coder, err := erasure.New(layout.DataShards, layout.ParityShards)
+	if err != nil {
+		return err
+	}
+
+	for stripe := range stripeCount(metadata) {
+		data := make([][]byte, layout.DataShards)
+		for shard := range layout.DataShards {
+			name, _, _, ok := shardChunk(metadata, stripe, shard)
+			if !ok {
+				data[shard] = make([]byte, metadata.ChunkSize)
+				continue
+			}
+			chunk, err := os.ReadFile(filepath.Join(CHUNKS_DIR, name))
+			if err != nil {
+				return err
+			}
+			data[shard] = erasure.Pad(chunk, metadata.ChunkSize)
+		}
+
+		parity, err := coder.Encode(data)
+		if err != nil {
+			return err
+		}
+		for p, chunk := range parity {
+			parityID := stripe*layout.ParityShards + p
+			if computeDataChecksum(chunk) != layout.ParityChecksums[parityID] {
+				return fmt.Errorf("parity chunk %d of %s does not match the torrent", parityID, metadata.FileName)
+			}
+			if err := os.WriteFile(filepath.Join(CHUNKS_DIR, GetParityChunkName(metadata.FileName, parityID)), chunk, 0644); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// requestChunk fetches a chunk from the first source serving a copy that
+// matches checksum, charging the bytes received to limit.
+func requestChunk(chunkName string, checksum string, sources []string, limit func(ctx context.Context, n int) error) ([]byte, bool) {
+	for _, addr := range sources {
+		conn, err := grpc.NewClient(addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+		if err != nil {
+			continue
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		resp, err := pb.NewPeerServiceClient(conn).RequestChunk(ctx, &pb.ChunkRequest{ChunkName: chunkName})
+		cancel()
+		conn.Close()
+
+		if err == nil {
+			limit(context.Background(), len(resp.ChunkData))
+		}
+		if err == nil && resp.Status == 200 && computeDataChecksum(resp.ChunkData) == checksum {
+			return resp.ChunkData, true
+		}
+		if debug_mode {
+			log.Printf("Chunk %s not available from %s", chunkName, addr)
+		}
+	}
+	return nil, false
+}
+
+// shardSources lists where chunks of a shard can be fetched: the shard's
+// holder first, then the peers seeding the whole file.
+func shardSources(metadata TorrentMetadata, shard int, self string) []string {
+	var sources []string
+	if holder := metadata.Erasure.Holders[shard]; holder != "" && holder != self {
+		sources = append(sources, holder)
+	}
+	for _, peer := range metadata.Peers {
+		if peer != self && !containsAddr(sources, peer) {
+			sources = append(sources, peer)
+		}
+	}
+	return sources
+}
+
+func containsAddr(addrs []string, addr string) bool {
+	for _, a := range addrs {
+		if a == addr {
+			return true
+		}
+	}
+	return false
+}
+
+// fetchStripe returns the shards of a stripe, padded to the chunk size. The
+// wanted shards are fetched directly; if any of them cannot be found, enough
+// other shards are fetched to rebuild them. have holds chunks already on hand;
+// the chunks fetched are charged to limit.
+func fetchStripe(metadata TorrentMetadata, stripe int, want []int, have map[int][]byte, self string, limit func(ctx context.Context, n int) error) ([][]byte, error) {
+	layout := metadata.Erasure
+	total := layout.DataShards + layout.ParityShards
+	shards := make([][]byte, total)
+	present := 0
+	for shard := range total {
+		if _, _, _, ok := shardChunk(metadata, stripe, shard); !ok {
+			shards[shard] = make([]byte, metadata.ChunkSize)
+			present++
+		} else if data, ok := have[shard]; ok {
+			shards[shard] = erasure.Pad(data, metadata.ChunkSize)
+			present++
+		}
+	}
+
+	fetch := func(shard int) bool {
+		name, checksum, _, _ := shardChunk(metadata, stripe, shard)
+		data, ok := requestChunk(name, checksum, shardSources(metadata, shard, self), limit)
+		if ok {
+			shards[shard] = erasure.Pad(data, metadata.ChunkSize)
+			present++
+		}
+		return ok
+	}
+
+	complete := true
+	for _, shard := range want {
+		if shards[shard] == nil && !fetch(shard) {
+			complete = false
+		}
+	}
+	if complete {
+		return shards, nil
+	}
+
+	for shard := 0; shard < total && present < layout.DataShards; shard++ {
+		if shards[shard] == nil {
+			fetch(shard)
+		}
+	}
+	coder, err := erasure.New(layout.DataShards, layout.ParityShards)
+	if err != nil {
+		return nil, err
+	}
+	if err := coder.Reconstruct(shards); err != nil {
+		return nil, err
+	}
+	return shards, nil
+}
+
+// fetchErasureCoded downloads the missing data chunks of an erasure-coded
+// file stripe by stripe, rebuilding chunks no source can serve from any
+// DataShards shards of their stripe.
+func fetchErasureCoded(metadata TorrentMetadata, chunkCoordinator *ChunkCoordinator, self string) {
+	stripes := make(chan int)
+	var wg sync.WaitGroup
+	for range MaxThreads() {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for stripe := range stripes {
+				fetchDataStripe(metadata, stripe, chunkCoordinator, self)
+			}
+		}()
+	}
+	for stripe := range stripeCount(metadata) {
+		stripes <- stripe
+	}
+	close(stripes)
+	wg.Wait()
+}
+
+func fetchDataStripe(metadata TorrentMetadata, stripe int, chunkCoordinator *ChunkCoordinator, self string) {
+	k := metadata.Erasure.DataShards
+	have := make(map[int][]byte)
+	var want []int
+
+	chunkCoordinator.chunkMutex.Lock()
+	for shard := range k {
+		chunkID := stripe*k + shard
+		if chunkID >= len(metadata.ChunkChecksums) {
+			break
+		}
+		if data, ok := chunkCoordinator.chunkData[chunkID]; ok {
+			have[shard] = data
+		} else {
+			want = append(want, shard)
+		}
+	}
+	chunkCoordinator.chunkMutex.Unlock()
+
+	for len(want) > 0 {
+		if getTorrentStatus(metadata.FileName) == "Paused" {
+			return
+		}
+		shards, err := fetchStripe(metadata, stripe, want, have, self, chunkCoordinator.limit)
+		if err == nil {
+			for _, shard := range want {
+				_, checksum, length, _ := shardChunk(metadata, stripe, shard)
+				data := shards[shard][:length]
+				if computeDataChecksum(data) != checksum {
+					err = fmt.Errorf("rebuilt chunk %d does not match its checksum", stripe*k+shard)
+					break
+				}
+				chunkCoordinator.deliver(stripe*k+shard, data)
+			}
+			if err == nil {
+				return
+			}
+		}
+		log.Printf("Stripe %d of %s: %v, retrying...", stripe, metadata.FileName, err)
+		time.Sleep(5 * time.Second)
+	}
+}
+
+// fetchTorrent downloads and parses a torrent without saving it.
+func fetchTorrent(client pb.CentralServerClient, filename string) (TorrentMetadata, error) {
+	var metadata TorrentMetadata
+	res, err := client.GetTorrent(context.Background(), &pb.SearchRequest{Query: filename})
+	if err != nil {
+		return metadata, err
+	}
+	if res.Status != 200 {
+		return metadata, fmt.Errorf("torrent of %s: status %d", filename, res.Status)
+	}
+	err = json.Unmarshal(res.Content, &metadata)
+	return metadata, err
+}
+
+// StoreShard is how the server places a shard of an erasure-coded file on a
+// contributor. The shard's chunks are fetched from seeders, or rebuilt from
+// the other shard holders, and stored in CHUNKS_DIR where RequestChunk serves
+// them.
+func (p *PeerServer) StoreShard(ctx context.Context, req *pb.ShardRequest) (*pb.GenResponse, error) {
+	metadata, err := fetchTorrent(p.Client, req.FileName)
+	if err != nil || metadata.Erasure == nil {
+		log.Printf("Cannot store shard of %s: %v", req.FileName, err)
+		return &pb.GenResponse{Status: 404}, nil
+	}
+	shard := int(req.Shard)
+	if shard < 0 || shard >= metadata.Erasure.DataShards+metadata.Erasure.ParityShards {
+		return &pb.GenResponse{Status: 400}, nil
+	}
+
+	limit := func(ctx context.Context, n int) error {
+		return p.throttleDownload(ctx, req.FileName, n)
+	}
+	os.MkdirAll(CHUNKS_DIR, os.ModePerm)
+	for stripe := range stripeCount(metadata) {
+		name, checksum, length, ok := shardChunk(metadata, stripe, shard)
+		if !ok {
+			continue
+		}
+		path := filepath.Join(CHUNKS_DIR, name)
+		if verified, _ := verifyFileChecksum(path, checksum); verified {
+			continue
+		}
+
+		shards, err := fetchStripe(metadata, stripe, []int{shard}, nil, p.PeerAddress, limit)
+		if err != nil {
+			log.Printf("Cannot store shard %d of %s: stripe %d: %v", shard, req.FileName, stripe, err)
+			return &pb.GenResponse{Status: 500}, nil
+		}
+		data := shards[shard][:length]
+		if computeDataChecksum(data) != checksum {
+			return &pb.GenResponse{Status: 500}, nil
+		}
+		if err := os.WriteFile(path, data, 0644); err != nil {
+			return &pb.GenResponse{Status: 500}, nil
+		}
+	}
+
+	log.Printf("Stored shard %d of %s", shard, req.FileName)
+	return &pb.GenResponse{Status: 200}, nil
+}
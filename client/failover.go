@@ -0,0 +1,354 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	pb "napster"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	healthCheckTimeout = 2 * time.Second
+	failoverWindow     = 10 * time.Second // how long a call waits for a server, e.g. during an election
+	failoverBackoff    = 500 * time.Millisecond
+)
+
+var errNoServer = status.Error(codes.Unavailable, "no central server reachable")
+
+// FailoverClient is a CentralServerClient over several central servers,
+// usually the members of one cluster. Calls go to one healthy server,
+// preferably the cluster leader since only it accepts changes. When that
+// server goes down or turns out not to lead any more (both answer with
+// codes.Unavailable), the servers are health-checked again and the call is
+// retried on the one picked, so callers such as running downloads carry on.
+type FailoverClient struct {
+	mu      sync.Mutex
+	addrs   []string
+	conns   map[string]*grpc.ClientConn
+	clients map[string]pb.CentralServerClient
+	current string        // server calls go to, "" until one is picked
+	last    string        // server picked last, for logging switches
+	probing chan struct{} // closed when the running health checks end, nil if none run
+}
+
+// GetIndexingClient connects to the central servers at serverAddrs. No
+// connection is made yet: the first call health-checks the servers.
+func GetIndexingClient(serverAddrs []string) (*FailoverClient, error) {
+	if len(serverAddrs) == 0 {
+		return nil, errors.New("no central server address given")
+	}
+
+	f := &FailoverClient{
+		conns:   make(map[string]*grpc.ClientConn),
+		clients: make(map[string]pb.CentralServerClient),
+	}
+	if err := f.SetServers(serverAddrs); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return f, nil
+}
+
+// SetServers replaces the list of central servers. Connections to servers
+// still listed are kept; the next call health-checks the new list unless the
+// current server is still on it.
+func (f *FailoverClient) SetServers(serverAddrs []string) error {
+	if len(serverAddrs) == 0 {
+		return errors.New("no central server address given")
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var addrs []string
+	conns := make(map[string]*grpc.ClientConn)
+	clients := make(map[string]pb.CentralServerClient)
+	for _, addr := range serverAddrs {
+		if _, exists := conns[addr]; exists {
+			continue
+		}
+		conn, exists := f.conns[addr]
+		if !exists {
+			var err error
+			conn, err = grpc.NewClient(addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+			if err != nil {
+				for added, conn := range conns {
+					if _, old := f.conns[added]; !old {
+						conn.Close()
+					}
+				}
+				return err
+			}
+		}
+		addrs = append(addrs, addr)
+		conns[addr] = conn
+		clients[addr] = pb.NewCentralServerClient(conn)
+	}
+
+	for addr, conn := range f.conns {
+		if _, kept := conns[addr]; !kept {
+			conn.Close()
+		}
+	}
+	f.addrs, f.conns, f.clients = addrs, conns, clients
+	if _, kept := conns[f.current]; !kept {
+		f.current = ""
+	}
+	return nil
+}
+
+// ParseServerList splits a comma-separated list of server addresses.
+func ParseServerList(list string) []string {
+	var addrs []string
+	for _, addr := range strings.Split(list, ",") {
+		if addr = strings.TrimSpace(addr); addr != "" {
+			addrs = append(addrs, addr)
+		}
+	}
+	return addrs
+}
+
+// Close releases the connections to every server.
+func (f *FailoverClient) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, conn := range f.conns {
+		conn.Close()
+	}
+	return nil
+}
+
+// Current returns the server calls go to, "" if none is reachable.
+func (f *FailoverClient) Current() string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.current
+}
+
+// Has reports whether addr is one of the servers.
+func (f *FailoverClient) Has(addr string) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	_, exists := f.clients[addr]
+	return exists
+}
+
+// server returns the server to call, health-checking them if none is picked.
+// The health checks run without f.mu, so other callers are not held up by
+// servers that do not answer; calls arriving meanwhile wait for their result.
+func (f *FailoverClient) server(ctx context.Context) (string, pb.CentralServerClient, error) {
+	f.mu.Lock()
+	if f.current == "" {
+		if probing := f.probing; probing != nil {
+			f.mu.Unlock()
+			select {
+			case <-probing:
+			case <-ctx.Done():
+				return "", nil, errNoServer
+			}
+			f.mu.Lock()
+		} else {
+			probing := make(chan struct{})
+			f.probing = probing
+			addrs, clients := f.addrs, f.clients
+			f.mu.Unlock()
+
+			picked := probe(ctx, addrs, clients)
+
+			f.mu.Lock()
+			f.probing = nil
+			close(probing)
+			// The servers may have changed meanwhile.
+			if _, listed := f.clients[picked]; listed && f.current == "" {
+				f.current = picked
+				if f.current != f.last {
+					log.Printf("Using central server %s", f.current)
+					f.last = f.current
+				}
+			}
+		}
+	}
+	defer f.mu.Unlock()
+
+	if f.current == "" {
+		return "", nil, errNoServer
+	}
+	return f.current, f.clients[f.current], nil
+}
+
+// probe health-checks the servers in order and returns the leader, or the
+// first live server while no leader is known.
+func probe(ctx context.Context, addrs []string, clients map[string]pb.CentralServerClient) string {
+	alive := ""
+	for _, addr := range addrs {
+		hctx, cancel := context.WithTimeout(ctx, healthCheckTimeout)
+		res, err := clients[addr].HealthCheckServer(hctx, &pb.HealthCheckRequest{})
+		cancel()
+		if err != nil || !res.Alive {
+			continue
+		}
+		if res.Leader {
+			return addr
+		}
+		if alive == "" {
+			alive = addr
+		}
+	}
+	return alive
+}
+
+// failed reports whether err means addr could not serve the call, in which
+// case another server is picked for the next attempt.
+func (f *FailoverClient) failed(addr string, err error) bool {
+	if status.Code(err) != codes.Unavailable {
+		return false
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.current == addr {
+		if debug_mode {
+			log.Printf("Central server %s unavailable: %v", addr, err)
+		}
+		f.current = ""
+	}
+	return true
+}
+
+// invoke runs call on a healthy server, moving on to another one while
+// servers answer with codes.Unavailable, for up to failoverWindow.
+func invoke[T any](f *FailoverClient, ctx context.Context, call func(pb.CentralServerClient) (T, error)) (T, error) {
+	deadline := time.Now().Add(failoverWindow)
+	for {
+		var res T
+		addr, client, err := f.server(ctx)
+		if err == nil {
+			res, err = call(client)
+			if !f.failed(addr, err) {
+				return res, err
+			}
+		}
+		if time.Now().After(deadline) {
+			return res, err
+		}
+		select {
+		case <-ctx.Done():
+			return res, err
+		case <-time.After(failoverBackoff):
+		}
+	}
+}
+
+// uploadStream reports a stream refused by its server, so the upload can be
+// sent again to another one.
+type uploadStream struct {
+	pb.CentralServer_UploadFileClient
+	f    *FailoverClient
+	addr string
+}
+
+func (s *uploadStream) CloseAndRecv() (*pb.UploadResponse, error) {
+	res, err := s.CentralServer_UploadFileClient.CloseAndRecv()
+	s.f.failed(s.addr, err)
+	return res, err
+}
+
+func (f *FailoverClient) UploadFile(ctx context.Context, opts ...grpc.CallOption) (pb.CentralServer_UploadFileClient, error) {
+	addr, client, err := f.server(ctx)
+	if err != nil {
+		return nil, err
+	}
+	stream, err := client.UploadFile(ctx, opts...)
+	if err != nil {
+		f.failed(addr, err)
+		return nil, err
+	}
+	return &uploadStream{CentralServer_UploadFileClient: stream, f: f, addr: addr}, nil
+}
+
+func (f *FailoverClient) RegisterPeer(ctx context.Context, in *pb.RegisterRequest, opts ...grpc.CallOption) (*pb.RegisterResponse, error) {
+	return invoke(f, ctx, func(c pb.CentralServerClient) (*pb.RegisterResponse, error) {
+		return c.RegisterPeer(ctx, in, opts...)
+	})
+}
+
+func (f *FailoverClient) Heartbeat(ctx context.Context, in *pb.HeartbeatRequest, opts ...grpc.CallOption) (*pb.HeartbeatResponse, error) {
+	return invoke(f, ctx, func(c pb.CentralServerClient) (*pb.HeartbeatResponse, error) {
+		return c.Heartbeat(ctx, in, opts...)
+	})
+}
+
+func (f *FailoverClient) SearchFile(ctx context.Context, in *pb.SearchRequest, opts ...grpc.CallOption) (*pb.SearchResponse, error) {
+	return invoke(f, ctx, func(c pb.CentralServerClient) (*pb.SearchResponse, error) {
+		return c.SearchFile(ctx, in, opts...)
+	})
+}
+
+func (f *FailoverClient) RankedSearch(ctx context.Context, in *pb.RankedSearchRequest, opts ...grpc.CallOption) (*pb.RankedSearchResponse, error) {
+	return invoke(f, ctx, func(c pb.CentralServerClient) (*pb.RankedSearchResponse, error) {
+		return c.RankedSearch(ctx, in, opts...)
+	})
+}
+
+func (f *FailoverClient) GetTorrent(ctx context.Context, in *pb.SearchRequest, opts ...grpc.CallOption) (*pb.TorrentResponse, error) {
+	return invoke(f, ctx, func(c pb.CentralServerClient) (*pb.TorrentResponse, error) {
+		return c.GetTorrent(ctx, in, opts...)
+	})
+}
+
+func (f *FailoverClient) EnableSeeding(ctx context.Context, in *pb.SeedingRequest, opts ...grpc.CallOption) (*pb.GenResponse, error) {
+	return invoke(f, ctx, func(c pb.CentralServerClient) (*pb.GenResponse, error) {
+		return c.EnableSeeding(ctx, in, opts...)
+	})
+}
+
+func (f *FailoverClient) StopSeeding(ctx context.Context, in *pb.SeedingRequest, opts ...grpc.CallOption) (*pb.GenResponse, error) {
+	return invoke(f, ctx, func(c pb.CentralServerClient) (*pb.GenResponse, error) {
+		return c.StopSeeding(ctx, in, opts...)
+	})
+}
+
+func (f *FailoverClient) HealthCheck(ctx context.Context, in *pb.HealthCheckRequest, opts ...grpc.CallOption) (*pb.HealthCheckResponse, error) {
+	return invoke(f, ctx, func(c pb.CentralServerClient) (*pb.HealthCheckResponse, error) {
+		return c.HealthCheck(ctx, in, opts...)
+	})
+}
+
+func (f *FailoverClient) HealthCheckServer(ctx context.Context, in *pb.HealthCheckRequest, opts ...grpc.CallOption) (*pb.HealthCheckResponse, error) {
+	return invoke(f, ctx, func(c pb.CentralServerClient) (*pb.HealthCheckResponse, error) {
+		return c.HealthCheckServer(ctx, in, opts...)
+	})
+}
+
+func (f *FailoverClient) RegisterContributor(ctx context.Context, in *pb.ContributorRequest, opts ...grpc.CallOption) (*pb.GenResponse, error) {
+	return invoke(f, ctx, func(c pb.CentralServerClient) (*pb.GenResponse, error) {
+		return c.RegisterContributor(ctx, in, opts...)
+	})
+}
+
+func (f *FailoverClient) ContributorHeartbeat(ctx context.Context, in *pb.ContributorRequest, opts ...grpc.CallOption) (*pb.HeartbeatResponse, error) {
+	return invoke(f, ctx, func(c pb.CentralServerClient) (*pb.HeartbeatResponse, error) {
+		return c.ContributorHeartbeat(ctx, in, opts...)
+	})
+}
+
+func (f *FailoverClient) DeregisterContributor(ctx context.Context, in *pb.ContributorRequest, opts ...grpc.CallOption) (*pb.GenResponse, error) {
+	return invoke(f, ctx, func(c pb.CentralServerClient) (*pb.GenResponse, error) {
+		return c.DeregisterContributor(ctx, in, opts...)
+	})
+}
+
+func (f *FailoverClient) ListContributors(ctx context.Context, in *pb.ListContributorsRequest, opts ...grpc.CallOption) (*pb.ListContributorsResponse, error) {
+	return invoke(f, ctx, func(c pb.CentralServerClient) (*pb.ListContributorsResponse, error) {
+		return c.ListContributors(ctx, in, opts...)
+	})
+}
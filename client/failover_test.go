@@ -0,0 +1,187 @@
+package client
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "napster"
+)
+
+// fakeCentralServer answers health checks and searches as one server of a
+// cluster would.
+type fakeCentralServer struct {
+	pb.CentralServerClient
+	name   string
+	hold   chan struct{} // when set, health checks wait until it is closed
+	checks atomic.Int32
+
+	mu      sync.Mutex
+	down    bool
+	leading bool
+}
+
+func (s *fakeCentralServer) set(down, leading bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.down, s.leading = down, leading
+}
+
+func (s *fakeCentralServer) HealthCheckServer(ctx context.Context, in *pb.HealthCheckRequest, opts ...grpc.CallOption) (*pb.HealthCheckResponse, error) {
+	s.checks.Add(1)
+	if s.hold != nil {
+		<-s.hold
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.down {
+		return nil, status.Error(codes.Unavailable, "down")
+	}
+	return &pb.HealthCheckResponse{Alive: true, Leader: s.leading}, nil
+}
+
+// SearchFile answers with the server's name. Like a follower refusing
+// changes, it fails with codes.Unavailable unless the server leads.
+func (s *fakeCentralServer) SearchFile(ctx context.Context, in *pb.SearchRequest, opts ...grpc.CallOption) (*pb.SearchResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.down || !s.leading {
+		return nil, status.Error(codes.Unavailable, "not leading")
+	}
+	if in.Query == "missing" {
+		return nil, status.Error(codes.NotFound, "no such file")
+	}
+	return &pb.SearchResponse{Results: []*pb.SongInfo{{FileName: s.name}}}, nil
+}
+
+func newFakeFailover(servers ...*fakeCentralServer) *FailoverClient {
+	f := &FailoverClient{clients: make(map[string]pb.CentralServerClient)}
+	for _, s := range servers {
+		f.addrs = append(f.addrs, s.name)
+		f.clients[s.name] = s
+	}
+	return f
+}
+
+func TestFailoverPrefersLeader(t *testing.T) {
+	tests := []struct {
+		name    string
+		down    []bool
+		leading []bool
+		want    string
+	}{
+		{"leader", []bool{false, false, false}, []bool{false, true, false}, "b"},
+		{"no leader known", []bool{false, false, false}, []bool{false, false, false}, "a"},
+		{"first down", []bool{true, false, false}, []bool{false, false, false}, "b"},
+		{"all down", []bool{true, true, true}, []bool{false, false, false}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			servers := []*fakeCentralServer{{name: "a"}, {name: "b"}, {name: "c"}}
+			for i, s := range servers {
+				s.set(tt.down[i], tt.leading[i])
+			}
+			f := newFakeFailover(servers...)
+			addr, _, err := f.server(context.Background())
+			if addr != tt.want || (tt.want == "") != (err != nil) {
+				t.Errorf("server = %q, %v, want %q", addr, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestFailoverRetriesUnavailable(t *testing.T) {
+	a, b := &fakeCentralServer{name: "a"}, &fakeCentralServer{name: "b"}
+	a.set(false, true)
+	f := newFakeFailover(a, b)
+	search := func(ctx context.Context, query string) (string, error) {
+		res, err := f.SearchFile(ctx, &pb.SearchRequest{Query: query})
+		if err != nil {
+			return "", err
+		}
+		return res.Results[0].FileName, nil
+	}
+
+	if got, err := search(context.Background(), "song"); got != "a" || err != nil {
+		t.Fatalf("search = %q, %v, want a", got, err)
+	}
+
+	// The leader fails over to b; the call moves with it.
+	a.set(true, false)
+	b.set(false, true)
+	if got, err := search(context.Background(), "song"); got != "b" || err != nil {
+		t.Fatalf("search after failover = %q, %v, want b", got, err)
+	}
+	if f.Current() != "b" {
+		t.Errorf("current = %q, want b", f.Current())
+	}
+
+	// Other errors are the server's answer and are not retried.
+	checks := a.checks.Load() + b.checks.Load()
+	if _, err := search(context.Background(), "missing"); status.Code(err) != codes.NotFound {
+		t.Errorf("search for a missing file: %v", err)
+	}
+	if a.checks.Load()+b.checks.Load() != checks || f.Current() != "b" {
+		t.Error("NotFound answer moved the client off its server")
+	}
+
+	// With every server down, the call gives up when its context ends.
+	b.set(true, false)
+	ctx, cancel := context.WithTimeout(context.Background(), 2*failoverBackoff)
+	defer cancel()
+	if _, err := search(ctx, "song"); status.Code(err) != codes.Unavailable {
+		t.Errorf("search with every server down: %v", err)
+	}
+}
+
+// Health checks run without the client's lock, once for every call that
+// waits on them.
+func TestFailoverProbeUnlocked(t *testing.T) {
+	slow := &fakeCentralServer{name: "a", hold: make(chan struct{})}
+	slow.set(false, true)
+	f := newFakeFailover(slow)
+
+	var wg sync.WaitGroup
+	picked := make(chan string, 5)
+	for range 5 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			addr, _, _ := f.server(context.Background())
+			picked <- addr
+		}()
+	}
+	for slow.checks.Load() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		f.Current()
+		f.Has("a")
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("client locked during health checks")
+	}
+
+	close(slow.hold)
+	wg.Wait()
+	close(picked)
+	for addr := range picked {
+		if addr != "a" {
+			t.Errorf("call picked %q", addr)
+		}
+	}
+	if checks := slow.checks.Load(); checks != 1 {
+		t.Errorf("%d health checks for concurrent calls, want 1", checks)
+	}
+}
@@ -0,0 +1,47 @@
+package client
+
+import (
+	"sync"
+
+	pb "napster"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// Connections to the central servers of federated indexes, by address.
+var (
+	originsMu sync.Mutex
+	origins   = make(map[string]pb.CentralServerClient)
+)
+
+// ownIndex reports whether a search result with this origin comes from this
+// peer's own central servers.
+func (p *PeerServer) ownIndex(origin string) bool {
+	if origin == "" {
+		return true
+	}
+	f, ok := p.Client.(*FailoverClient)
+	return ok && f.Has(origin)
+}
+
+// indexFor returns the client for the central server whose index lists a
+// search result. Results of this peer's own servers, or of a server that does
+// not federate (empty origin), go through p.Client.
+func (p *PeerServer) indexFor(origin string) (pb.CentralServerClient, error) {
+	if p.ownIndex(origin) {
+		return p.Client, nil
+	}
+
+	originsMu.Lock()
+	defer originsMu.Unlock()
+	if client, exists := origins[origin]; exists {
+		return client, nil
+	}
+	conn, err := grpc.NewClient(origin, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return nil, err
+	}
+	origins[origin] = pb.NewCentralServerClient(conn)
+	return origins[origin], nil
+}
@@ -0,0 +1,51 @@
+package client
+
+import (
+	"testing"
+
+	pb "napster"
+)
+
+func TestIndexFor(t *testing.T) {
+	own := newFakeFailover(&fakeCentralServer{name: "localhost:50051"}, &fakeCentralServer{name: "localhost:50052"})
+	p := &PeerServer{Client: own}
+	t.Cleanup(func() {
+		originsMu.Lock()
+		delete(origins, "remote:50051")
+		originsMu.Unlock()
+	})
+
+	tests := []struct {
+		origin string
+		own    bool
+	}{
+		{"", true},
+		{"localhost:50052", true},
+		{"remote:50051", false},
+	}
+	for _, tt := range tests {
+		if got := p.ownIndex(tt.origin); got != tt.own {
+			t.Errorf("ownIndex(%q) = %v, want %v", tt.origin, got, tt.own)
+		}
+		index, err := p.indexFor(tt.origin)
+		if err != nil {
+			t.Fatalf("indexFor(%q): %v", tt.origin, err)
+		}
+		if (index == pb.CentralServerClient(own)) != tt.own {
+			t.Errorf("indexFor(%q) went to the own servers: %v", tt.origin, !tt.own)
+		}
+	}
+
+	// Connections to other indexes are reused.
+	first, _ := p.indexFor("remote:50051")
+	if again, _ := p.indexFor("remote:50051"); again != first {
+		t.Error("second indexFor opened a new connection")
+	}
+
+	// A peer whose client cannot tell its servers apart treats only results
+	// without an origin as its own.
+	p = &PeerServer{Client: &fakeCentralServer{name: "localhost:50051"}}
+	if p.ownIndex("localhost:50051") || !p.ownIndex("") {
+		t.Error("origins of a plain client misjudged")
+	}
+}
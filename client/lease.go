@@ -0,0 +1,119 @@
+package client
+
+import (
+	"context"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	pb "napster"
+)
+
+// defaultLease is used until the server tells us the real lease length.
+const defaultLease = 30 * time.Second
+
+// MaintainLease registers this peer with the central server and renews the
+// lease with heartbeats, re-registering whenever the server has forgotten us.
+// It never returns; run it in its own goroutine.
+func (p *PeerServer) MaintainLease() {
+	lease := p.registerPeer()
+	for {
+		time.Sleep(lease / 3)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		res, err := p.Client.Heartbeat(ctx, &pb.HeartbeatRequest{
+			PeerAddress: p.PeerAddress,
+			FileNames:   seededFiles(),
+		})
+		cancel()
+
+		if err != nil {
+			if debug_mode {
+				log.Printf("Heartbeat failed: %v", err)
+			}
+			continue
+		}
+		if res.Reregister {
+			lease = p.registerPeer()
+			continue
+		}
+		if res.LeaseSeconds > 0 {
+			lease = time.Duration(res.LeaseSeconds) * time.Second
+		}
+	}
+}
+
+// registerPeer announces this peer and its seeded files, returning the lease length.
+func (p *PeerServer) registerPeer() time.Duration {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	res, err := p.Client.RegisterPeer(ctx, &pb.RegisterRequest{
+		PeerId:      p.PeerAddress,
+		PeerAddress: p.PeerAddress,
+		FileNames:   seededFiles(),
+	})
+	if err != nil || !res.Success {
+		log.Printf("Peer registration failed: %v", err)
+		return defaultLease
+	}
+
+	log.Printf("Registered with server, lease %ds", res.LeaseSeconds)
+	return time.Duration(res.LeaseSeconds) * time.Second
+}
+
+// seededFiles lists the files whose chunks are in CHUNKS_DIR, skipping those
+// the user stopped seeding.
+func seededFiles() []string {
+	entries, err := os.ReadDir(CHUNKS_DIR)
+	if err != nil {
+		return nil
+	}
+
+	seen := make(map[string]bool)
+	for _, entry := range entries {
+		name := getFileName(entry.Name())
+		if name == "" || seen[name] {
+			continue
+		}
+		if getTorrentStatus(name) == "Downloaded" {
+			continue
+		}
+		// Shard holders keep only part of a file's chunks; only peers with
+		// the whole file are seeders.
+		if _, err := os.Stat(filepath.Join(DOWNLOAD_PATH, name)); err != nil {
+			continue
+		}
+		seen[name] = true
+	}
+
+	files := make([]string, 0, len(seen))
+	for name := range seen {
+		files = append(files, name)
+	}
+	sort.Strings(files)
+	return files
+}
+
+// EnableSeeding lists this peer as a seeder of filename again.
+func (p *PeerServer) EnableSeeding(filename string) error {
+	changeTorrentStatus(filename, "Seeding")
+	if metadata := ParseTorrent(torrentPath(filename)); metadata.FileName != "" {
+		go p.Announce(metadata)
+	}
+	_, err := p.Client.EnableSeeding(context.Background(), &pb.SeedingRequest{
+		FileName: filename, ClientAddr: p.PeerAddress,
+	})
+	return err
+}
+
+// StopSeeding removes this peer from the seeders of filename.
+func (p *PeerServer) StopSeeding(filename string) error {
+	changeTorrentStatus(filename, "Downloaded")
+	_, err := p.Client.StopSeeding(context.Background(), &pb.SeedingRequest{
+		FileName: filename, ClientAddr: p.PeerAddress,
+	})
+	return err
+}
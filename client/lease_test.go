@@ -0,0 +1,99 @@
+package client
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"testing"
+)
+
+// inTempDir runs the test from a fresh directory, as CHUNKS_DIR is relative,
+// and points the download directories into it.
+func inTempDir(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	oldDownloads := DOWNLOAD_PATH
+	UseDownloadDir(filepath.Join(dir, "downloads"))
+	t.Cleanup(func() {
+		os.Chdir(wd)
+		UseDownloadDir(oldDownloads)
+	})
+	return dir
+}
+
+func TestSeededFiles(t *testing.T) {
+	inTempDir(t)
+	os.MkdirAll(CHUNKS_DIR, os.ModePerm)
+	os.MkdirAll(DOWNLOAD_PATH, os.ModePerm)
+
+	tests := []struct {
+		file       string
+		status     string
+		downloaded bool // whole file in DOWNLOAD_PATH
+		seeded     bool
+	}{
+		{"seeding.mp3", "Seeding", true, true},
+		{"unknown.mp3", "", true, true},
+		{"stopped.mp3", "Downloaded", true, false},
+		{"shards.mp3", "Seeding", false, false},
+	}
+	var want []string
+	for _, tt := range tests {
+		os.WriteFile(filepath.Join(CHUNKS_DIR, GetChunkName(tt.file, 0)), []byte("chunk"), 0644)
+		if tt.downloaded {
+			os.WriteFile(filepath.Join(DOWNLOAD_PATH, tt.file), []byte("file"), 0644)
+		}
+		if tt.status != "" {
+			changeTorrentStatus(tt.file, tt.status)
+		}
+		if tt.seeded {
+			want = append(want, tt.file)
+		}
+		t.Cleanup(func() { changeTorrentStatus(tt.file, "") })
+	}
+	slices.Sort(want)
+
+	if got := seededFiles(); !slices.Equal(got, want) {
+		t.Fatalf("seededFiles() = %v, want %v", got, want)
+	}
+}
+
+// Heartbeats and bitfield requests list the seeded files while downloads
+// change torrent statuses; run with -race.
+func TestSeededFilesWhileStatusChanges(t *testing.T) {
+	inTempDir(t)
+	os.MkdirAll(CHUNKS_DIR, os.ModePerm)
+	os.MkdirAll(DOWNLOAD_PATH, os.ModePerm)
+	files := []string{"a.mp3", "b.mp3", "c.mp3"}
+	for _, file := range files {
+		os.WriteFile(filepath.Join(CHUNKS_DIR, GetChunkName(file, 0)), []byte("chunk"), 0644)
+		os.WriteFile(filepath.Join(DOWNLOAD_PATH, file), []byte("file"), 0644)
+		t.Cleanup(func() { changeTorrentStatus(file, "") })
+	}
+
+	var wg sync.WaitGroup
+	for _, file := range files {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			for i := range 50 {
+				changeTorrentStatus(file, []string{"Downloading", "Downloaded", "Seeding"}[i%3])
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for range 50 {
+				seededFiles()
+			}
+		}()
+	}
+	wg.Wait()
+}
@@ -0,0 +1,189 @@
+package client
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	pb "napster"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+const (
+	statsInterval     = 10 * time.Second // between RTT measurements during a download
+	statsWeight       = 0.3              // of a new measurement in a peer's running average
+	defaultThroughput = 1 << 20          // bytes/sec assumed of a peer before any is measured
+)
+
+// PeerStats are what a download measured of one of its peers, as sent in
+// download status events.
+type PeerStats struct {
+	Address     string `json:"address"`
+	RTTMillis   int64  `json:"rtt_ms"`        // HealthCheck round trip, 0 if not measured yet
+	BytesPerSec int64  `json:"bytes_per_sec"` // chunk transfer rate, 0 if not measured yet
+	Chunks      int    `json:"chunks"`        // chunks received
+	Failures    int    `json:"failures"`      // chunk requests that failed
+}
+
+// peerStat is the running measurement of a peer, guarded by chunkMutex.
+type peerStat struct {
+	rtt        time.Duration
+	throughput float64
+	chunks     int
+	failures   int
+}
+
+func (c *ChunkCoordinator) stat(peer string) *peerStat {
+	stat, exists := c.stats[peer]
+	if !exists {
+		stat = &peerStat{}
+		c.stats[peer] = stat
+	}
+	return stat
+}
+
+// recordRTT adds a HealthCheck round trip to a peer's average.
+func (c *ChunkCoordinator) recordRTT(peer string, rtt time.Duration) {
+	c.chunkMutex.Lock()
+	defer c.chunkMutex.Unlock()
+	stat := c.stat(peer)
+	if stat.rtt == 0 {
+		stat.rtt = rtt
+		return
+	}
+	stat.rtt = time.Duration(statsWeight*float64(rtt) + (1-statsWeight)*float64(stat.rtt))
+}
+
+// recordTransfer adds a chunk received from a peer to its average rate.
+func (c *ChunkCoordinator) recordTransfer(peer string, bytes int, elapsed time.Duration) {
+	c.chunkMutex.Lock()
+	defer c.chunkMutex.Unlock()
+	stat := c.stat(peer)
+	stat.chunks++
+	rate := float64(bytes) / max(elapsed.Seconds(), 1e-6)
+	if stat.throughput == 0 {
+		stat.throughput = rate
+		return
+	}
+	stat.throughput = statsWeight*rate + (1-statsWeight)*stat.throughput
+}
+
+func (c *ChunkCoordinator) recordFailure(peer string) {
+	c.chunkMutex.Lock()
+	defer c.chunkMutex.Unlock()
+	c.stat(peer).failures++
+}
+
+// weights returns how likely each candidate is to be asked for a chunk: the
+// inverse of the time it is expected to take, one round trip plus the chunk
+// at the peer's rate. Peers not measured yet are expected to do as well as
+// the average peer, so they get their chance. chunkMutex must be held.
+func (c *ChunkCoordinator) weights(candidates []string) []float64 {
+	var rttSum time.Duration
+	var rateSum float64
+	var rtts, rates int
+	for _, stat := range c.stats {
+		if stat.rtt > 0 {
+			rttSum += stat.rtt
+			rtts++
+		}
+		if stat.throughput > 0 {
+			rateSum += stat.throughput
+			rates++
+		}
+	}
+	avgRTT, avgRate := time.Duration(0), float64(defaultThroughput)
+	if rtts > 0 {
+		avgRTT = rttSum / time.Duration(rtts)
+	}
+	if rates > 0 {
+		avgRate = rateSum / float64(rates)
+	}
+
+	chunkSize := float64(max(c.metadata.ChunkSize, 1))
+	weights := make([]float64, len(candidates))
+	for i, peer := range candidates {
+		rtt, rate := avgRTT, avgRate
+		if stat, exists := c.stats[peer]; exists {
+			if stat.rtt > 0 {
+				rtt = stat.rtt
+			}
+			if stat.throughput > 0 {
+				rate = stat.throughput
+			}
+		}
+		weights[i] = 1 / (rtt.Seconds() + chunkSize/rate)
+	}
+	return weights
+}
+
+// peerStats returns the measurements of the peers of this download, the
+// fastest first.
+func (c *ChunkCoordinator) peerStats() []PeerStats {
+	c.chunkMutex.Lock()
+	defer c.chunkMutex.Unlock()
+	stats := make([]PeerStats, 0, len(c.stats))
+	for peer, stat := range c.stats {
+		stats = append(stats, PeerStats{
+			Address:     peer,
+			RTTMillis:   stat.rtt.Milliseconds(),
+			BytesPerSec: int64(stat.throughput),
+			Chunks:      stat.chunks,
+			Failures:    stat.failures,
+		})
+	}
+	slices.SortFunc(stats, func(a, b PeerStats) int { return cmp.Compare(b.BytesPerSec, a.BytesPerSec) })
+	return stats
+}
+
+// measurePeers pings the peers of a download every statsInterval until it
+// ends, keeping their round-trip times up to date.
+func (p *PeerServer) measurePeers(chunkCoordinator *ChunkCoordinator) {
+	ticker := time.NewTicker(statsInterval)
+	defer ticker.Stop()
+	for {
+		var wg sync.WaitGroup
+		for _, addr := range chunkCoordinator.sources() {
+			wg.Add(1)
+			go func(addr string) {
+				defer wg.Done()
+				if rtt, err := pingPeer(chunkCoordinator.ctx, addr); err == nil {
+					chunkCoordinator.recordRTT(addr, rtt)
+				}
+			}(addr)
+		}
+		wg.Wait()
+
+		select {
+		case <-chunkCoordinator.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// pingPeer measures the round trip of a HealthCheck to a peer.
+func pingPeer(ctx context.Context, addr string) (time.Duration, error) {
+	conn, err := grpc.NewClient(addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return 0, err
+	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithTimeout(ctx, pexTimeout)
+	defer cancel()
+	client := pb.NewPeerServiceClient(conn)
+	// The first call also sets up the connection; only the second is timed.
+	if _, err := client.HealthCheck(ctx, &pb.HealthCheckRequest{}); err != nil {
+		return 0, err
+	}
+	start := time.Now()
+	if _, err := client.HealthCheck(ctx, &pb.HealthCheckRequest{}); err != nil {
+		return 0, err
+	}
+	return time.Since(start), nil
+}
@@ -0,0 +1,177 @@
+package client
+
+import (
+	"context"
+	"math"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+
+	pb "napster"
+)
+
+func TestWeights(t *testing.T) {
+	c := newTestCoordinator(t, 4, nil, nil)
+	c.recordRTT("fast", 10*time.Millisecond)
+	c.recordTransfer("fast", ChunkSize, 100*time.Millisecond)
+	c.recordRTT("slow", 200*time.Millisecond)
+	c.recordTransfer("slow", ChunkSize, 2*time.Second)
+	c.recordFailure("failing") // counted, not measured
+
+	c.chunkMutex.Lock()
+	weights := c.weights([]string{"fast", "slow", "new", "failing"})
+	c.chunkMutex.Unlock()
+	if weights[0] <= weights[1] {
+		t.Errorf("fast peer weighs %v, not above the slow one's %v", weights[0], weights[1])
+	}
+
+	// Unmeasured peers are expected to do as well as the average peer.
+	avgRTT := 105 * time.Millisecond
+	avgRate := (ChunkSize/0.1 + ChunkSize/2.0) / 2
+	want := 1 / (avgRTT.Seconds() + ChunkSize/avgRate)
+	for i, peer := range []string{"new", "failing"} {
+		if got := weights[2+i]; math.Abs(got-want) > 1e-9*want {
+			t.Errorf("%s weighs %v, want the average %v", peer, got, want)
+		}
+	}
+	if weights[2] >= weights[0] || weights[2] <= weights[1] {
+		t.Errorf("average weight %v not between slow %v and fast %v", weights[2], weights[1], weights[0])
+	}
+
+	// Before any measurement, every peer weighs the same.
+	c = newTestCoordinator(t, 4, nil, nil)
+	c.chunkMutex.Lock()
+	weights = c.weights([]string{"a", "b"})
+	c.chunkMutex.Unlock()
+	if weights[0] != weights[1] || weights[0] != defaultThroughput/float64(ChunkSize) {
+		t.Errorf("unmeasured weights = %v", weights)
+	}
+}
+
+func TestPickPeerFavoursFaster(t *testing.T) {
+	c := newTestCoordinator(t, 4, []string{"fast", "slow"}, nil)
+	c.recordTransfer("fast", ChunkSize, 100*time.Millisecond)
+	c.recordTransfer("slow", ChunkSize, time.Second)
+
+	picks := make(map[string]int)
+	c.chunkMutex.Lock()
+	for range 2000 {
+		peer, _ := c.pickPeer(0, nil)
+		picks[peer]++
+	}
+	c.chunkMutex.Unlock()
+	// Weighted 10 to 1: about 1818 to 182.
+	if picks["fast"] < 1500 || picks["slow"] == 0 {
+		t.Errorf("picks = %v, want fast about ten times as often, slow still picked", picks)
+	}
+}
+
+func TestPeerStats(t *testing.T) {
+	c := newTestCoordinator(t, 4, nil, nil)
+	c.recordTransfer("a", 1000, time.Second)
+	c.recordTransfer("a", 2000, time.Second) // 0.3*2000 + 0.7*1000
+	c.recordRTT("a", 10*time.Millisecond)
+	c.recordRTT("a", 20*time.Millisecond)
+	c.recordTransfer("b", 5000, time.Second)
+	c.recordFailure("b")
+
+	want := []PeerStats{
+		{Address: "b", BytesPerSec: 5000, Chunks: 1, Failures: 1},
+		{Address: "a", RTTMillis: 13, BytesPerSec: 1300, Chunks: 2},
+	}
+	got := c.peerStats()
+	if len(got) != len(want) {
+		t.Fatalf("peerStats = %+v, want %+v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("peerStats[%d] = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+// chunkPeer is a seeder on 127.0.0.1 serving the chunks of one file.
+type chunkPeer struct {
+	pb.UnimplementedPeerServiceServer
+	addr     string
+	metadata TorrentMetadata
+	data     []byte
+}
+
+func newChunkPeer(t *testing.T, metadata TorrentMetadata, data []byte) *chunkPeer {
+	t.Helper()
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	peer := &chunkPeer{addr: lis.Addr().String(), metadata: metadata, data: data}
+	server := grpc.NewServer()
+	pb.RegisterPeerServiceServer(server, peer)
+	go server.Serve(lis)
+	t.Cleanup(server.Stop)
+	return peer
+}
+
+func (p *chunkPeer) RequestChunk(ctx context.Context, req *pb.ChunkRequest) (*pb.ChunkResponse, error) {
+	for chunkID := range len(p.metadata.ChunkChecksums) {
+		if req.ChunkName == GetChunkName(p.metadata.FileName, chunkID) {
+			start := int64(chunkID) * int64(p.metadata.ChunkSize)
+			return &pb.ChunkResponse{Status: 200, ChunkData: p.data[start : start+chunkLength(p.metadata, chunkID)]}, nil
+		}
+	}
+	return &pb.ChunkResponse{Status: 404}, nil
+}
+
+func (p *chunkPeer) HealthCheck(ctx context.Context, req *pb.HealthCheckRequest) (*pb.HealthCheckResponse, error) {
+	return &pb.HealthCheckResponse{Alive: true}, nil
+}
+
+func TestDownloadStatusPeerStats(t *testing.T) {
+	inTempDir(t)
+	shortenTimeouts(t, time.Hour, time.Hour)
+	data := []byte(strings.Repeat("x", 3*ChunkSize))
+	metadata := testTorrent("song.mp3", data)
+	seeder := newChunkPeer(t, metadata, data)
+	metadata.Peers = []string{seeder.addr}
+	writeTestTorrent(t, metadata)
+	t.Cleanup(func() { changeTorrentStatus(metadata.FileName, "") })
+
+	events := make(chan DownloadStatus, 100)
+	p := &PeerServer{PeerAddress: "self:1", EventEmitter: func(eventName string, event any) {
+		if status, ok := event.(DownloadStatus); ok {
+			events <- status
+		}
+	}}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		p.StartDownload(metadata, noTorrentServer{}, p.PeerAddress)
+	}()
+	select {
+	case <-done:
+	case <-time.After(10 * time.Second):
+		t.Fatal("download still running")
+	}
+	close(events)
+
+	// The last progress event reports every chunk, each from the seeder.
+	var progress, last DownloadStatus
+	for event := range events {
+		if event.Status == "Downloading" && event.BytesDone > 0 {
+			progress = event
+		}
+		last = event
+	}
+	if last.Status != "Downloaded" {
+		t.Fatalf("last event %+v, want the download finished", last)
+	}
+	if progress.BytesDone != metadata.FileSize || len(progress.Peers) != 1 {
+		t.Fatalf("last progress %+v, want the whole file and the seeder's stats", progress)
+	}
+	if stats := progress.Peers[0]; stats.Address != seeder.addr || stats.Chunks < 1 || stats.BytesPerSec <= 0 || stats.Failures != 0 {
+		t.Errorf("seeder stats %+v", stats)
+	}
+}
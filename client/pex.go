@@ -0,0 +1,200 @@
+package client
+
+import (
+	"context"
+	"log"
+	"math/rand"
+	"os"
+	"slices"
+	"sync"
+	"time"
+
+	pb "napster"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+const (
+	pexInterval = 15 * time.Second // between exchanges during a download
+	pexFanout   = 5                // peers asked per exchange
+	pexTimeout  = 3 * time.Second
+	pexPeerTTL  = 30 * time.Minute // how long a peer heard of is passed on
+	pexMaxPeers = 50               // peers returned per exchange
+	pexMaxKnown = 200              // peers remembered per file
+)
+
+// ExchangePeers tells a downloader the seeders and partial holders of a file
+// this peer knows, and learns the ones the downloader knows in return. Only
+// files this peer has a torrent for or is downloading are exchanged, so
+// requests for other names cannot grow the peers remembered.
+func (p *PeerServer) ExchangePeers(ctx context.Context, req *pb.PeerExchangeRequest) (*pb.PeerExchangeResponse, error) {
+	chunkCoordinator := p.activeDownload(req.FileName)
+	var metadata TorrentMetadata
+	if chunkCoordinator != nil {
+		metadata = chunkCoordinator.metadata
+	} else if _, err := os.Stat(torrentPath(req.FileName)); err == nil {
+		metadata = ParseTorrent(torrentPath(req.FileName))
+	}
+	if metadata.FileName == "" {
+		return &pb.PeerExchangeResponse{}, nil
+	}
+	if metadata.Checksum != "" && req.Checksum != "" && metadata.Checksum != req.Checksum {
+		// Another file under the same name.
+		return &pb.PeerExchangeResponse{}, nil
+	}
+
+	p.rememberPeers(req.FileName, req.Peers)
+	holding := Bitfield(req.Bitfield).count(len(req.Bitfield)*8) > 0
+	if req.Seeding || holding {
+		p.rememberPeers(req.FileName, []string{req.Sender})
+	}
+	if chunkCoordinator != nil && holding {
+		chunkCoordinator.addHolder(req.Sender, Bitfield(req.Bitfield))
+	}
+
+	peers := p.knownPeers(req.FileName)
+	if slices.Contains(seededFiles(), req.FileName) || (chunkCoordinator != nil && chunkCoordinator.bitfield().count(chunkCoordinator.numChunks) > 0) {
+		peers = append([]string{p.PeerAddress}, peers...)
+	}
+	peers = slices.DeleteFunc(peers, func(peer string) bool { return peer == req.Sender })
+	return &pb.PeerExchangeResponse{Peers: peers[:min(len(peers), pexMaxPeers)]}, nil
+}
+
+// exchangePeers runs peer exchange with the seeders and partial holders of a
+// download every pexInterval until ctx ends. The peers learned, and the
+// partial holders already known, are asked which chunks they hold.
+func (p *PeerServer) exchangePeers(ctx context.Context, metadata TorrentMetadata, chunkCoordinator *ChunkCoordinator) {
+	ticker := time.NewTicker(pexInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		learned := p.exchangeWith(ctx, metadata, chunkCoordinator.sources(), false, chunkCoordinator.bitfield())
+		chunkCoordinator.chunkMutex.Lock()
+		for peer := range chunkCoordinator.holders {
+			learned = append(learned, peer)
+		}
+		chunkCoordinator.chunkMutex.Unlock()
+		slices.Sort(learned)
+		p.exchangeBitfields(ctx, metadata, chunkCoordinator, slices.Compact(learned))
+	}
+}
+
+// announceSeeder tells the seeders of a finished download that this peer
+// serves the file too, so their other downloaders learn about it.
+func (p *PeerServer) announceSeeder(metadata TorrentMetadata, seeders []string) {
+	ctx, cancel := context.WithTimeout(context.Background(), pexTimeout)
+	defer cancel()
+	p.exchangeWith(ctx, metadata, seeders, true, nil)
+}
+
+// exchangeWith runs one exchange with up to pexFanout of the given peers at
+// once and returns the peers they know. have is the bitfield of a peer still
+// downloading, nil once seeding.
+func (p *PeerServer) exchangeWith(ctx context.Context, metadata TorrentMetadata, seeders []string, seeding bool, have Bitfield) []string {
+	known := slices.Clone(seeders)
+	rand.Shuffle(len(seeders), func(i, j int) { seeders[i], seeders[j] = seeders[j], seeders[i] })
+	seeders = seeders[:min(len(seeders), pexFanout)]
+
+	var (
+		mu      sync.Mutex
+		learned []string
+		wg      sync.WaitGroup
+	)
+	for _, addr := range seeders {
+		wg.Add(1)
+		go func(addr string) {
+			defer wg.Done()
+			conn, err := grpc.NewClient(addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+			if err != nil {
+				return
+			}
+			defer conn.Close()
+
+			rctx, cancel := context.WithTimeout(ctx, pexTimeout)
+			defer cancel()
+			res, err := pb.NewPeerServiceClient(conn).ExchangePeers(rctx, &pb.PeerExchangeRequest{
+				FileName: metadata.FileName,
+				Checksum: metadata.Checksum,
+				Sender:   p.PeerAddress,
+				Seeding:  seeding,
+				Peers:    known,
+				Bitfield: have,
+			})
+			if err != nil {
+				if debug_mode {
+					log.Printf("Peer exchange with %s failed: %v", addr, err)
+				}
+				return
+			}
+			mu.Lock()
+			learned = append(learned, res.Peers...)
+			mu.Unlock()
+		}(addr)
+	}
+	wg.Wait()
+
+	p.rememberPeers(metadata.FileName, learned)
+	return learned
+}
+
+// rememberPeers records seeders and partial holders of a file heard of
+// through peer exchange. Past pexMaxKnown peers, those heard of longest ago
+// are forgotten.
+func (p *PeerServer) rememberPeers(fileName string, peers []string) {
+	p.pexMu.Lock()
+	defer p.pexMu.Unlock()
+
+	if p.pexPeers == nil {
+		p.pexPeers = make(map[string]map[string]time.Time)
+	}
+	known, exists := p.pexPeers[fileName]
+	if !exists {
+		known = make(map[string]time.Time)
+		p.pexPeers[fileName] = known
+	}
+	now := time.Now()
+	for _, peer := range peers {
+		if peer != "" && peer != p.PeerAddress {
+			known[peer] = now
+		}
+	}
+	if len(known) <= pexMaxKnown {
+		return
+	}
+	oldest := make([]string, 0, len(known))
+	for peer := range known {
+		oldest = append(oldest, peer)
+	}
+	slices.SortFunc(oldest, func(a, b string) int { return known[a].Compare(known[b]) })
+	for _, peer := range oldest[:len(known)-pexMaxKnown] {
+		delete(known, peer)
+	}
+}
+
+// knownPeers returns the seeders and partial holders of a file heard of
+// within pexPeerTTL, most recently heard first.
+func (p *PeerServer) knownPeers(fileName string) []string {
+	p.pexMu.Lock()
+	defer p.pexMu.Unlock()
+
+	known := p.pexPeers[fileName]
+	var peers []string
+	for peer, heard := range known {
+		if time.Since(heard) > pexPeerTTL {
+			delete(known, peer)
+			continue
+		}
+		peers = append(peers, peer)
+	}
+	if len(known) == 0 {
+		delete(p.pexPeers, fileName)
+	}
+	slices.SortFunc(peers, func(a, b string) int { return known[b].Compare(known[a]) })
+	return peers
+}
@@ -0,0 +1,102 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"testing"
+	"time"
+
+	pb "napster"
+)
+
+func TestKnownPeers(t *testing.T) {
+	p := &PeerServer{PeerAddress: "self:1"}
+	p.rememberPeers("song.mp3", []string{"p1", "", "self:1", "p2"})
+	p.pexPeers["song.mp3"]["p1"] = time.Now().Add(-time.Minute)
+	p.rememberPeers("other.mp3", []string{"p3"})
+
+	// Most recently heard first; neither self nor empty addresses are kept.
+	if got := p.knownPeers("song.mp3"); !slices.Equal(got, []string{"p2", "p1"}) {
+		t.Errorf("knownPeers = %v, want [p2 p1]", got)
+	}
+
+	// Peers are forgotten after pexPeerTTL, and files with them.
+	p.pexPeers["song.mp3"]["p1"] = time.Now().Add(-pexPeerTTL - time.Second)
+	if got := p.knownPeers("song.mp3"); !slices.Equal(got, []string{"p2"}) {
+		t.Errorf("knownPeers = %v, want the expired p1 dropped", got)
+	}
+	p.pexPeers["other.mp3"]["p3"] = time.Now().Add(-pexPeerTTL - time.Second)
+	if got := p.knownPeers("other.mp3"); len(got) != 0 {
+		t.Errorf("knownPeers = %v, want none", got)
+	}
+	if _, kept := p.pexPeers["other.mp3"]; kept {
+		t.Error("file without peers still tracked")
+	}
+}
+
+func TestRememberPeersCap(t *testing.T) {
+	p := &PeerServer{}
+	p.rememberPeers("song.mp3", []string{"old"})
+	p.pexPeers["song.mp3"]["old"] = time.Now().Add(-time.Minute)
+
+	var peers []string
+	for i := range pexMaxKnown {
+		peers = append(peers, fmt.Sprintf("p%d", i))
+	}
+	p.rememberPeers("song.mp3", peers)
+
+	got := p.knownPeers("song.mp3")
+	if len(got) != pexMaxKnown || slices.Contains(got, "old") {
+		t.Errorf("remembered %d peers (old kept: %v), want the %d newest", len(got), slices.Contains(got, "old"), pexMaxKnown)
+	}
+}
+
+func TestExchangePeers(t *testing.T) {
+	inTempDir(t)
+	seedTestFile(t, TorrentMetadata{FileName: "seeded.mp3", Checksum: "sum"})
+	downloading := newTestCoordinator(t, 4, nil, nil)
+	downloading.metadata.Checksum = "sum"
+
+	tests := []struct {
+		name      string
+		req       *pb.PeerExchangeRequest
+		want      []string // peers returned
+		remembers []string
+	}{
+		{"unknown file", &pb.PeerExchangeRequest{FileName: "unknown.mp3", Sender: "s:1", Seeding: true, Peers: []string{"p1"}}, nil, nil},
+		{"checksum mismatch", &pb.PeerExchangeRequest{FileName: "seeded.mp3", Checksum: "other", Sender: "s:1", Seeding: true, Peers: []string{"p1"}}, nil, nil},
+		{"seeded file", &pb.PeerExchangeRequest{FileName: "seeded.mp3", Checksum: "sum", Sender: "s:1", Seeding: true, Peers: []string{"p1"}},
+			[]string{"self:1", "p1"}, []string{"p1", "s:1"}},
+		{"downloader without chunks", &pb.PeerExchangeRequest{FileName: "seeded.mp3", Sender: "s:1", Peers: []string{"p2"}},
+			[]string{"self:1", "p2"}, []string{"p2"}},
+		{"active download", &pb.PeerExchangeRequest{FileName: "song.mp3", Checksum: "sum", Sender: "h:1", Peers: []string{"p3"}, Bitfield: []byte{0x80}},
+			[]string{"p3"}, []string{"h:1", "p3"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &PeerServer{PeerAddress: "self:1"}
+			p.trackDownload("song.mp3", downloading)
+			res, err := p.ExchangePeers(context.Background(), tt.req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !slices.Equal(res.Peers, tt.want) {
+				t.Errorf("returned %v, want %v", res.Peers, tt.want)
+			}
+			remembered := p.knownPeers(tt.req.FileName)
+			slices.Sort(remembered)
+			if !slices.Equal(remembered, tt.remembers) {
+				t.Errorf("remembered %v, want %v", remembered, tt.remembers)
+			}
+		})
+	}
+
+	// A partial holder joins the download it offers chunks for.
+	downloading.chunkMutex.Lock()
+	have, added := downloading.holders["h:1"]
+	downloading.chunkMutex.Unlock()
+	if !added || !have.Has(0) {
+		t.Errorf("holder h:1 not added to the download")
+	}
+}
@@ -0,0 +1,218 @@
+package client
+
+import (
+	"context"
+	"log"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+)
+
+const (
+	endgameRequests = 3                // peers asked at once for one chunk in endgame
+	chunkTimeout    = 30 * time.Second // before a peer that has not sent a chunk is given up
+)
+
+// chunkRequest tracks the requests out for one chunk. All share ctx, which
+// is cancelled as soon as one of them delivers the chunk.
+type chunkRequest struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	peers  []string // asked and not answered yet
+}
+
+// queueMissing marks every chunk not loaded yet as waiting to be fetched.
+func (c *ChunkCoordinator) queueMissing() {
+	c.chunkMutex.Lock()
+	defer c.chunkMutex.Unlock()
+	for chunkID := range c.numChunks {
+		if _, downloaded := c.chunkData[chunkID]; !downloaded {
+			c.pending[chunkID] = true
+		}
+	}
+}
+
+// nextTask picks the chunk a worker fetches next, and the peer to ask for
+// it. Chunks held by the fewest peers come first (rarest first, as in
+// BitTorrent), ties broken at random, so the copies the swarm would lose
+// when peers leave are spread first. A download set to sequential takes its
+// chunks in order instead, for playback.
+//
+// Once no waiting chunk can be fetched, the download is in its endgame: the
+// chunks still being fetched are requested from other peers as well, up to
+// endgameRequests at once, so a slow peer cannot hold up the last chunks.
+// ok is false when there is nothing to request.
+func (c *ChunkCoordinator) nextTask() (task DownloadTask, ok bool) {
+	c.chunkMutex.Lock()
+	defer c.chunkMutex.Unlock()
+
+	if chunkID := c.pickChunk(); chunkID >= 0 {
+		addr, _ := c.pickPeer(chunkID, nil)
+		delete(c.pending, chunkID)
+		ctx, cancel := context.WithCancel(c.ctx)
+		request := &chunkRequest{ctx: ctx, cancel: cancel}
+		c.inFlight[chunkID] = request
+		return c.newTask(chunkID, addr, request), true
+	}
+
+	chunkID, addr := -1, ""
+	for candidate, request := range c.inFlight {
+		if len(request.peers) >= endgameRequests || (chunkID >= 0 && len(request.peers) >= len(c.inFlight[chunkID].peers)) {
+			continue
+		}
+		if peer, found := c.pickPeer(candidate, request.peers); found {
+			chunkID, addr = candidate, peer
+		}
+	}
+	if chunkID < 0 {
+		return task, false
+	}
+	if debug_mode {
+		log.Printf("Endgame: chunk %d also requested from %s", chunkID, addr)
+	}
+	return c.newTask(chunkID, addr, c.inFlight[chunkID]), true
+}
+
+// pickChunk returns the waiting chunk to fetch next, -1 if no peer holds
+// any. chunkMutex must be held.
+func (c *ChunkCoordinator) pickChunk() int {
+	seeders := len(c.hashRing.Members())
+	chunkID, rarest, ties := -1, 0, 0
+	for candidate := range c.pending {
+		holders := seeders
+		for _, have := range c.holders {
+			if have.Has(candidate) {
+				holders++
+			}
+		}
+		switch {
+		case holders == 0:
+			continue
+		case c.sequential:
+			if chunkID < 0 || candidate < chunkID {
+				chunkID = candidate
+			}
+		case chunkID < 0 || holders < rarest:
+			chunkID, rarest, ties = candidate, holders, 1
+		case holders == rarest:
+			// Keeps each of the equally rare chunks with the same chance.
+			ties++
+			if rand.Intn(ties) == 0 {
+				chunkID = candidate
+			}
+		}
+	}
+	return chunkID
+}
+
+// pickPeer picks the peer to request a chunk from among the seeders and the
+// partial holders that have it, leaving out those in exclude. Faster peers
+// are picked more often, in proportion to their weights, while slow ones
+// still get a share. chunkMutex must be held.
+func (c *ChunkCoordinator) pickPeer(chunkID int, exclude []string) (string, bool) {
+	var candidates []string
+	for peer, have := range c.holders {
+		if have.Has(chunkID) && !slices.Contains(exclude, peer) {
+			candidates = append(candidates, peer)
+		}
+	}
+	for _, seeder := range c.hashRing.Members() {
+		if !slices.Contains(exclude, seeder) {
+			candidates = append(candidates, seeder)
+		}
+	}
+	if len(candidates) == 0 {
+		return "", false
+	}
+
+	weights := c.weights(candidates)
+	var total float64
+	for _, weight := range weights {
+		total += weight
+	}
+	r := rand.Float64() * total
+	for i, weight := range weights {
+		if r < weight {
+			return candidates[i], true
+		}
+		r -= weight
+	}
+	return candidates[len(candidates)-1], true
+}
+
+// newTask records that addr is asked for a chunk. chunkMutex must be held.
+func (c *ChunkCoordinator) newTask(chunkID int, addr string, request *chunkRequest) DownloadTask {
+	request.peers = append(request.peers, addr)
+	return DownloadTask{
+		ChunkID:    chunkID,
+		ChunkName:  GetChunkName(c.metadata.FileName, chunkID),
+		ClientAddr: addr,
+		CheckSum:   c.metadata.ChunkChecksums[chunkID],
+		ctx:        request.ctx,
+	}
+}
+
+// release ends a request that did not deliver its chunk. The chunk waits
+// for a worker again unless other requests for it are still out.
+func (c *ChunkCoordinator) release(task DownloadTask) {
+	c.chunkMutex.Lock()
+	defer c.chunkMutex.Unlock()
+	request, exists := c.inFlight[task.ChunkID]
+	if !exists {
+		// Delivered by another request.
+		return
+	}
+	if i := slices.Index(request.peers, task.ClientAddr); i >= 0 {
+		request.peers = slices.Delete(request.peers, i, i+1)
+	}
+	if len(request.peers) == 0 {
+		request.cancel()
+		delete(c.inFlight, task.ChunkID)
+		c.pending[task.ChunkID] = true
+	}
+}
+
+// deliver stores a verified chunk, cancels the other requests for it and
+// caches it on disk before handing it to the stream writer. It reports
+// false when the chunk was delivered already.
+func (c *ChunkCoordinator) deliver(chunkID int, data []byte) bool {
+	c.chunkMutex.Lock()
+	if _, delivered := c.chunkData[chunkID]; delivered {
+		c.chunkMutex.Unlock()
+		return false
+	}
+	c.chunkData[chunkID] = data
+	delete(c.pending, chunkID)
+	if request, exists := c.inFlight[chunkID]; exists {
+		request.cancel()
+		delete(c.inFlight, chunkID)
+	}
+	c.chunkMutex.Unlock()
+
+	os.MkdirAll(CACHE_DIR, os.ModePerm)
+	chunkName := GetChunkName(c.metadata.FileName, chunkID)
+	if err := os.WriteFile(filepath.Join(CACHE_DIR, chunkName), data, 0644); err != nil {
+		log.Printf("Failed to write chunk %s: %v", chunkName, err)
+	}
+	c.chunkReady <- chunkID
+	return true
+}
+
+// SetSequential makes a running download fetch its chunks in order, so it
+// can be played while it downloads, or goes back to rarest first. It
+// reports whether fileName is being downloaded.
+func (p *PeerServer) SetSequential(fileName string, sequential bool) bool {
+	chunkCoordinator := p.activeDownload(fileName)
+	if chunkCoordinator == nil {
+		return false
+	}
+	chunkCoordinator.chunkMutex.Lock()
+	chunkCoordinator.sequential = sequential
+	chunkCoordinator.chunkMutex.Unlock()
+	if debug_mode {
+		log.Printf("Sequential download of %s: %v", fileName, sequential)
+	}
+	return true
+}
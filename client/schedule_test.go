@@ -0,0 +1,180 @@
+package client
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stathat/consistent"
+)
+
+// newTestCoordinator schedules numChunks chunks among seeders, which hold
+// every chunk, and partial holders, which hold the listed chunks.
+func newTestCoordinator(t *testing.T, numChunks int, seeders []string, holders map[string][]int) *ChunkCoordinator {
+	t.Helper()
+	ctx, fail := context.WithCancelCause(context.Background())
+	t.Cleanup(func() { fail(nil) })
+	c := &ChunkCoordinator{
+		chunkData:  make(map[int][]byte),
+		chunkReady: make(chan int, numChunks),
+		chunkMutex: &sync.Mutex{},
+		hashRing:   consistent.New(),
+		holders:    make(map[string]Bitfield),
+		numChunks:  numChunks,
+		metadata:   TorrentMetadata{FileName: "song.mp3", ChunkSize: ChunkSize, ChunkChecksums: make(map[int]string)},
+		pending:    make(map[int]bool),
+		inFlight:   make(map[int]*chunkRequest),
+		stats:      make(map[string]*peerStat),
+		dropped:    make(map[string]time.Time),
+		ctx:        ctx,
+		fail:       fail,
+	}
+	for _, seeder := range seeders {
+		c.hashRing.Add(seeder)
+	}
+	for peer, chunks := range holders {
+		have := newBitfield(numChunks)
+		for _, chunkID := range chunks {
+			have.Set(chunkID)
+		}
+		c.holders[peer] = have
+	}
+	c.queueMissing()
+	return c
+}
+
+func TestPickChunk(t *testing.T) {
+	tests := []struct {
+		name       string
+		seeders    []string
+		holders    map[string][]int
+		sequential bool
+		want       []int // any of these
+	}{
+		{"rarest first", nil, map[string][]int{"p1": {0, 1, 2, 3}, "p2": {0, 2, 3}, "p3": {0, 2, 3}}, false, []int{1}},
+		{"equally rare", nil, map[string][]int{"p1": {0, 1, 2, 3}, "p2": {1, 2}, "p3": {0, 3}}, false, []int{0, 1, 2, 3}},
+		{"seeders hold every chunk", []string{"s1"}, map[string][]int{"p1": {0, 1}, "p2": {1, 2}}, false, []int{3}},
+		{"only held chunks", nil, map[string][]int{"p1": {2, 3}, "p2": {2}}, false, []int{3}},
+		{"sequential", []string{"s1"}, map[string][]int{"p1": {1, 2}}, true, []int{0}},
+		{"sequential skips missing chunks", nil, map[string][]int{"p1": {2, 3}, "p2": {3}}, true, []int{2}},
+		{"nobody holds anything", nil, nil, false, []int{-1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newTestCoordinator(t, 4, tt.seeders, tt.holders)
+			c.sequential = tt.sequential
+			seen := make(map[int]bool)
+			for range 100 {
+				got := c.pickChunk()
+				if !slices.Contains(tt.want, got) {
+					t.Fatalf("pickChunk = %d, want one of %v", got, tt.want)
+				}
+				seen[got] = true
+			}
+			// Equally rare chunks are all picked now and then.
+			if len(seen) != len(tt.want) {
+				t.Errorf("picked %v over 100 runs, want each of %v", seen, tt.want)
+			}
+		})
+	}
+}
+
+func TestPickChunkSkipsFetched(t *testing.T) {
+	c := newTestCoordinator(t, 3, nil, map[string][]int{"p1": {0, 1, 2}, "p2": {1, 2}})
+	c.chunkData[0] = []byte("have it")
+	delete(c.pending, 0)
+	if got := c.pickChunk(); got != 1 && got != 2 {
+		t.Errorf("pickChunk = %d, want 1 or 2", got)
+	}
+}
+
+func TestNextTaskEndgame(t *testing.T) {
+	tests := []struct {
+		name    string
+		seeders []string
+		want    int // requests out for the last chunk
+	}{
+		{"one seeder", []string{"s1"}, 1},
+		{"two seeders", []string{"s1", "s2"}, 2},
+		{"more seeders than endgame requests", []string{"s1", "s2", "s3", "s4", "s5"}, endgameRequests},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newTestCoordinator(t, 1, tt.seeders, nil)
+			var asked []string
+			for {
+				task, ok := c.nextTask()
+				if !ok {
+					break
+				}
+				if task.ChunkID != 0 || slices.Contains(asked, task.ClientAddr) {
+					t.Fatalf("task %+v after asking %v", task, asked)
+				}
+				asked = append(asked, task.ClientAddr)
+			}
+			if len(asked) != tt.want {
+				t.Errorf("asked %v for the last chunk, want %d peers", asked, tt.want)
+			}
+		})
+	}
+}
+
+// Endgame goes to the chunk with the fewest requests out.
+func TestNextTaskEndgameSpreadsRequests(t *testing.T) {
+	c := newTestCoordinator(t, 2, []string{"s1", "s2", "s3"}, nil)
+	counts := make(map[int]int)
+	for range 4 {
+		task, ok := c.nextTask()
+		if !ok {
+			t.Fatal("ran out of tasks")
+		}
+		counts[task.ChunkID]++
+	}
+	if counts[0] != 2 || counts[1] != 2 {
+		t.Errorf("requests per chunk = %v, want 2 each", counts)
+	}
+}
+
+func TestReleaseAndDeliver(t *testing.T) {
+	inTempDir(t)
+	c := newTestCoordinator(t, 1, []string{"s1", "s2"}, nil)
+	first, _ := c.nextTask()
+	second, _ := c.nextTask()
+
+	// The chunk waits again only once every request for it failed.
+	c.release(first)
+	if c.pending[0] || first.ctx.Err() != nil {
+		t.Fatal("chunk requeued while another request is out")
+	}
+	c.release(second)
+	if !c.pending[0] || second.ctx.Err() == nil {
+		t.Fatal("chunk not requeued after its last request failed")
+	}
+
+	// A delivery cancels the other requests; later ones are refused.
+	first, _ = c.nextTask()
+	second, _ = c.nextTask()
+	if !c.deliver(0, []byte("chunk")) {
+		t.Fatal("first delivery refused")
+	}
+	if second.ctx.Err() == nil || len(c.inFlight) != 0 || c.pending[0] {
+		t.Fatal("requests for a delivered chunk still out")
+	}
+	if c.deliver(0, []byte("again")) {
+		t.Error("second delivery accepted")
+	}
+	c.release(first)
+	if c.pending[0] {
+		t.Error("released request requeued a delivered chunk")
+	}
+	if ready := <-c.chunkReady; ready != 0 || len(c.chunkReady) != 0 {
+		t.Errorf("chunk %d handed to the writer, %d more queued", ready, len(c.chunkReady))
+	}
+	if data, err := os.ReadFile(filepath.Join(CACHE_DIR, GetChunkName("song.mp3", 0))); err != nil || string(data) != "chunk" {
+		t.Errorf("cached chunk = %q, %v", data, err)
+	}
+}
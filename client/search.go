@@ -0,0 +1,253 @@
+package client
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"log"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	pb "napster"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	floodTTL        = 3                      // forwards of a search started here
+	floodFanout     = 8                      // neighbours each peer forwards to
+	floodTimeout    = 3 * time.Second        // longest a search started here waits
+	floodMargin     = 300 * time.Millisecond // left to merge before the sender gives up
+	floodMaxResults = 100
+	seenSearchTTL   = time.Minute
+)
+
+// FloodSearch asks the peers around this one for songs matching query,
+// without a central server. The query spreads floodTTL hops through the
+// neighbours of every peer reached; each answers from the files it seeds.
+func (p *PeerServer) FloodSearch(query string) []*pb.SongInfo {
+	if strings.TrimSpace(query) == "" {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), floodTimeout)
+	defer cancel()
+
+	queryID := newQueryID()
+	p.firstSearch(queryID)
+	return p.forwardSearch(ctx, &pb.PeerSearchRequest{
+		Query:   query,
+		QueryId: queryID,
+		Ttl:     floodTTL,
+		Sender:  p.PeerAddress,
+	})
+}
+
+// Search answers a flooded search from the files this peer seeds, adding
+// what its neighbours find while the TTL lasts. A query seen before gets no
+// results, so it is answered once however many paths reach this peer.
+func (p *PeerServer) Search(ctx context.Context, req *pb.PeerSearchRequest) (*pb.PeerSearchResponse, error) {
+	if req.QueryId == "" || !p.firstSearch(req.QueryId) {
+		return &pb.PeerSearchResponse{}, nil
+	}
+
+	results := p.localMatches(req.Query)
+	if req.Ttl > 0 {
+		results = mergeSongs(results, p.forwardSearch(ctx, &pb.PeerSearchRequest{
+			Query:   req.Query,
+			QueryId: req.QueryId,
+			Ttl:     min(req.Ttl, floodTTL) - 1,
+			Sender:  p.PeerAddress,
+		}, req.Sender))
+	}
+	return &pb.PeerSearchResponse{Results: results[:min(len(results), floodMaxResults)]}, nil
+}
+
+// forwardSearch sends a search to up to floodFanout neighbours at once and
+// merges the answers that arrive in time.
+func (p *PeerServer) forwardSearch(ctx context.Context, req *pb.PeerSearchRequest, exclude ...string) []*pb.SongInfo {
+	timeout := floodTimeout
+	if deadline, ok := ctx.Deadline(); ok {
+		timeout = min(timeout, time.Until(deadline)-floodMargin)
+	}
+	if timeout <= 0 {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	var (
+		mu      sync.Mutex
+		results []*pb.SongInfo
+		wg      sync.WaitGroup
+	)
+	for _, addr := range p.neighbours(append(exclude, p.PeerAddress)) {
+		wg.Add(1)
+		go func(addr string) {
+			defer wg.Done()
+			conn, err := grpc.NewClient(addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+			if err != nil {
+				return
+			}
+			defer conn.Close()
+			res, err := pb.NewPeerServiceClient(conn).Search(ctx, req)
+			if err != nil {
+				if debug_mode {
+					log.Printf("Search on peer %s failed: %v", addr, err)
+				}
+				if status.Code(err) == codes.Unavailable && p.DHT != nil {
+					p.DHT.forget(addr)
+				}
+				return
+			}
+			mu.Lock()
+			results = mergeSongs(results, res.Results)
+			mu.Unlock()
+		}(addr)
+	}
+	wg.Wait()
+	return results
+}
+
+// neighbours returns the peers a search is forwarded to: the nearest DHT
+// contacts, then the peers named in local torrents.
+func (p *PeerServer) neighbours(exclude []string) []string {
+	var candidates []string
+	if p.DHT != nil {
+		candidates = p.DHT.closest(p.DHT.id, floodFanout+len(exclude), "")
+	}
+	candidates = append(candidates, torrentPeers()...)
+
+	var addrs []string
+	for _, addr := range candidates {
+		if !slices.Contains(exclude, addr) && !slices.Contains(addrs, addr) {
+			addrs = append(addrs, addr)
+			if len(addrs) == floodFanout {
+				break
+			}
+		}
+	}
+	return addrs
+}
+
+// localMatches returns the seeded files whose name or artist contains every
+// word of query, each listing this peer as its seeder.
+func (p *PeerServer) localMatches(query string) []*pb.SongInfo {
+	words := strings.Fields(strings.ToLower(query))
+	if len(words) == 0 {
+		return nil
+	}
+
+	var results []*pb.SongInfo
+	for _, name := range seededFiles() {
+		metadata := ParseTorrent(torrentPath(name))
+		if metadata.FileName == "" {
+			continue
+		}
+		text := strings.ToLower(metadata.FileName + " " + metadata.ArtistName)
+		if !allContained(text, words) {
+			continue
+		}
+		results = append(results, &pb.SongInfo{
+			FileName:      metadata.FileName,
+			ArtistName:    metadata.ArtistName,
+			PeerAddresses: []string{p.PeerAddress},
+			CreatedAt:     metadata.CreatedAt,
+			Duration:      strconv.FormatInt(metadata.Duration, 10),
+			LiveSeeders:   1,
+			Available:     true,
+		})
+	}
+	return results
+}
+
+func allContained(text string, words []string) bool {
+	for _, word := range words {
+		if !strings.Contains(text, word) {
+			return false
+		}
+	}
+	return true
+}
+
+// firstSearch records a search ID and reports whether it had not been seen.
+func (p *PeerServer) firstSearch(queryID string) bool {
+	p.searchesMu.Lock()
+	defer p.searchesMu.Unlock()
+
+	if p.searches == nil {
+		p.searches = make(map[string]time.Time)
+	}
+	now := time.Now()
+	for id, at := range p.searches {
+		if now.Sub(at) > seenSearchTTL {
+			delete(p.searches, id)
+		}
+	}
+	if _, seen := p.searches[queryID]; seen {
+		return false
+	}
+	p.searches[queryID] = now
+	return true
+}
+
+// MergeSongs adds flooded search results to those of the central servers.
+// A song of this peer's own index found both ways keeps its place and gains
+// the seeders the flood found; songs only the flood found follow, most
+// seeded first.
+func (p *PeerServer) MergeSongs(indexed []*pb.SongInfo, flooded []*pb.SongInfo) []*pb.SongInfo {
+	byName := make(map[string]*pb.SongInfo)
+	for _, song := range indexed {
+		if p.ownIndex(song.Origin) {
+			byName[song.FileName] = song
+		}
+	}
+
+	var extra []*pb.SongInfo
+	for _, song := range flooded {
+		if known, exists := byName[song.FileName]; exists {
+			addSeeders(known, song.PeerAddresses)
+			continue
+		}
+		extra = append(extra, song)
+	}
+	slices.SortStableFunc(extra, func(a, b *pb.SongInfo) int {
+		return int(b.LiveSeeders - a.LiveSeeders)
+	})
+	return append(indexed, extra...)
+}
+
+// mergeSongs combines flooded search results, one entry per file name.
+func mergeSongs(results []*pb.SongInfo, more []*pb.SongInfo) []*pb.SongInfo {
+	for _, song := range more {
+		i := slices.IndexFunc(results, func(r *pb.SongInfo) bool { return r.FileName == song.FileName })
+		if i < 0 {
+			results = append(results, song)
+			continue
+		}
+		addSeeders(results[i], song.PeerAddresses)
+	}
+	return results
+}
+
+func addSeeders(song *pb.SongInfo, peers []string) {
+	for _, peer := range peers {
+		if !slices.Contains(song.PeerAddresses, peer) {
+			song.PeerAddresses = append(song.PeerAddresses, peer)
+		}
+	}
+	song.LiveSeeders = int32(len(song.PeerAddresses))
+	song.Available = song.Available || len(song.PeerAddresses) > 0
+}
+
+// newQueryID returns a random ID for a search started here.
+func newQueryID() string {
+	id := make([]byte, 16)
+	rand.Read(id)
+	return hex.EncodeToString(id)
+}
@@ -0,0 +1,243 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+
+	pb "napster"
+)
+
+// writeTestTorrent stores metadata as a local torrent.
+func writeTestTorrent(t *testing.T, metadata TorrentMetadata) {
+	t.Helper()
+	data, err := json.Marshal(metadata)
+	if err != nil {
+		t.Fatal(err)
+	}
+	os.MkdirAll(TORRENTS_DIR, os.ModePerm)
+	if err := os.WriteFile(torrentPath(metadata.FileName), data, 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// seedTestFile makes this peer seed a file, as a finished download does.
+func seedTestFile(t *testing.T, metadata TorrentMetadata) {
+	t.Helper()
+	writeTestTorrent(t, metadata)
+	os.MkdirAll(CHUNKS_DIR, os.ModePerm)
+	os.MkdirAll(DOWNLOAD_PATH, os.ModePerm)
+	os.WriteFile(filepath.Join(CHUNKS_DIR, GetChunkName(metadata.FileName, 0)), []byte("chunk"), 0644)
+	os.WriteFile(filepath.Join(DOWNLOAD_PATH, metadata.FileName), []byte("file"), 0644)
+}
+
+// searchPeer is a neighbour on 127.0.0.1 that records the searches it gets
+// and answers each with the same results.
+type searchPeer struct {
+	pb.UnimplementedPeerServiceServer
+	addr    string
+	results []*pb.SongInfo
+
+	mu       sync.Mutex
+	requests []*pb.PeerSearchRequest
+}
+
+func newSearchPeer(t *testing.T, results ...*pb.SongInfo) *searchPeer {
+	t.Helper()
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	peer := &searchPeer{addr: lis.Addr().String(), results: results}
+	server := grpc.NewServer()
+	pb.RegisterPeerServiceServer(server, peer)
+	go server.Serve(lis)
+	t.Cleanup(server.Stop)
+	return peer
+}
+
+func (p *searchPeer) Search(ctx context.Context, req *pb.PeerSearchRequest) (*pb.PeerSearchResponse, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.requests = append(p.requests, req)
+	return &pb.PeerSearchResponse{Results: p.results}, nil
+}
+
+func (p *searchPeer) received() []*pb.PeerSearchRequest {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return slices.Clone(p.requests)
+}
+
+func TestFirstSearch(t *testing.T) {
+	p := &PeerServer{}
+	if !p.firstSearch("q1") || p.firstSearch("q1") {
+		t.Fatal("search q1 not deduplicated")
+	}
+	if !p.firstSearch("q2") {
+		t.Fatal("search q2 taken for q1")
+	}
+
+	// IDs are forgotten after seenSearchTTL.
+	p.searches["q1"] = time.Now().Add(-seenSearchTTL - time.Second)
+	if !p.firstSearch("q1") {
+		t.Error("expired search ID still seen")
+	}
+	if len(p.searches) != 2 {
+		t.Errorf("searches = %v", p.searches)
+	}
+}
+
+func TestSearchForwarding(t *testing.T) {
+	inTempDir(t)
+	neighbour := newSearchPeer(t, &pb.SongInfo{FileName: "Yesterday.mp3", PeerAddresses: []string{"far:1"}, LiveSeeders: 1})
+	// The neighbour is known from a local torrent.
+	writeTestTorrent(t, TorrentMetadata{FileName: "other.mp3", Peers: []string{neighbour.addr}})
+	seedTestFile(t, TorrentMetadata{FileName: "Yesterday.mp3", ArtistName: "The Beatles"})
+	p := &PeerServer{PeerAddress: "self:1"}
+
+	tests := []struct {
+		name    string
+		ttl     int32
+		sender  string
+		forward int32 // TTL forwarded with, -1 if not forwarded
+	}{
+		{"last hop", 0, "sender:1", -1},
+		{"one hop left", 1, "sender:1", 0},
+		{"TTL capped", 10, "sender:1", floodTTL - 1},
+		{"not back to the sender", 2, neighbour.addr, -1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			before := len(neighbour.received())
+			queryID := newQueryID()
+			req := &pb.PeerSearchRequest{Query: "yesterday", QueryId: queryID, Ttl: tt.ttl, Sender: tt.sender}
+			res, err := p.Search(context.Background(), req)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got := neighbour.received()[before:]
+			if tt.forward < 0 {
+				if len(got) != 0 {
+					t.Fatalf("forwarded %v", got)
+				}
+				if len(res.Results) != 1 || !slices.Equal(res.Results[0].PeerAddresses, []string{"self:1"}) {
+					t.Errorf("results = %v, want the local match only", res.Results)
+				}
+				return
+			}
+			if len(got) != 1 || got[0].Ttl != tt.forward || got[0].QueryId != queryID || got[0].Sender != "self:1" {
+				t.Fatalf("forwarded %v, want TTL %d", got, tt.forward)
+			}
+			if len(res.Results) != 1 || !slices.Equal(res.Results[0].PeerAddresses, []string{"self:1", "far:1"}) {
+				t.Errorf("results = %v, want the local match seeded by both peers", res.Results)
+			}
+
+			// The same search arriving along another path gets no answer.
+			again, _ := p.Search(context.Background(), req)
+			if len(again.Results) != 0 || len(neighbour.received()) != before+1 {
+				t.Errorf("seen search answered with %v", again.Results)
+			}
+		})
+	}
+}
+
+func TestLocalMatches(t *testing.T) {
+	inTempDir(t)
+	seedTestFile(t, TorrentMetadata{FileName: "Yesterday.mp3", ArtistName: "The Beatles", Duration: 125})
+	seedTestFile(t, TorrentMetadata{FileName: "Let It Be.mp3", ArtistName: "The Beatles"})
+	seedTestFile(t, TorrentMetadata{FileName: "Help.mp3", ArtistName: "The Beatles"})
+	changeTorrentStatus("Help.mp3", "Downloaded") // no longer seeded
+	t.Cleanup(func() { changeTorrentStatus("Help.mp3", "") })
+	p := &PeerServer{PeerAddress: "self:1"}
+
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{"beatles", []string{"Let It Be.mp3", "Yesterday.mp3"}},
+		{"BEATLES yester", []string{"Yesterday.mp3"}},
+		{"beatles cohen", nil},
+		{"help", nil},
+		{"  ", nil},
+	}
+	for _, tt := range tests {
+		var got []string
+		for _, song := range p.localMatches(tt.query) {
+			got = append(got, song.FileName)
+			if !slices.Equal(song.PeerAddresses, []string{"self:1"}) || song.LiveSeeders != 1 || !song.Available {
+				t.Errorf("%q: match %v", tt.query, song)
+			}
+		}
+		slices.Sort(got)
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("localMatches(%q) = %v, want %v", tt.query, got, tt.want)
+		}
+	}
+	if songs := p.localMatches("yesterday"); len(songs) != 1 || songs[0].Duration != "125" || songs[0].ArtistName != "The Beatles" {
+		t.Errorf("match = %v", songs)
+	}
+}
+
+func TestMergeFloodedSongs(t *testing.T) {
+	results := mergeSongs(nil, []*pb.SongInfo{
+		{FileName: "a.mp3", PeerAddresses: []string{"p1"}, LiveSeeders: 1},
+		{FileName: "b.mp3", PeerAddresses: []string{"p2"}, LiveSeeders: 1},
+	})
+	results = mergeSongs(results, []*pb.SongInfo{
+		{FileName: "a.mp3", PeerAddresses: []string{"p1", "p3"}, LiveSeeders: 2},
+		{FileName: "c.mp3", PeerAddresses: []string{"p4"}, LiveSeeders: 1},
+	})
+
+	want := map[string][]string{"a.mp3": {"p1", "p3"}, "b.mp3": {"p2"}, "c.mp3": {"p4"}}
+	if len(results) != len(want) {
+		t.Fatalf("merged into %d songs, want %d", len(results), len(want))
+	}
+	for _, song := range results {
+		if !slices.Equal(song.PeerAddresses, want[song.FileName]) || int(song.LiveSeeders) != len(want[song.FileName]) {
+			t.Errorf("%s seeded by %v (%d live), want %v", song.FileName, song.PeerAddresses, song.LiveSeeders, want[song.FileName])
+		}
+	}
+}
+
+func TestMergeSongs(t *testing.T) {
+	p := &PeerServer{Client: newFakeFailover(&fakeCentralServer{name: "own:1"})}
+	indexed := []*pb.SongInfo{
+		{FileName: "a.mp3", PeerAddresses: []string{"p1"}, LiveSeeders: 1, Available: true},
+		{FileName: "b.mp3", Available: false},
+		{FileName: "c.mp3", Origin: "other:1", PeerAddresses: []string{"p5"}, LiveSeeders: 1},
+	}
+	flooded := []*pb.SongInfo{
+		{FileName: "b.mp3", PeerAddresses: []string{"p2"}, LiveSeeders: 1},
+		{FileName: "c.mp3", PeerAddresses: []string{"p6"}, LiveSeeders: 1},
+		{FileName: "d.mp3", PeerAddresses: []string{"p7", "p8"}, LiveSeeders: 2},
+	}
+	merged := p.MergeSongs(indexed, flooded)
+
+	// Indexed songs keep their place; only those of this peer's own index
+	// gain the flooded seeders. Songs only the flood found follow, most
+	// seeded first.
+	var got []string
+	for _, song := range merged {
+		got = append(got, song.Origin+"/"+song.FileName)
+	}
+	want := []string{"/a.mp3", "/b.mp3", "other:1/c.mp3", "/d.mp3", "/c.mp3"}
+	if !slices.Equal(got, want) {
+		t.Fatalf("merged = %v, want %v", got, want)
+	}
+	if b := merged[1]; !slices.Equal(b.PeerAddresses, []string{"p2"}) || b.LiveSeeders != 1 || !b.Available {
+		t.Errorf("b.mp3 = %v, want it seeded by p2", b)
+	}
+	if c := merged[2]; !slices.Equal(c.PeerAddresses, []string{"p5"}) {
+		t.Errorf("federated c.mp3 gained seeders: %v", c.PeerAddresses)
+	}
+}
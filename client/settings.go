@@ -0,0 +1,90 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+)
+
+// Settings are the peer's user preferences, stored as JSON next to the app.
+type Settings struct {
+	ServerAddresses  []string `json:"server_addresses"`  // central servers, tried in order
+	DownloadDir      string   `json:"download_dir"`      // downloaded songs, torrents and cache
+	MaxThreads       int      `json:"max_threads"`       // parallel chunk downloads
+	Contributor      bool     `json:"contributor"`       // store replicas for the swarm
+	AdvertiseAddress string   `json:"advertise_address"` // address other peers and the servers reach this peer at
+	DHTBootstrap     []string `json:"dht_bootstrap"`     // peers to join the DHT through, besides those in local torrents
+	UploadLimit      int64    `json:"upload_limit"`      // bytes per second sent to other peers, 0 for no limit
+	DownloadLimit    int64    `json:"download_limit"`    // bytes per second received from other peers, 0 for no limit
+}
+
+// LoadSettings reads the settings stored at path. Fields missing from the
+// file, or the whole file if it does not exist yet, take their defaults.
+func LoadSettings(path string, defaults Settings) (Settings, error) {
+	settings := defaults
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return settings, nil
+	}
+	if err != nil {
+		return settings, err
+	}
+	if err := json.Unmarshal(data, &settings); err != nil {
+		return defaults, fmt.Errorf("corrupt settings %s: %v", path, err)
+	}
+	return settings, settings.Validate()
+}
+
+// SaveSettings replaces the settings stored at path.
+func SaveSettings(path string, settings Settings) error {
+	data, err := json.MarshalIndent(settings, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// Validate checks that the settings can be applied.
+func (s Settings) Validate() error {
+	if len(s.ServerAddresses) == 0 {
+		return errors.New("at least one central server address is required")
+	}
+	for _, addr := range s.ServerAddresses {
+		if _, _, err := net.SplitHostPort(addr); err != nil {
+			return fmt.Errorf("invalid server address %q: %v", addr, err)
+		}
+	}
+	if s.DownloadDir == "" {
+		return errors.New("download directory is required")
+	}
+	if s.MaxThreads < 1 || s.MaxThreads > 64 {
+		return fmt.Errorf("max threads must be between 1 and 64, got %d", s.MaxThreads)
+	}
+	if _, _, err := net.SplitHostPort(s.AdvertiseAddress); err != nil {
+		return fmt.Errorf("invalid advertised address %q: %v", s.AdvertiseAddress, err)
+	}
+	for _, addr := range s.DHTBootstrap {
+		if _, _, err := net.SplitHostPort(addr); err != nil {
+			return fmt.Errorf("invalid DHT bootstrap address %q: %v", addr, err)
+		}
+	}
+	if s.UploadLimit < 0 || s.DownloadLimit < 0 {
+		return errors.New("bandwidth limits cannot be negative")
+	}
+	return nil
+}
+
+// UseDownloadDir points DOWNLOAD_PATH, TORRENTS_DIR and CACHE_DIR at dir.
+// Call it before any download starts.
+func UseDownloadDir(dir string) {
+	DOWNLOAD_PATH = dir
+	TORRENTS_DIR = filepath.Join(dir, "torrents")
+	CACHE_DIR = filepath.Join(dir, "cache")
+}
@@ -0,0 +1,110 @@
+package client
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func validSettings() Settings {
+	return Settings{
+		ServerAddresses:  []string{"localhost:50051", "10.0.0.2:50051"},
+		DownloadDir:      "./downloads",
+		MaxThreads:       4,
+		AdvertiseAddress: "localhost:5003",
+	}
+}
+
+func TestSettingsValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		change func(s *Settings)
+		errs   string // part of the error, "" for valid settings
+	}{
+		{"valid", func(s *Settings) {}, ""},
+		{"limits and bootstrap peers", func(s *Settings) {
+			s.UploadLimit, s.DownloadLimit, s.DHTBootstrap = 1024, 2048, []string{"10.0.0.3:5003"}
+		}, ""},
+		{"no servers", func(s *Settings) { s.ServerAddresses = nil }, "central server"},
+		{"server without port", func(s *Settings) { s.ServerAddresses = []string{"localhost"} }, "server address"},
+		{"no download directory", func(s *Settings) { s.DownloadDir = "" }, "download directory"},
+		{"no threads", func(s *Settings) { s.MaxThreads = 0 }, "max threads"},
+		{"too many threads", func(s *Settings) { s.MaxThreads = 65 }, "max threads"},
+		{"advertised address without port", func(s *Settings) { s.AdvertiseAddress = "localhost" }, "advertised address"},
+		{"bad bootstrap peer", func(s *Settings) { s.DHTBootstrap = []string{"peer"} }, "bootstrap"},
+		{"negative limit", func(s *Settings) { s.DownloadLimit = -1 }, "negative"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			settings := validSettings()
+			tt.change(&settings)
+			err := settings.Validate()
+			if tt.errs == "" && err != nil {
+				t.Errorf("Validate = %v, want nil", err)
+			}
+			if tt.errs != "" && (err == nil || !strings.Contains(err.Error(), tt.errs)) {
+				t.Errorf("Validate = %v, want an error about %s", err, tt.errs)
+			}
+		})
+	}
+}
+
+func TestLoadSettings(t *testing.T) {
+	defaults := validSettings()
+	tests := []struct {
+		name    string
+		content string // "" for no file
+		want    func(s *Settings)
+		wantErr bool
+	}{
+		{"no file", "", func(s *Settings) {}, false},
+		{"missing fields keep defaults", `{"max_threads": 8, "contributor": true}`, func(s *Settings) {
+			s.MaxThreads, s.Contributor = 8, true
+		}, false},
+		{"corrupt", `{"max_threads": `, func(s *Settings) {}, true},
+		{"invalid", `{"max_threads": 0}`, func(s *Settings) { s.MaxThreads = 0 }, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "settings.json")
+			if tt.content != "" {
+				if err := os.WriteFile(path, []byte(tt.content), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			got, err := LoadSettings(path, defaults)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("LoadSettings error = %v, want error %v", err, tt.wantErr)
+			}
+			want := validSettings()
+			tt.want(&want)
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("LoadSettings = %+v, want %+v", got, want)
+			}
+		})
+	}
+}
+
+func TestSaveSettings(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "settings.json")
+	first := validSettings()
+	second := validSettings()
+	second.DHTBootstrap = []string{"10.0.0.3:5003"}
+	second.UploadLimit = 4096
+
+	for _, settings := range []Settings{first, second} {
+		if err := SaveSettings(path, settings); err != nil {
+			t.Fatal(err)
+		}
+		loaded, err := LoadSettings(path, Settings{})
+		if err != nil || !reflect.DeepEqual(loaded, settings) {
+			t.Errorf("loaded %+v, %v, want the saved %+v", loaded, err, settings)
+		}
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 1 {
+		t.Errorf("directory holds %d files, want only the settings", len(entries))
+	}
+}
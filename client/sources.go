@@ -0,0 +1,89 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"slices"
+	"time"
+
+	pb "napster"
+)
+
+// Variables so that tests can shorten them; set before a download starts.
+var (
+	sourceRefreshInterval = 20 * time.Second // between torrent refreshes during a download
+	peerCooldown          = 30 * time.Second // before a failed seeder is tried again
+	sourceTimeout         = 2 * time.Minute  // a download without any seeder this long fails
+	downloadStartDelay    = 5 * time.Second  // before a download starts fetching
+)
+
+const sourceWait = time.Second // a worker's pause while it has no chunk to fetch
+
+// errNoSource ends a download no seeder is left for.
+var errNoSource = fmt.Errorf("no seeder left")
+
+// refreshSources keeps a download supplied with seeders until its context
+// ends. Every sourceRefreshInterval it fetches the torrent again, adding the
+// seeders the server or the DHT know of and the downloaders announced in the
+// DHT that hold chunks, and takes back the dropped peers that answer again
+// after peerCooldown. A download left without any peer
+// to ask for sourceTimeout is failed with errNoSource.
+func (p *PeerServer) refreshSources(metadata TorrentMetadata, chunkCoordinator *ChunkCoordinator, indexingClient pb.CentralServerClient) {
+	ticker := time.NewTicker(sourceRefreshInterval)
+	defer ticker.Stop()
+	lastSource := time.Now()
+	for {
+		select {
+		case <-chunkCoordinator.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		for _, peer := range p.currentSeeders(metadata, indexingClient) {
+			if peer != p.PeerAddress && chunkCoordinator.admit(peer) && debug_mode {
+				log.Printf("Torrent refresh: %s now seeds %s", peer, metadata.FileName)
+			}
+		}
+		ctx, cancel := context.WithTimeout(chunkCoordinator.ctx, dhtLookupTimeout)
+		p.exchangeBitfields(ctx, metadata, chunkCoordinator, p.holdersOf(ctx, metadata))
+		cancel()
+		for _, peer := range chunkCoordinator.cooledDown() {
+			if have, err := p.queryBitfield(chunkCoordinator.ctx, metadata, chunkCoordinator, peer); err == nil {
+				chunkCoordinator.readmit(peer, have)
+				log.Printf("Peer %s of %s is back", peer, metadata.FileName)
+			} else {
+				chunkCoordinator.drop(peer)
+			}
+		}
+
+		if len(chunkCoordinator.sources()) > 0 {
+			lastSource = time.Now()
+		} else if time.Since(lastSource) >= sourceTimeout {
+			chunkCoordinator.fail(fmt.Errorf("%w for %s after %v", errNoSource, metadata.FileName, sourceTimeout))
+			return
+		}
+	}
+}
+
+// currentSeeders lists the seeders of a file known now: those in the
+// torrent as the server has it and those announced in the DHT.
+func (p *PeerServer) currentSeeders(metadata TorrentMetadata, indexingClient pb.CentralServerClient) []string {
+	ctx, cancel := context.WithTimeout(context.Background(), dhtLookupTimeout)
+	defer cancel()
+
+	var seeders []string
+	torrent, err := fetchTorrent(indexingClient, metadata.FileName)
+	if err == nil && torrent.Checksum == metadata.Checksum {
+		seeders = append(seeders, torrent.Peers...)
+	} else if err != nil && debug_mode {
+		log.Printf("Torrent refresh of %s failed: %v", metadata.FileName, err)
+	}
+	if p.DHT != nil {
+		for _, seeder := range p.DHT.Get(ctx, dhtSeedersKey(metadata.Checksum)) {
+			seeders = append(seeders, string(seeder))
+		}
+	}
+	slices.Sort(seeders)
+	return slices.Compact(seeders)
+}
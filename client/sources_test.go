@@ -0,0 +1,172 @@
+package client
+
+import (
+	"context"
+	"net"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "napster"
+)
+
+// shortenTimeouts runs downloads of this test on a shorter clock.
+func shortenTimeouts(t *testing.T, cooldown, timeout time.Duration) {
+	t.Helper()
+	oldRefresh, oldCooldown, oldTimeout, oldDelay := sourceRefreshInterval, peerCooldown, sourceTimeout, downloadStartDelay
+	sourceRefreshInterval, peerCooldown, sourceTimeout, downloadStartDelay = 20*time.Millisecond, cooldown, timeout, 0
+	t.Cleanup(func() {
+		sourceRefreshInterval, peerCooldown, sourceTimeout, downloadStartDelay = oldRefresh, oldCooldown, oldTimeout, oldDelay
+	})
+}
+
+// noTorrentServer is a central server that cannot be reached.
+type noTorrentServer struct {
+	pb.CentralServerClient
+}
+
+func (noTorrentServer) GetTorrent(ctx context.Context, in *pb.SearchRequest, opts ...grpc.CallOption) (*pb.TorrentResponse, error) {
+	return nil, status.Error(codes.Unavailable, "down")
+}
+
+func (noTorrentServer) EnableSeeding(ctx context.Context, in *pb.SeedingRequest, opts ...grpc.CallOption) (*pb.GenResponse, error) {
+	return nil, status.Error(codes.Unavailable, "down")
+}
+
+// bitfieldPeer is a seeder on 127.0.0.1 that answers bitfield queries
+// unless down.
+type bitfieldPeer struct {
+	pb.UnimplementedPeerServiceServer
+	addr string
+	down atomic.Bool
+}
+
+func newBitfieldPeer(t *testing.T) *bitfieldPeer {
+	t.Helper()
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	peer := &bitfieldPeer{addr: lis.Addr().String()}
+	server := grpc.NewServer()
+	pb.RegisterPeerServiceServer(server, peer)
+	go server.Serve(lis)
+	t.Cleanup(server.Stop)
+	return peer
+}
+
+func (p *bitfieldPeer) GetBitfield(ctx context.Context, req *pb.BitfieldRequest) (*pb.BitfieldResponse, error) {
+	if p.down.Load() {
+		return nil, status.Error(codes.Unavailable, "down")
+	}
+	return &pb.BitfieldResponse{Status: 200, Seeding: true}, nil
+}
+
+// closedAddr returns an address nothing listens on.
+func closedAddr(t *testing.T) string {
+	t.Helper()
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := lis.Addr().String()
+	lis.Close()
+	return addr
+}
+
+func TestDownloadFailsWithoutSources(t *testing.T) {
+	inTempDir(t)
+	shortenTimeouts(t, time.Hour, 200*time.Millisecond)
+	metadata := testTorrent("song.mp3", []byte(strings.Repeat("x", 2*ChunkSize)))
+	metadata.Peers = []string{closedAddr(t)}
+	writeTestTorrent(t, metadata)
+	t.Cleanup(func() { changeTorrentStatus(metadata.FileName, "") })
+
+	events := make(chan DownloadStatus, 100)
+	p := &PeerServer{PeerAddress: "self:1", EventEmitter: func(eventName string, event any) {
+		if status, ok := event.(DownloadStatus); ok {
+			events <- status
+		}
+	}}
+	start := time.Now()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		p.StartDownload(metadata, noTorrentServer{}, p.PeerAddress)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(10 * time.Second):
+		t.Fatal("download without seeders still running")
+	}
+	elapsed := time.Since(start)
+	close(events)
+	var last DownloadStatus
+	for event := range events {
+		last = event
+	}
+	if last.Status != "Failed" || !strings.Contains(last.Error, errNoSource.Error()) {
+		t.Fatalf("last event %+v, want the download failed for want of seeders", last)
+	}
+	if elapsed < sourceTimeout {
+		t.Errorf("failed after %v, before the source timeout of %v", elapsed, sourceTimeout)
+	}
+	if got := getTorrentStatus(metadata.FileName); got != "Failed" {
+		t.Errorf("torrent status %q, want Failed", got)
+	}
+}
+
+func TestDroppedPeerReadmitted(t *testing.T) {
+	shortenTimeouts(t, 200*time.Millisecond, time.Hour)
+	peer := newBitfieldPeer(t)
+	peer.down.Store(true)
+	c := newTestCoordinator(t, 4, []string{peer.addr}, nil)
+	p := &PeerServer{PeerAddress: "self:1"}
+
+	c.drop(peer.addr)
+	dropped := time.Now()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		p.refreshSources(c.metadata, c, noTorrentServer{})
+	}()
+	t.Cleanup(func() {
+		c.fail(nil)
+		<-done
+	})
+
+	// Still down once cooled down: set aside for another cooldown.
+	time.Sleep(2 * peerCooldown)
+	if sources := c.sources(); len(sources) != 0 {
+		t.Fatalf("sources = %v, want the failing peer kept out", sources)
+	}
+
+	peer.down.Store(false)
+	recovered := time.Now()
+	deadline := time.Now().Add(5 * time.Second)
+	for !slices.Contains(c.sources(), peer.addr) {
+		if time.Now().After(deadline) {
+			t.Fatal("recovered peer not readmitted")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if time.Since(dropped) < peerCooldown {
+		t.Errorf("readmitted %v after the drop, before the cooldown of %v", time.Since(dropped), peerCooldown)
+	}
+	if waited := time.Since(recovered); waited > peerCooldown+time.Second {
+		t.Errorf("readmitted %v after recovering", waited)
+	}
+	c.chunkMutex.Lock()
+	_, still := c.dropped[peer.addr]
+	c.chunkMutex.Unlock()
+	if still {
+		t.Error("readmitted peer still listed as dropped")
+	}
+}
@@ -0,0 +1,177 @@
+package client
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// tokenBucket limits a byte rate. Tokens accrue at rate per second up to a
+// second's worth; taking more than there are puts the bucket in debt, which
+// the taker waits out, so chunks larger than the burst still pass at the set
+// rate, and bytes can be charged once they have been counted. A zero rate
+// means no limit.
+type tokenBucket struct {
+	mu     sync.Mutex
+	rate   float64 // bytes per second
+	tokens float64
+	last   time.Time
+}
+
+func newTokenBucket(rate int64) *tokenBucket {
+	b := &tokenBucket{}
+	b.setRate(rate)
+	return b
+}
+
+func (b *tokenBucket) setRate(rate int64) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.rate = float64(max(rate, 0))
+	b.tokens = b.rate
+	b.last = time.Now()
+}
+
+// take charges n bytes to the bucket and returns how long the taker has to
+// wait before the rate allows them.
+func (b *tokenBucket) take(n int) time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.rate == 0 {
+		return 0
+	}
+	now := time.Now()
+	b.tokens = min(b.rate, b.tokens+now.Sub(b.last).Seconds()*b.rate)
+	b.last = now
+	b.tokens -= float64(n)
+	return time.Duration(-b.tokens / b.rate * float64(time.Second))
+}
+
+// refund gives back n bytes charged but not transferred.
+func (b *tokenBucket) refund(n int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.tokens = min(b.rate, b.tokens+float64(n))
+}
+
+// sleep waits for delay or until ctx ends.
+func sleep(ctx context.Context, delay time.Duration) error {
+	if delay <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// bandwidthLimit is a pair of upload and download buckets; nil buckets
+// leave the traffic unlimited.
+type bandwidthLimit struct {
+	upload   *tokenBucket
+	download *tokenBucket
+}
+
+func newBandwidthLimit(upload, download int64) bandwidthLimit {
+	return bandwidthLimit{upload: newTokenBucket(upload), download: newTokenBucket(download)}
+}
+
+// bandwidth holds a peer's limits: one pair for all traffic, and one per
+// file given limits of its own.
+type bandwidth struct {
+	mu    sync.Mutex
+	all   bandwidthLimit
+	files map[string]bandwidthLimit
+}
+
+// SetBandwidthLimits limits the bytes per second this peer uploads to and
+// downloads from other peers, over all files; 0 lifts a limit. It applies
+// to transfers already running.
+func (p *PeerServer) SetBandwidthLimits(upload, download int64) {
+	p.bandwidth.mu.Lock()
+	defer p.bandwidth.mu.Unlock()
+	if p.bandwidth.all.upload == nil {
+		p.bandwidth.all = newBandwidthLimit(upload, download)
+	} else {
+		p.bandwidth.all.upload.setRate(upload)
+		p.bandwidth.all.download.setRate(download)
+	}
+	log.Printf("Bandwidth limits: upload %d B/s, download %d B/s (0 is unlimited)", upload, download)
+}
+
+// SetFileBandwidthLimits limits the bytes per second of one file uploaded
+// and downloaded, within the overall limits; 0 lifts a limit.
+func (p *PeerServer) SetFileBandwidthLimits(fileName string, upload, download int64) {
+	p.bandwidth.mu.Lock()
+	defer p.bandwidth.mu.Unlock()
+	if upload <= 0 && download <= 0 {
+		delete(p.bandwidth.files, fileName)
+		return
+	}
+	if p.bandwidth.files == nil {
+		p.bandwidth.files = make(map[string]bandwidthLimit)
+	}
+	if limit, exists := p.bandwidth.files[fileName]; exists {
+		limit.upload.setRate(upload)
+		limit.download.setRate(download)
+	} else {
+		p.bandwidth.files[fileName] = newBandwidthLimit(upload, download)
+	}
+	log.Printf("Bandwidth limits of %s: upload %d B/s, download %d B/s (0 is unlimited)", fileName, upload, download)
+}
+
+// throttleUpload waits until n bytes of fileName may be sent, within the
+// file's limit and the overall one. A wait that would outlast the
+// requester's deadline is refused with ResourceExhausted and the bytes given
+// back, so the requester turns to another peer rather than timing out.
+func (p *PeerServer) throttleUpload(ctx context.Context, fileName string, n int) error {
+	all, file := p.limits(fileName)
+	delay := chargeBuckets(n, file.upload, all.upload)
+	if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < delay {
+		for _, bucket := range []*tokenBucket{file.upload, all.upload} {
+			if bucket != nil {
+				bucket.refund(n)
+			}
+		}
+		return status.Errorf(codes.ResourceExhausted, "upload limit reached: %s would wait %v", fileName, delay.Round(time.Millisecond))
+	}
+	return sleep(ctx, delay)
+}
+
+// throttleDownload charges n received bytes of fileName, waiting until the
+// file's limit and the overall one allow them.
+func (p *PeerServer) throttleDownload(ctx context.Context, fileName string, n int) error {
+	all, file := p.limits(fileName)
+	return waitBuckets(ctx, n, file.download, all.download)
+}
+
+func (p *PeerServer) limits(fileName string) (all bandwidthLimit, file bandwidthLimit) {
+	p.bandwidth.mu.Lock()
+	defer p.bandwidth.mu.Unlock()
+	return p.bandwidth.all, p.bandwidth.files[fileName]
+}
+
+// waitBuckets charges n bytes to every bucket, then waits for the slowest.
+func waitBuckets(ctx context.Context, n int, buckets ...*tokenBucket) error {
+	return sleep(ctx, chargeBuckets(n, buckets...))
+}
+
+// chargeBuckets charges n bytes to every bucket and returns the wait of the
+// slowest.
+func chargeBuckets(n int, buckets ...*tokenBucket) time.Duration {
+	var delay time.Duration
+	for _, bucket := range buckets {
+		if bucket != nil {
+			delay = max(delay, bucket.take(n))
+		}
+	}
+	return delay
+}
@@ -0,0 +1,186 @@
+package client
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestTokenBucketTake(t *testing.T) {
+	tests := []struct {
+		name  string
+		rate  int64
+		takes []int
+		want  time.Duration // delay of the last take, give or take 10ms
+	}{
+		{"unlimited", 0, []int{1 << 30}, 0},
+		{"within burst", 1000, []int{400, 600}, 0},
+		{"beyond burst", 1000, []int{1500}, 500 * time.Millisecond},
+		{"debt adds up", 1000, []int{1000, 500, 500}, time.Second},
+		{"larger than burst", 1000, []int{3000}, 2 * time.Second},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := newTokenBucket(tt.rate)
+			var delay time.Duration
+			for _, n := range tt.takes {
+				delay = b.take(n)
+			}
+			if diff := delay - tt.want; diff > 10*time.Millisecond || diff < -10*time.Millisecond {
+				t.Errorf("delay = %v, want %v", delay, tt.want)
+			}
+		})
+	}
+}
+
+func TestTokenBucketSetRate(t *testing.T) {
+	b := newTokenBucket(1000)
+	b.take(5000)
+	b.setRate(10000)
+	if delay := b.take(10000); delay > 10*time.Millisecond {
+		t.Errorf("debt kept across a new rate: delay %v", delay)
+	}
+	b.setRate(0)
+	if delay := b.take(1 << 30); delay != 0 {
+		t.Errorf("lifted limit still delays by %v", delay)
+	}
+}
+
+// Workers charging what they receive share the rate of the bucket.
+func TestWaitBucketsHoldsRate(t *testing.T) {
+	const rate, chunk, workers, chunks = 1 << 20, 1 << 16, 4, 6 // 1.5MB at 1MB/s
+	b := newTokenBucket(rate)
+	start := time.Now()
+	var wg sync.WaitGroup
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range chunks {
+				waitBuckets(context.Background(), chunk, b)
+			}
+		}()
+	}
+	wg.Wait()
+
+	// The first second's worth passes at once; the rest at the rate.
+	want := time.Duration(float64(workers*chunks*chunk-rate) / rate * float64(time.Second))
+	if elapsed := time.Since(start); elapsed < want-50*time.Millisecond || elapsed > want+300*time.Millisecond {
+		t.Errorf("took %v, want about %v", elapsed, want)
+	}
+}
+
+func TestWaitBucketsCancelled(t *testing.T) {
+	b := newTokenBucket(1000)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := waitBuckets(ctx, 5000, b); err == nil {
+		t.Fatal("cancelled wait returned no error")
+	}
+	// The bytes stay charged.
+	if delay := b.take(0); delay < 3900*time.Millisecond {
+		t.Errorf("charge lost on cancel: delay %v", delay)
+	}
+}
+
+func TestBandwidthLimits(t *testing.T) {
+	p := &PeerServer{}
+	p.SetBandwidthLimits(1000, 0)
+	p.SetFileBandwidthLimits("slow.mp3", 100, 2000)
+
+	all, file := p.limits("slow.mp3")
+	if all.upload.rate != 1000 || all.download.rate != 0 || file.upload.rate != 100 || file.download.rate != 2000 {
+		t.Fatalf("limits = %+v %+v", all, file)
+	}
+	if _, other := p.limits("other.mp3"); other.upload != nil {
+		t.Errorf("other file has limits: %+v", other)
+	}
+
+	// A transfer is charged to the file's and the overall limit.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	p.throttleUpload(ctx, "slow.mp3", 300)
+	if delay := file.upload.take(0); delay < 1900*time.Millisecond {
+		t.Errorf("file limit not charged: delay %v", delay)
+	}
+	if delay := all.upload.take(700); delay > 10*time.Millisecond {
+		t.Errorf("overall limit charged wrongly: delay %v", delay)
+	} else if delay := all.upload.take(100); delay < 90*time.Millisecond {
+		t.Errorf("overall limit not charged: delay %v", delay)
+	}
+	start := time.Now()
+	p.throttleDownload(context.Background(), "slow.mp3", 1500)
+	if elapsed := time.Since(start); elapsed > 100*time.Millisecond {
+		t.Errorf("download within the file's burst waited %v", elapsed)
+	}
+
+	p.SetFileBandwidthLimits("slow.mp3", 0, 0)
+	if _, file := p.limits("slow.mp3"); file.upload != nil {
+		t.Errorf("limits of slow.mp3 not lifted")
+	}
+}
+
+func TestThrottleUploadDeadline(t *testing.T) {
+	p := &PeerServer{}
+	p.SetBandwidthLimits(1000, 0)
+	p.throttleUpload(context.Background(), "song.mp3", 1000) // the burst
+
+	// A second's wait does not fit in 100ms: refused at once, nothing charged.
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	err := p.throttleUpload(ctx, "song.mp3", 1000)
+	if status.Code(err) != codes.ResourceExhausted {
+		t.Fatalf("throttleUpload = %v, want ResourceExhausted", err)
+	}
+	if elapsed := time.Since(start); elapsed > 50*time.Millisecond {
+		t.Errorf("refusal took %v", elapsed)
+	}
+	all, _ := p.limits("song.mp3")
+	if delay := all.upload.take(0); delay > 10*time.Millisecond {
+		t.Errorf("refused bytes still charged: delay %v", delay)
+	}
+
+	// A wait within the deadline is taken.
+	ctx, cancel = context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	start = time.Now()
+	if err := p.throttleUpload(ctx, "song.mp3", 100); err != nil {
+		t.Fatalf("throttleUpload = %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < 50*time.Millisecond {
+		t.Errorf("waited %v, want about 100ms", elapsed)
+	}
+}
+
+func TestDownloadWorkerReleasesOnLimit(t *testing.T) {
+	data := []byte(strings.Repeat("x", 2*ChunkSize))
+	metadata := testTorrent("song.mp3", data)
+	seeder := newChunkPeer(t, metadata, data)
+	c := newTestCoordinator(t, 2, []string{seeder.addr}, nil)
+	c.metadata = metadata
+	c.limit = func(ctx context.Context, n int) error { return context.Canceled }
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		DownloadWorker(0, c)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("worker still running after the limit failed")
+	}
+
+	// The chunk fetched goes back to be fetched by another worker.
+	c.chunkMutex.Lock()
+	defer c.chunkMutex.Unlock()
+	if len(c.inFlight) != 0 || len(c.pending) != 2 {
+		t.Errorf("in flight %v, pending %v, want both chunks pending", c.inFlight, c.pending)
+	}
+}
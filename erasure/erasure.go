@@ -0,0 +1,228 @@
+// Package erasure implements systematic Reed-Solomon coding over GF(2^8).
+//
+// A Coder turns k equally sized data shards into m parity shards such that
+// the data can be recovered from any k of the k+m shards. The encoding matrix
+// is the identity stacked on a Cauchy matrix, so every k×k submatrix is
+// invertible.
+package erasure
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	ErrTooFewShards = errors.New("erasure: not enough shards to reconstruct")
+	ErrShardSize    = errors.New("erasure: shards differ in size")
+)
+
+// GF(2^8) with the polynomial x^8+x^4+x^3+x^2+1 (0x11d).
+var (
+	expTable [510]byte
+	logTable [256]byte
+	mulTable [256][256]byte
+)
+
+func init() {
+	x := 1
+	for i := 0; i < 255; i++ {
+		expTable[i] = byte(x)
+		logTable[x] = byte(i)
+		x <<= 1
+		if x&0x100 != 0 {
+			x ^= 0x11d
+		}
+	}
+	for i := 255; i < len(expTable); i++ {
+		expTable[i] = expTable[i-255]
+	}
+	for a := 1; a < 256; a++ {
+		for b := 1; b < 256; b++ {
+			mulTable[a][b] = expTable[int(logTable[a])+int(logTable[b])]
+		}
+	}
+}
+
+func inv(a byte) byte {
+	return expTable[255-int(logTable[a])]
+}
+
+// mulAdd sets dst[i] ^= c*src[i].
+func mulAdd(dst, src []byte, c byte) {
+	if c == 0 {
+		return
+	}
+	row := &mulTable[c]
+	for i, b := range src {
+		dst[i] ^= row[b]
+	}
+}
+
+// Coder encodes and reconstructs stripes of DataShards + ParityShards shards.
+type Coder struct {
+	DataShards   int
+	ParityShards int
+	parity       [][]byte // ParityShards × DataShards Cauchy matrix
+}
+
+// New returns a Coder for k data and m parity shards, k+m <= 256.
+func New(k, m int) (*Coder, error) {
+	if k <= 0 || m <= 0 || k+m > 256 {
+		return nil, fmt.Errorf("erasure: invalid layout %d+%d", k, m)
+	}
+	c := &Coder{DataShards: k, ParityShards: m, parity: make([][]byte, m)}
+	for i := range m {
+		c.parity[i] = make([]byte, k)
+		for j := range k {
+			c.parity[i][j] = inv(byte(k+i) ^ byte(j))
+		}
+	}
+	return c, nil
+}
+
+// row returns the encoding coefficients of a shard.
+func (c *Coder) row(shard int) []byte {
+	if shard >= c.DataShards {
+		return c.parity[shard-c.DataShards]
+	}
+	identity := make([]byte, c.DataShards)
+	identity[shard] = 1
+	return identity
+}
+
+// Encode computes the parity shards of DataShards equally sized data shards.
+func (c *Coder) Encode(data [][]byte) ([][]byte, error) {
+	if len(data) != c.DataShards {
+		return nil, fmt.Errorf("erasure: got %d data shards, want %d", len(data), c.DataShards)
+	}
+	size := len(data[0])
+	for _, shard := range data {
+		if len(shard) != size {
+			return nil, ErrShardSize
+		}
+	}
+
+	parity := make([][]byte, c.ParityShards)
+	for i := range parity {
+		parity[i] = make([]byte, size)
+		for j, shard := range data {
+			mulAdd(parity[i], shard, c.parity[i][j])
+		}
+	}
+	return parity, nil
+}
+
+// Reconstruct fills in the nil entries of shards, which holds the
+// DataShards+ParityShards shards of one stripe in order. At least DataShards
+// of them must be present.
+func (c *Coder) Reconstruct(shards [][]byte) error {
+	k := c.DataShards
+	if len(shards) != k+c.ParityShards {
+		return fmt.Errorf("erasure: got %d shards, want %d", len(shards), k+c.ParityShards)
+	}
+
+	var present []int
+	size := -1
+	for i, shard := range shards {
+		if shard == nil {
+			continue
+		}
+		if size >= 0 && len(shard) != size {
+			return ErrShardSize
+		}
+		size = len(shard)
+		present = append(present, i)
+	}
+	if len(present) < k {
+		return ErrTooFewShards
+	}
+	present = present[:k]
+
+	missingData := false
+	for j := range k {
+		if shards[j] == nil {
+			missingData = true
+			break
+		}
+	}
+
+	if missingData {
+		matrix := make([][]byte, k)
+		for t, shard := range present {
+			matrix[t] = append([]byte(nil), c.row(shard)...)
+		}
+		decode, err := invert(matrix)
+		if err != nil {
+			return err
+		}
+		for j := range k {
+			if shards[j] != nil {
+				continue
+			}
+			out := make([]byte, size)
+			for t, shard := range present {
+				mulAdd(out, shards[shard], decode[j][t])
+			}
+			shards[j] = out
+		}
+	}
+
+	for i := range c.ParityShards {
+		if shards[k+i] != nil {
+			continue
+		}
+		out := make([]byte, size)
+		for j := range k {
+			mulAdd(out, shards[j], c.parity[i][j])
+		}
+		shards[k+i] = out
+	}
+	return nil
+}
+
+// invert returns the inverse of a square matrix by Gauss-Jordan elimination.
+func invert(matrix [][]byte) ([][]byte, error) {
+	n := len(matrix)
+	result := make([][]byte, n)
+	for i := range n {
+		result[i] = make([]byte, n)
+		result[i][i] = 1
+	}
+
+	for col := range n {
+		pivot := col
+		for pivot < n && matrix[pivot][col] == 0 {
+			pivot++
+		}
+		if pivot == n {
+			return nil, errors.New("erasure: singular matrix")
+		}
+		matrix[col], matrix[pivot] = matrix[pivot], matrix[col]
+		result[col], result[pivot] = result[pivot], result[col]
+
+		scale := inv(matrix[col][col])
+		for j := range n {
+			matrix[col][j] = mulTable[scale][matrix[col][j]]
+			result[col][j] = mulTable[scale][result[col][j]]
+		}
+		for row := range n {
+			if row == col || matrix[row][col] == 0 {
+				continue
+			}
+			factor := matrix[row][col]
+			mulAdd(matrix[row], matrix[col], factor)
+			mulAdd(result[row], result[col], factor)
+		}
+	}
+	return result, nil
+}
+
+// Pad returns chunk extended with zeros to size bytes.
+func Pad(chunk []byte, size int) []byte {
+	if len(chunk) >= size {
+		return chunk
+	}
+	padded := make([]byte, size)
+	copy(padded, chunk)
+	return padded
+}
@@ -0,0 +1,161 @@
+package erasure
+
+import (
+	"bytes"
+	"errors"
+	"math/rand"
+	"testing"
+)
+
+func TestFieldInverse(t *testing.T) {
+	for a := 1; a < 256; a++ {
+		if got := mulTable[a][inv(byte(a))]; got != 1 {
+			t.Fatalf("%d * inv(%d) = %d, want 1", a, a, got)
+		}
+		if mulTable[a][1] != byte(a) || mulTable[a][0] != 0 {
+			t.Fatalf("1 and 0 are not the identity and zero for %d", a)
+		}
+	}
+}
+
+func TestInvert(t *testing.T) {
+	c, _ := New(5, 3)
+	// Any 5 rows of the encoding matrix form an invertible matrix.
+	rows := []int{0, 2, 5, 6, 7}
+	matrix := make([][]byte, len(rows))
+	for i, shard := range rows {
+		matrix[i] = append([]byte(nil), c.row(shard)...)
+	}
+	original := make([][]byte, len(matrix))
+	for i := range matrix {
+		original[i] = append([]byte(nil), matrix[i]...)
+	}
+
+	inverse, err := invert(matrix)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range original {
+		for j := range original {
+			var sum byte
+			for k := range original {
+				sum ^= mulTable[original[i][k]][inverse[k][j]]
+			}
+			want := byte(0)
+			if i == j {
+				want = 1
+			}
+			if sum != want {
+				t.Fatalf("M * inverse(M) is not the identity at (%d, %d): %d", i, j, sum)
+			}
+		}
+	}
+
+	if _, err := invert([][]byte{{1, 2}, {1, 2}}); err == nil {
+		t.Fatal("singular matrix inverted")
+	}
+}
+
+// erasurePatterns calls f with every set of at most max of n shards.
+func erasurePatterns(n, max int, f func(erased []int)) {
+	var walk func(start int, erased []int)
+	walk = func(start int, erased []int) {
+		f(erased)
+		if len(erased) == max {
+			return
+		}
+		for i := start; i < n; i++ {
+			walk(i+1, append(erased, i))
+		}
+	}
+	walk(0, nil)
+}
+
+func TestReconstruct(t *testing.T) {
+	layouts := []struct{ k, m int }{{1, 1}, {2, 1}, {3, 2}, {4, 2}, {5, 3}, {10, 4}}
+	rng := rand.New(rand.NewSource(1))
+	for _, layout := range layouts {
+		c, err := New(layout.k, layout.m)
+		if err != nil {
+			t.Fatalf("New(%d, %d): %v", layout.k, layout.m, err)
+		}
+		data := make([][]byte, layout.k)
+		for i := range data {
+			data[i] = make([]byte, 64)
+			rng.Read(data[i])
+		}
+		parity, err := c.Encode(data)
+		if err != nil {
+			t.Fatalf("Encode %d+%d: %v", layout.k, layout.m, err)
+		}
+		stripe := append(append([][]byte(nil), data...), parity...)
+
+		patterns := 0
+		erasurePatterns(layout.k+layout.m, layout.m, func(erased []int) {
+			patterns++
+			shards := append([][]byte(nil), stripe...)
+			for _, shard := range erased {
+				shards[shard] = nil
+			}
+			if err := c.Reconstruct(shards); err != nil {
+				t.Fatalf("%d+%d, erased %v: %v", layout.k, layout.m, erased, err)
+			}
+			for i := range shards {
+				if !bytes.Equal(shards[i], stripe[i]) {
+					t.Fatalf("%d+%d, erased %v: shard %d rebuilt wrong", layout.k, layout.m, erased, i)
+				}
+			}
+		})
+		if patterns == 0 {
+			t.Fatalf("%d+%d: no erasure pattern tried", layout.k, layout.m)
+		}
+
+		// One erasure more than there are parity shards cannot be rebuilt.
+		shards := append([][]byte(nil), stripe...)
+		for shard := range layout.m + 1 {
+			shards[shard] = nil
+		}
+		if err := c.Reconstruct(shards); !errors.Is(err, ErrTooFewShards) {
+			t.Errorf("%d+%d with %d erasures: err = %v, want ErrTooFewShards", layout.k, layout.m, layout.m+1, err)
+		}
+	}
+}
+
+func TestCoderErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		k, m int
+	}{
+		{"no data shards", 0, 2},
+		{"no parity shards", 4, 0},
+		{"too many shards", 200, 57},
+	}
+	for _, tt := range tests {
+		if _, err := New(tt.k, tt.m); err == nil {
+			t.Errorf("%s: New(%d, %d) succeeded", tt.name, tt.k, tt.m)
+		}
+	}
+
+	c, _ := New(2, 1)
+	if _, err := c.Encode([][]byte{{1, 2}}); err == nil {
+		t.Error("Encode took too few data shards")
+	}
+	if _, err := c.Encode([][]byte{{1, 2}, {3}}); !errors.Is(err, ErrShardSize) {
+		t.Errorf("Encode of uneven shards: err = %v, want ErrShardSize", err)
+	}
+	if err := c.Reconstruct([][]byte{{1, 2}, nil}); err == nil {
+		t.Error("Reconstruct took a short stripe")
+	}
+	if err := c.Reconstruct([][]byte{{1, 2}, {3}, nil}); !errors.Is(err, ErrShardSize) {
+		t.Errorf("Reconstruct of uneven shards: err = %v, want ErrShardSize", err)
+	}
+}
+
+func TestPad(t *testing.T) {
+	if got := Pad([]byte{1, 2}, 4); !bytes.Equal(got, []byte{1, 2, 0, 0}) {
+		t.Errorf("Pad = %v", got)
+	}
+	if got := Pad([]byte{1, 2, 3}, 2); !bytes.Equal(got, []byte{1, 2, 3}) {
+		t.Errorf("Pad of a longer chunk = %v", got)
+	}
+}
@@ -5,7 +5,8 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
-	"strings"
+	"slices"
+	"sync"
 
 	// "encoding/json"
 	// "path/filepath"
@@ -21,50 +22,90 @@ import (
 
 type App struct {
 	grpcClient	 	*client.PeerServer
+	indexingClient	*client.FailoverClient
 	peerAddress		string
 	httpPort		string
-	contributor		bool
 	ctx        		context.Context	
+	settingsMu		sync.Mutex
+	settings		client.Settings
+	settingsPath	string
+	stopContributing	context.CancelFunc
+	contributionDone	chan struct{}
 }
 
-func NewApp(address string, httpPort string, contributor bool) *App {
-	_, indexingClient := client.GetIndexingClient("localhost:50051")
-	if contributor {
-		log.Print("hello")
-		indexingClient.RegisterContributor(context.Background(), &pb.ContributorRequest{
-			ContriAddr: address,
-		})
-		log.Print("world")
+func NewApp(port string, httpPort string, settings client.Settings, settingsPath string) (*App, error) {
+	if err := settings.Validate(); err != nil {
+		return nil, err
+	}
+	indexingClient, err := client.GetIndexingClient(settings.ServerAddresses)
+	if err != nil {
+		return nil, err
+	}
+
+	// Listen on every interface when other machines are told to reach us
+	// under another address.
+	listenAddress := "localhost:" + port
+	if settings.AdvertiseAddress != listenAddress {
+		listenAddress = ":" + port
 	}
-	
 	clt := &client.PeerServer{
 		Client: indexingClient,
-		PeerAddress: address,
+		PeerAddress: settings.AdvertiseAddress,
+		ListenAddress: listenAddress,
+		DHT: client.NewDHT(settings.AdvertiseAddress),
 	}
 
 	app := &App{
 		grpcClient: clt,
-		peerAddress: address,
+		indexingClient: indexingClient,
+		peerAddress: settings.AdvertiseAddress,
 		httpPort: httpPort,
-		contributor: contributor,
+		settings: settings,
+		settingsPath: settingsPath,
 	};
 
 	clt.EventEmitter = app.EventEmitter
 
-	parts := strings.Split(address, ":")
-	port := parts[len(parts)-1] // "50051"
-
-	client.DOWNLOAD_PATH = client.DOWNLOAD_PATH + "_" + port 
-	client.TORRENTS_DIR = client.DOWNLOAD_PATH + "/torrents"
-	client.CACHE_DIR = client.DOWNLOAD_PATH + "/cache"
+	client.UseDownloadDir(settings.DownloadDir)
+	client.SetMaxThreads(settings.MaxThreads)
+	clt.SetBandwidthLimits(settings.UploadLimit, settings.DownloadLimit)
 
 	go func() {
 		if err := client.StartPeerServer(clt); err != nil {
 			log.Printf("Peer server failed to start: %v", err)
 		}
 	}()
+	go clt.MaintainLease()
+	go clt.JoinDHT(settings.DHTBootstrap)
+
+	if settings.Contributor {
+		app.startContributing()
+	}
+
+	return app, nil
+}
+
+// startContributing offers this peer's storage to the swarm until
+// stopContributing is called. Callers must hold settingsMu, except in NewApp.
+func (a *App) startContributing() {
+	ctx, cancel := context.WithCancel(context.Background())
+	a.stopContributing = cancel
+	a.contributionDone = make(chan struct{})
+	go func(done chan struct{}) {
+		a.grpcClient.MaintainContribution(ctx)
+		close(done)
+	}(a.contributionDone)
+}
 
-	return app
+// stopContribution deregisters the contributor and waits until it is done.
+// Callers must hold settingsMu, except on shutdown.
+func (a *App) stopContribution() {
+	if a.stopContributing == nil {
+		return
+	}
+	a.stopContributing()
+	<-a.contributionDone
+	a.stopContributing = nil
 }
 
 // ============ Wails Lifecycle Hooks ============
@@ -85,6 +126,9 @@ func (a *App) beforeClose(ctx context.Context) bool {
 
 func (a *App) shutdown(ctx context.Context) {
 	log.Println("App shutdown")
+	a.settingsMu.Lock()
+	defer a.settingsMu.Unlock()
+	a.stopContribution()
 }
 
 // ============ App Methods Bound to Frontend ============
@@ -97,7 +141,64 @@ func (a *App) GetPeerAddress() string {
 }
 
 func (a *App) GetContributorStatus() bool { 
-	return a.contributor;
+	a.settingsMu.Lock()
+	defer a.settingsMu.Unlock()
+	return a.settings.Contributor;
+}
+
+// GetSettings returns the settings in effect, as stored in the settings file.
+func (a *App) GetSettings() client.Settings {
+	a.settingsMu.Lock()
+	defer a.settingsMu.Unlock()
+	return a.settings
+}
+
+// UpdateSettings validates, stores and applies new settings. Server
+// addresses, DHT bootstrap peers, the thread count, bandwidth limits and
+// contributor mode take effect right away: the client reconnects to the new
+// servers, where the lease and contributor loops register again, without
+// interrupting downloads. The download directory and advertised address are
+// fixed while the app runs, so changes to them are refused; they are set in
+// the settings file before starting the app.
+func (a *App) UpdateSettings(settings client.Settings) error {
+	if err := settings.Validate(); err != nil {
+		return err
+	}
+
+	a.settingsMu.Lock()
+	defer a.settingsMu.Unlock()
+
+	old := a.settings
+	if settings.DownloadDir != old.DownloadDir {
+		return fmt.Errorf("the download directory cannot change while the app runs; set download_dir in %s and restart", a.settingsPath)
+	}
+	if settings.AdvertiseAddress != old.AdvertiseAddress {
+		return fmt.Errorf("the advertised address cannot change while the app runs; set advertise_address in %s and restart", a.settingsPath)
+	}
+	if err := client.SaveSettings(a.settingsPath, settings); err != nil {
+		return err
+	}
+	a.settings = settings
+
+	if !slices.Equal(old.ServerAddresses, settings.ServerAddresses) {
+		if err := a.indexingClient.SetServers(settings.ServerAddresses); err != nil {
+			return err
+		}
+		log.Printf("Central servers set to %v", settings.ServerAddresses)
+	}
+	client.SetMaxThreads(settings.MaxThreads)
+	if settings.UploadLimit != old.UploadLimit || settings.DownloadLimit != old.DownloadLimit {
+		a.grpcClient.SetBandwidthLimits(settings.UploadLimit, settings.DownloadLimit)
+	}
+	if !slices.Equal(old.DHTBootstrap, settings.DHTBootstrap) {
+		go a.grpcClient.DHT.Bootstrap(context.Background(), settings.DHTBootstrap)
+	}
+	if settings.Contributor && !old.Contributor {
+		a.startContributing()
+	} else if !settings.Contributor && old.Contributor {
+		a.stopContribution()
+	}
+	return nil
 }
 
 func (a *App) GetTorrents() []client.TorrentInfo {
@@ -119,13 +220,15 @@ func (a *App) GetLibraryTorrents() []client.TorrentInfo {
 }
 
 
-func (a *App) SearchSongs(query string) []*pb.SongInfo {
-	results, err := a.grpcClient.SearchFile(query)
-	if err != nil {
-		log.Printf("SearchSongs error: %v", err)
-		return nil
+// SearchSongs asks the central servers. Only when none of them answers is
+// the query flooded to the surrounding peers, so songs are still found.
+func (a *App) SearchSongs(query string, onlyAvailable bool) []*pb.SongInfo {
+	results, err := a.grpcClient.SearchFile(query, onlyAvailable)
+	if err == nil {
+		return results
 	}
-	return results
+	log.Printf("SearchSongs error: %v; asking the peers around", err)
+	return a.grpcClient.MergeSongs(nil, a.grpcClient.FloodSearch(query))
 }
 
 func (a *App) UploadFile(filePath string, artist string) string {
@@ -137,9 +240,10 @@ func (a *App) UploadFile(filePath string, artist string) string {
 	return result
 }
 
-func (a *App) DownloadFile(query string) string {
+// DownloadFile downloads a search result from the index named by its origin.
+func (a *App) DownloadFile(query string, origin string) string {
 
-	result := a.grpcClient.DownloadFile(query)
+	result := a.grpcClient.DownloadFileFrom(query, origin)
 	return result
 }
 
@@ -150,10 +254,9 @@ func (a *App) EventEmitter(eventName string, returnObject any) {
 }
 
 func (a *App) StopSeeding(query string) {
-	// a.grpcClient.Client -> indexing client (client that is connected to the server)
-	a.grpcClient.Client.StopSeeding(context.Background(), &pb.SeedingRequest{
-		FileName: query, ClientAddr: a.peerAddress,
-	})
+	if err := a.grpcClient.StopSeeding(query); err != nil {
+		log.Printf("StopSeeding error: %v", err)
+	}
 	runtime.EventsEmit(a.ctx, "download-status", client.DownloadStatus{
 		Filename: query,
 		Status: "Downloaded",
@@ -161,15 +264,33 @@ func (a *App) StopSeeding(query string) {
 }
 
 func (a *App) EnableSeeding(query string) {
-	a.grpcClient.Client.EnableSeeding(context.Background(), &pb.SeedingRequest{
-		FileName: query, ClientAddr: a.peerAddress,
-	})
+	if err := a.grpcClient.EnableSeeding(query); err != nil {
+		log.Printf("EnableSeeding error: %v", err)
+	}
 	runtime.EventsEmit(a.ctx, "download-status", client.DownloadStatus{
 		Filename: query,
 		Status: "Seeding",
 	})
 }
 
+// SetFileBandwidthLimits limits the bytes per second of one file uploaded
+// and downloaded, within the limits of the settings; 0 lifts a limit. It
+// lasts until the app exits.
+func (a *App) SetFileBandwidthLimits(filename string, upload int64, download int64) error {
+	if upload < 0 || download < 0 {
+		return fmt.Errorf("bandwidth limits cannot be negative")
+	}
+	a.grpcClient.SetFileBandwidthLimits(filename, upload, download)
+	return nil
+}
+
+// SetSequentialDownload makes a running download fetch its chunks in order,
+// for playback, instead of rarest first. It reports whether the file is
+// being downloaded.
+func (a *App) SetSequentialDownload(filename string, sequential bool) bool {
+	return a.grpcClient.SetSequential(filename, sequential)
+}
+
 func (a *App) SelectFileAndUpload() (string, error) {
 	path, err := runtime.OpenFileDialog(a.ctx, runtime.OpenDialogOptions{
 		Title: "Select a Song",
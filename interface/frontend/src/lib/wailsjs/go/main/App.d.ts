@@ -3,7 +3,7 @@
 import {client} from '../models';
 import {__} from '../models';
 
-export function DownloadFile(arg1:string):Promise<string>;
+export function DownloadFile(arg1:string,arg2:string):Promise<string>;
 
 export function EnableSeeding(arg1:string):Promise<void>;
 
@@ -19,12 +19,20 @@ export function GetMusicFilePath(arg1:string):Promise<string>;
 
 export function GetPeerAddress():Promise<string>;
 
+export function GetSettings():Promise<client.Settings>;
+
 export function GetTorrents():Promise<Array<client.TorrentInfo>>;
 
-export function SearchSongs(arg1:string):Promise<Array<__.SongInfo>>;
+export function SearchSongs(arg1:string,arg2:boolean):Promise<Array<__.SongInfo>>;
 
 export function SelectFileAndUpload():Promise<string>;
 
+export function SetFileBandwidthLimits(arg1:string,arg2:number,arg3:number):Promise<void>;
+
+export function SetSequentialDownload(arg1:string,arg2:boolean):Promise<boolean>;
+
 export function StopSeeding(arg1:string):Promise<void>;
 
+export function UpdateSettings(arg1:client.Settings):Promise<void>;
+
 export function UploadFile(arg1:string,arg2:string):Promise<string>;
@@ -2,8 +2,8 @@
 // Cynhyrchwyd y ffeil hon yn awtomatig. PEIDIWCH Â MODIWL
 // This file is automatically generated. DO NOT EDIT
 
-export function DownloadFile(arg1) {
-  return window['go']['main']['App']['DownloadFile'](arg1);
+export function DownloadFile(arg1, arg2) {
+  return window['go']['main']['App']['DownloadFile'](arg1, arg2);
 }
 
 export function EnableSeeding(arg1) {
@@ -34,22 +34,38 @@ export function GetPeerAddress() {
   return window['go']['main']['App']['GetPeerAddress']();
 }
 
+export function GetSettings() {
+  return window['go']['main']['App']['GetSettings']();
+}
+
 export function GetTorrents() {
   return window['go']['main']['App']['GetTorrents']();
 }
 
-export function SearchSongs(arg1) {
-  return window['go']['main']['App']['SearchSongs'](arg1);
+export function SearchSongs(arg1, arg2) {
+  return window['go']['main']['App']['SearchSongs'](arg1, arg2);
 }
 
 export function SelectFileAndUpload() {
   return window['go']['main']['App']['SelectFileAndUpload']();
 }
 
+export function SetFileBandwidthLimits(arg1, arg2, arg3) {
+  return window['go']['main']['App']['SetFileBandwidthLimits'](arg1, arg2, arg3);
+}
+
+export function SetSequentialDownload(arg1, arg2) {
+  return window['go']['main']['App']['SetSequentialDownload'](arg1, arg2);
+}
+
 export function StopSeeding(arg1) {
   return window['go']['main']['App']['StopSeeding'](arg1);
 }
 
+export function UpdateSettings(arg1) {
+  return window['go']['main']['App']['UpdateSettings'](arg1);
+}
+
 export function UploadFile(arg1, arg2) {
   return window['go']['main']['App']['UploadFile'](arg1, arg2);
 }
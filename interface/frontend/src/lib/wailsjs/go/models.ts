@@ -6,6 +6,11 @@ export namespace __ {
 	    peer_addresses?: string[];
 	    created_at?: string;
 	    duration?: string;
+	    live_seeders?: number;
+	    contributors?: number;
+	    available?: boolean;
+	    score?: number;
+	    origin?: string;
 	
 	    static createFrom(source: any = {}) {
 	        return new SongInfo(source);
@@ -18,6 +23,11 @@ export namespace __ {
 	        this.peer_addresses = source["peer_addresses"];
 	        this.created_at = source["created_at"];
 	        this.duration = source["duration"];
+	        this.live_seeders = source["live_seeders"];
+	        this.contributors = source["contributors"];
+	        this.available = source["available"];
+	        this.score = source["score"];
+	        this.origin = source["origin"];
 	    }
 	}
 
@@ -25,6 +35,32 @@ export namespace __ {
 
 export namespace client {
 	
+	export class Settings {
+	    server_addresses: string[];
+	    download_dir: string;
+	    max_threads: number;
+	    contributor: boolean;
+	    advertise_address: string;
+	    dht_bootstrap: string[];
+	    upload_limit: number;
+	    download_limit: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new Settings(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.server_addresses = source["server_addresses"];
+	        this.download_dir = source["download_dir"];
+	        this.max_threads = source["max_threads"];
+	        this.contributor = source["contributor"];
+	        this.advertise_address = source["advertise_address"];
+	        this.dht_bootstrap = source["dht_bootstrap"];
+	        this.upload_limit = source["upload_limit"];
+	        this.download_limit = source["download_limit"];
+	    }
+	}
 	export class TorrentMetadata {
 	    file_name: string;
 	    file_size: number;
@@ -9,6 +9,8 @@ import (
 	"os"
 
 	// server "napster/server"
+	"napster/client"
+
 	"github.com/wailsapp/wails/v2"
 	"github.com/wailsapp/wails/v2/pkg/logger"
 	"github.com/wailsapp/wails/v2/pkg/options"
@@ -27,10 +29,31 @@ func main() {
 
 	port := flag.String("port", "5003", "Port to run the peer server on")
 	contributor := flag.Bool("c", false, "Contributor Node")
+	servers := flag.String("servers", "localhost:50051", "Central server addresses, comma-separated")
 	flag.Parse()
 
-	download_dir := "./downloads_" + *port
-	http.Handle("/audio/", http.StripPrefix("/audio/", http.FileServer(http.Dir(download_dir))))
+	settingsPath := "./settings_" + *port + ".json"
+	settings, err := client.LoadSettings(settingsPath, client.Settings{
+		ServerAddresses:  client.ParseServerList(*servers),
+		DownloadDir:      "./downloads_" + *port,
+		MaxThreads:       client.MaxThreads(),
+		Contributor:      *contributor,
+		AdvertiseAddress: "localhost:" + *port,
+	})
+	if err != nil {
+		log.Fatalf("Failed to load settings: %v", err)
+	}
+	// Flags given on the command line win over the stored settings.
+	flag.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "c":
+			settings.Contributor = *contributor
+		case "servers":
+			settings.ServerAddresses = client.ParseServerList(*servers)
+		}
+	})
+
+	http.Handle("/audio/", http.StripPrefix("/audio/", http.FileServer(http.Dir(settings.DownloadDir))))
 
 	httpPort := ":" + *port + "0"
 	fmt.Printf("Server started at http://localhost%s\n", httpPort)
@@ -43,13 +66,14 @@ func main() {
 		}
 	}()
 
-	address := "localhost:" + *port
-
 	// Create an instance of the app structure
-	app := NewApp(address, httpPort, *contributor)
+	app, err := NewApp(*port, httpPort, settings, settingsPath)
+	if err != nil {
+		log.Fatalf("Failed to connect to the central servers: %v", err)
+	}
 
 	// Create application with options
-	err := wails.Run(&options.App{
+	err = wails.Run(&options.App{
 		Title:             "interface",
 		Width:             1024,
 		Height:            768,
@@ -19,22 +19,30 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	CentralServer_SearchFile_FullMethodName          = "/napster.CentralServer/SearchFile"
-	CentralServer_UploadFile_FullMethodName          = "/napster.CentralServer/UploadFile"
-	CentralServer_GetTorrent_FullMethodName          = "/napster.CentralServer/GetTorrent"
-	CentralServer_EnableSeeding_FullMethodName       = "/napster.CentralServer/EnableSeeding"
-	CentralServer_StopSeeding_FullMethodName         = "/napster.CentralServer/StopSeeding"
-	CentralServer_HealthCheck_FullMethodName         = "/napster.CentralServer/HealthCheck"
-	CentralServer_HealthCheckServer_FullMethodName   = "/napster.CentralServer/HealthCheckServer"
-	CentralServer_RegisterContributor_FullMethodName = "/napster.CentralServer/RegisterContributor"
+	CentralServer_RegisterPeer_FullMethodName          = "/napster.CentralServer/RegisterPeer"
+	CentralServer_Heartbeat_FullMethodName             = "/napster.CentralServer/Heartbeat"
+	CentralServer_SearchFile_FullMethodName            = "/napster.CentralServer/SearchFile"
+	CentralServer_RankedSearch_FullMethodName          = "/napster.CentralServer/RankedSearch"
+	CentralServer_UploadFile_FullMethodName            = "/napster.CentralServer/UploadFile"
+	CentralServer_GetTorrent_FullMethodName            = "/napster.CentralServer/GetTorrent"
+	CentralServer_EnableSeeding_FullMethodName         = "/napster.CentralServer/EnableSeeding"
+	CentralServer_StopSeeding_FullMethodName           = "/napster.CentralServer/StopSeeding"
+	CentralServer_HealthCheck_FullMethodName           = "/napster.CentralServer/HealthCheck"
+	CentralServer_HealthCheckServer_FullMethodName     = "/napster.CentralServer/HealthCheckServer"
+	CentralServer_RegisterContributor_FullMethodName   = "/napster.CentralServer/RegisterContributor"
+	CentralServer_ContributorHeartbeat_FullMethodName  = "/napster.CentralServer/ContributorHeartbeat"
+	CentralServer_DeregisterContributor_FullMethodName = "/napster.CentralServer/DeregisterContributor"
+	CentralServer_ListContributors_FullMethodName      = "/napster.CentralServer/ListContributors"
 )
 
 // CentralServerClient is the client API for CentralServer service.
 //
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
 type CentralServerClient interface {
-	// rpc RegisterPeer(RegisterRequest) returns (RegisterResponse);
+	RegisterPeer(ctx context.Context, in *RegisterRequest, opts ...grpc.CallOption) (*RegisterResponse, error)
+	Heartbeat(ctx context.Context, in *HeartbeatRequest, opts ...grpc.CallOption) (*HeartbeatResponse, error)
 	SearchFile(ctx context.Context, in *SearchRequest, opts ...grpc.CallOption) (*SearchResponse, error)
+	RankedSearch(ctx context.Context, in *RankedSearchRequest, opts ...grpc.CallOption) (*RankedSearchResponse, error)
 	// rpc GenerateTorrent(TorrentRequest) returns (TorrentResponse);
 	UploadFile(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[FileChunk, UploadResponse], error)
 	GetTorrent(ctx context.Context, in *SearchRequest, opts ...grpc.CallOption) (*TorrentResponse, error)
@@ -43,6 +51,9 @@ type CentralServerClient interface {
 	HealthCheck(ctx context.Context, in *HealthCheckRequest, opts ...grpc.CallOption) (*HealthCheckResponse, error)
 	HealthCheckServer(ctx context.Context, in *HealthCheckRequest, opts ...grpc.CallOption) (*HealthCheckResponse, error)
 	RegisterContributor(ctx context.Context, in *ContributorRequest, opts ...grpc.CallOption) (*GenResponse, error)
+	ContributorHeartbeat(ctx context.Context, in *ContributorRequest, opts ...grpc.CallOption) (*HeartbeatResponse, error)
+	DeregisterContributor(ctx context.Context, in *ContributorRequest, opts ...grpc.CallOption) (*GenResponse, error)
+	ListContributors(ctx context.Context, in *ListContributorsRequest, opts ...grpc.CallOption) (*ListContributorsResponse, error)
 }
 
 type centralServerClient struct {
@@ -53,6 +64,26 @@ func NewCentralServerClient(cc grpc.ClientConnInterface) CentralServerClient {
 	return &centralServerClient{cc}
 }
 
+func (c *centralServerClient) RegisterPeer(ctx context.Context, in *RegisterRequest, opts ...grpc.CallOption) (*RegisterResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RegisterResponse)
+	err := c.cc.Invoke(ctx, CentralServer_RegisterPeer_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *centralServerClient) Heartbeat(ctx context.Context, in *HeartbeatRequest, opts ...grpc.CallOption) (*HeartbeatResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(HeartbeatResponse)
+	err := c.cc.Invoke(ctx, CentralServer_Heartbeat_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *centralServerClient) SearchFile(ctx context.Context, in *SearchRequest, opts ...grpc.CallOption) (*SearchResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(SearchResponse)
@@ -63,6 +94,16 @@ func (c *centralServerClient) SearchFile(ctx context.Context, in *SearchRequest,
 	return out, nil
 }
 
+func (c *centralServerClient) RankedSearch(ctx context.Context, in *RankedSearchRequest, opts ...grpc.CallOption) (*RankedSearchResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RankedSearchResponse)
+	err := c.cc.Invoke(ctx, CentralServer_RankedSearch_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *centralServerClient) UploadFile(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[FileChunk, UploadResponse], error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	stream, err := c.cc.NewStream(ctx, &CentralServer_ServiceDesc.Streams[0], CentralServer_UploadFile_FullMethodName, cOpts...)
@@ -136,12 +177,44 @@ func (c *centralServerClient) RegisterContributor(ctx context.Context, in *Contr
 	return out, nil
 }
 
+func (c *centralServerClient) ContributorHeartbeat(ctx context.Context, in *ContributorRequest, opts ...grpc.CallOption) (*HeartbeatResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(HeartbeatResponse)
+	err := c.cc.Invoke(ctx, CentralServer_ContributorHeartbeat_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *centralServerClient) DeregisterContributor(ctx context.Context, in *ContributorRequest, opts ...grpc.CallOption) (*GenResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GenResponse)
+	err := c.cc.Invoke(ctx, CentralServer_DeregisterContributor_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *centralServerClient) ListContributors(ctx context.Context, in *ListContributorsRequest, opts ...grpc.CallOption) (*ListContributorsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListContributorsResponse)
+	err := c.cc.Invoke(ctx, CentralServer_ListContributors_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // CentralServerServer is the server API for CentralServer service.
 // All implementations must embed UnimplementedCentralServerServer
 // for forward compatibility.
 type CentralServerServer interface {
-	// rpc RegisterPeer(RegisterRequest) returns (RegisterResponse);
+	RegisterPeer(context.Context, *RegisterRequest) (*RegisterResponse, error)
+	Heartbeat(context.Context, *HeartbeatRequest) (*HeartbeatResponse, error)
 	SearchFile(context.Context, *SearchRequest) (*SearchResponse, error)
+	RankedSearch(context.Context, *RankedSearchRequest) (*RankedSearchResponse, error)
 	// rpc GenerateTorrent(TorrentRequest) returns (TorrentResponse);
 	UploadFile(grpc.ClientStreamingServer[FileChunk, UploadResponse]) error
 	GetTorrent(context.Context, *SearchRequest) (*TorrentResponse, error)
@@ -150,6 +223,9 @@ type CentralServerServer interface {
 	HealthCheck(context.Context, *HealthCheckRequest) (*HealthCheckResponse, error)
 	HealthCheckServer(context.Context, *HealthCheckRequest) (*HealthCheckResponse, error)
 	RegisterContributor(context.Context, *ContributorRequest) (*GenResponse, error)
+	ContributorHeartbeat(context.Context, *ContributorRequest) (*HeartbeatResponse, error)
+	DeregisterContributor(context.Context, *ContributorRequest) (*GenResponse, error)
+	ListContributors(context.Context, *ListContributorsRequest) (*ListContributorsResponse, error)
 	mustEmbedUnimplementedCentralServerServer()
 }
 
@@ -160,9 +236,18 @@ type CentralServerServer interface {
 // pointer dereference when methods are called.
 type UnimplementedCentralServerServer struct{}
 
+func (UnimplementedCentralServerServer) RegisterPeer(context.Context, *RegisterRequest) (*RegisterResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RegisterPeer not implemented")
+}
+func (UnimplementedCentralServerServer) Heartbeat(context.Context, *HeartbeatRequest) (*HeartbeatResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Heartbeat not implemented")
+}
 func (UnimplementedCentralServerServer) SearchFile(context.Context, *SearchRequest) (*SearchResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method SearchFile not implemented")
 }
+func (UnimplementedCentralServerServer) RankedSearch(context.Context, *RankedSearchRequest) (*RankedSearchResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RankedSearch not implemented")
+}
 func (UnimplementedCentralServerServer) UploadFile(grpc.ClientStreamingServer[FileChunk, UploadResponse]) error {
 	return status.Errorf(codes.Unimplemented, "method UploadFile not implemented")
 }
@@ -184,6 +269,15 @@ func (UnimplementedCentralServerServer) HealthCheckServer(context.Context, *Heal
 func (UnimplementedCentralServerServer) RegisterContributor(context.Context, *ContributorRequest) (*GenResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method RegisterContributor not implemented")
 }
+func (UnimplementedCentralServerServer) ContributorHeartbeat(context.Context, *ContributorRequest) (*HeartbeatResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ContributorHeartbeat not implemented")
+}
+func (UnimplementedCentralServerServer) DeregisterContributor(context.Context, *ContributorRequest) (*GenResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeregisterContributor not implemented")
+}
+func (UnimplementedCentralServerServer) ListContributors(context.Context, *ListContributorsRequest) (*ListContributorsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListContributors not implemented")
+}
 func (UnimplementedCentralServerServer) mustEmbedUnimplementedCentralServerServer() {}
 func (UnimplementedCentralServerServer) testEmbeddedByValue()                       {}
 
@@ -205,6 +299,42 @@ func RegisterCentralServerServer(s grpc.ServiceRegistrar, srv CentralServerServe
 	s.RegisterService(&CentralServer_ServiceDesc, srv)
 }
 
+func _CentralServer_RegisterPeer_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RegisterRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CentralServerServer).RegisterPeer(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CentralServer_RegisterPeer_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CentralServerServer).RegisterPeer(ctx, req.(*RegisterRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CentralServer_Heartbeat_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(HeartbeatRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CentralServerServer).Heartbeat(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CentralServer_Heartbeat_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CentralServerServer).Heartbeat(ctx, req.(*HeartbeatRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _CentralServer_SearchFile_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(SearchRequest)
 	if err := dec(in); err != nil {
@@ -223,6 +353,24 @@ func _CentralServer_SearchFile_Handler(srv interface{}, ctx context.Context, dec
 	return interceptor(ctx, in, info, handler)
 }
 
+func _CentralServer_RankedSearch_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RankedSearchRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CentralServerServer).RankedSearch(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CentralServer_RankedSearch_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CentralServerServer).RankedSearch(ctx, req.(*RankedSearchRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _CentralServer_UploadFile_Handler(srv interface{}, stream grpc.ServerStream) error {
 	return srv.(CentralServerServer).UploadFile(&grpc.GenericServerStream[FileChunk, UploadResponse]{ServerStream: stream})
 }
@@ -338,6 +486,60 @@ func _CentralServer_RegisterContributor_Handler(srv interface{}, ctx context.Con
 	return interceptor(ctx, in, info, handler)
 }
 
+func _CentralServer_ContributorHeartbeat_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ContributorRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CentralServerServer).ContributorHeartbeat(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CentralServer_ContributorHeartbeat_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CentralServerServer).ContributorHeartbeat(ctx, req.(*ContributorRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CentralServer_DeregisterContributor_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ContributorRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CentralServerServer).DeregisterContributor(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CentralServer_DeregisterContributor_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CentralServerServer).DeregisterContributor(ctx, req.(*ContributorRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CentralServer_ListContributors_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListContributorsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CentralServerServer).ListContributors(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CentralServer_ListContributors_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CentralServerServer).ListContributors(ctx, req.(*ListContributorsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // CentralServer_ServiceDesc is the grpc.ServiceDesc for CentralServer service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -345,10 +547,22 @@ var CentralServer_ServiceDesc = grpc.ServiceDesc{
 	ServiceName: "napster.CentralServer",
 	HandlerType: (*CentralServerServer)(nil),
 	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "RegisterPeer",
+			Handler:    _CentralServer_RegisterPeer_Handler,
+		},
+		{
+			MethodName: "Heartbeat",
+			Handler:    _CentralServer_Heartbeat_Handler,
+		},
 		{
 			MethodName: "SearchFile",
 			Handler:    _CentralServer_SearchFile_Handler,
 		},
+		{
+			MethodName: "RankedSearch",
+			Handler:    _CentralServer_RankedSearch_Handler,
+		},
 		{
 			MethodName: "GetTorrent",
 			Handler:    _CentralServer_GetTorrent_Handler,
@@ -373,6 +587,18 @@ var CentralServer_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "RegisterContributor",
 			Handler:    _CentralServer_RegisterContributor_Handler,
 		},
+		{
+			MethodName: "ContributorHeartbeat",
+			Handler:    _CentralServer_ContributorHeartbeat_Handler,
+		},
+		{
+			MethodName: "DeregisterContributor",
+			Handler:    _CentralServer_DeregisterContributor_Handler,
+		},
+		{
+			MethodName: "ListContributors",
+			Handler:    _CentralServer_ListContributors_Handler,
+		},
 	},
 	Streams: []grpc.StreamDesc{
 		{
@@ -388,6 +614,11 @@ const (
 	PeerService_RequestChunk_FullMethodName     = "/napster.PeerService/RequestChunk"
 	PeerService_HealthCheck_FullMethodName      = "/napster.PeerService/HealthCheck"
 	PeerService_DownloadThisFile_FullMethodName = "/napster.PeerService/DownloadThisFile"
+	PeerService_DropFile_FullMethodName         = "/napster.PeerService/DropFile"
+	PeerService_StoreShard_FullMethodName       = "/napster.PeerService/StoreShard"
+	PeerService_Search_FullMethodName           = "/napster.PeerService/Search"
+	PeerService_ExchangePeers_FullMethodName    = "/napster.PeerService/ExchangePeers"
+	PeerService_GetBitfield_FullMethodName      = "/napster.PeerService/GetBitfield"
 )
 
 // PeerServiceClient is the client API for PeerService service.
@@ -397,6 +628,11 @@ type PeerServiceClient interface {
 	RequestChunk(ctx context.Context, in *ChunkRequest, opts ...grpc.CallOption) (*ChunkResponse, error)
 	HealthCheck(ctx context.Context, in *HealthCheckRequest, opts ...grpc.CallOption) (*HealthCheckResponse, error)
 	DownloadThisFile(ctx context.Context, in *SearchRequest, opts ...grpc.CallOption) (*GenResponse, error)
+	DropFile(ctx context.Context, in *SearchRequest, opts ...grpc.CallOption) (*GenResponse, error)
+	StoreShard(ctx context.Context, in *ShardRequest, opts ...grpc.CallOption) (*GenResponse, error)
+	Search(ctx context.Context, in *PeerSearchRequest, opts ...grpc.CallOption) (*PeerSearchResponse, error)
+	ExchangePeers(ctx context.Context, in *PeerExchangeRequest, opts ...grpc.CallOption) (*PeerExchangeResponse, error)
+	GetBitfield(ctx context.Context, in *BitfieldRequest, opts ...grpc.CallOption) (*BitfieldResponse, error)
 }
 
 type peerServiceClient struct {
@@ -437,6 +673,56 @@ func (c *peerServiceClient) DownloadThisFile(ctx context.Context, in *SearchRequ
 	return out, nil
 }
 
+func (c *peerServiceClient) DropFile(ctx context.Context, in *SearchRequest, opts ...grpc.CallOption) (*GenResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GenResponse)
+	err := c.cc.Invoke(ctx, PeerService_DropFile_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *peerServiceClient) StoreShard(ctx context.Context, in *ShardRequest, opts ...grpc.CallOption) (*GenResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GenResponse)
+	err := c.cc.Invoke(ctx, PeerService_StoreShard_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *peerServiceClient) Search(ctx context.Context, in *PeerSearchRequest, opts ...grpc.CallOption) (*PeerSearchResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(PeerSearchResponse)
+	err := c.cc.Invoke(ctx, PeerService_Search_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *peerServiceClient) ExchangePeers(ctx context.Context, in *PeerExchangeRequest, opts ...grpc.CallOption) (*PeerExchangeResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(PeerExchangeResponse)
+	err := c.cc.Invoke(ctx, PeerService_ExchangePeers_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *peerServiceClient) GetBitfield(ctx context.Context, in *BitfieldRequest, opts ...grpc.CallOption) (*BitfieldResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(BitfieldResponse)
+	err := c.cc.Invoke(ctx, PeerService_GetBitfield_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // PeerServiceServer is the server API for PeerService service.
 // All implementations must embed UnimplementedPeerServiceServer
 // for forward compatibility.
@@ -444,6 +730,11 @@ type PeerServiceServer interface {
 	RequestChunk(context.Context, *ChunkRequest) (*ChunkResponse, error)
 	HealthCheck(context.Context, *HealthCheckRequest) (*HealthCheckResponse, error)
 	DownloadThisFile(context.Context, *SearchRequest) (*GenResponse, error)
+	DropFile(context.Context, *SearchRequest) (*GenResponse, error)
+	StoreShard(context.Context, *ShardRequest) (*GenResponse, error)
+	Search(context.Context, *PeerSearchRequest) (*PeerSearchResponse, error)
+	ExchangePeers(context.Context, *PeerExchangeRequest) (*PeerExchangeResponse, error)
+	GetBitfield(context.Context, *BitfieldRequest) (*BitfieldResponse, error)
 	mustEmbedUnimplementedPeerServiceServer()
 }
 
@@ -463,6 +754,21 @@ func (UnimplementedPeerServiceServer) HealthCheck(context.Context, *HealthCheckR
 func (UnimplementedPeerServiceServer) DownloadThisFile(context.Context, *SearchRequest) (*GenResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method DownloadThisFile not implemented")
 }
+func (UnimplementedPeerServiceServer) DropFile(context.Context, *SearchRequest) (*GenResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DropFile not implemented")
+}
+func (UnimplementedPeerServiceServer) StoreShard(context.Context, *ShardRequest) (*GenResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method StoreShard not implemented")
+}
+func (UnimplementedPeerServiceServer) Search(context.Context, *PeerSearchRequest) (*PeerSearchResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Search not implemented")
+}
+func (UnimplementedPeerServiceServer) ExchangePeers(context.Context, *PeerExchangeRequest) (*PeerExchangeResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ExchangePeers not implemented")
+}
+func (UnimplementedPeerServiceServer) GetBitfield(context.Context, *BitfieldRequest) (*BitfieldResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetBitfield not implemented")
+}
 func (UnimplementedPeerServiceServer) mustEmbedUnimplementedPeerServiceServer() {}
 func (UnimplementedPeerServiceServer) testEmbeddedByValue()                     {}
 
@@ -538,6 +844,96 @@ func _PeerService_DownloadThisFile_Handler(srv interface{}, ctx context.Context,
 	return interceptor(ctx, in, info, handler)
 }
 
+func _PeerService_DropFile_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SearchRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PeerServiceServer).DropFile(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PeerService_DropFile_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PeerServiceServer).DropFile(ctx, req.(*SearchRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PeerService_StoreShard_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ShardRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PeerServiceServer).StoreShard(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PeerService_StoreShard_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PeerServiceServer).StoreShard(ctx, req.(*ShardRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PeerService_Search_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PeerSearchRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PeerServiceServer).Search(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PeerService_Search_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PeerServiceServer).Search(ctx, req.(*PeerSearchRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PeerService_ExchangePeers_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PeerExchangeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PeerServiceServer).ExchangePeers(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PeerService_ExchangePeers_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PeerServiceServer).ExchangePeers(ctx, req.(*PeerExchangeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PeerService_GetBitfield_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BitfieldRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PeerServiceServer).GetBitfield(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PeerService_GetBitfield_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PeerServiceServer).GetBitfield(ctx, req.(*BitfieldRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // PeerService_ServiceDesc is the grpc.ServiceDesc for PeerService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -557,6 +953,438 @@ var PeerService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "DownloadThisFile",
 			Handler:    _PeerService_DownloadThisFile_Handler,
 		},
+		{
+			MethodName: "DropFile",
+			Handler:    _PeerService_DropFile_Handler,
+		},
+		{
+			MethodName: "StoreShard",
+			Handler:    _PeerService_StoreShard_Handler,
+		},
+		{
+			MethodName: "Search",
+			Handler:    _PeerService_Search_Handler,
+		},
+		{
+			MethodName: "ExchangePeers",
+			Handler:    _PeerService_ExchangePeers_Handler,
+		},
+		{
+			MethodName: "GetBitfield",
+			Handler:    _PeerService_GetBitfield_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "napster.proto",
+}
+
+const (
+	DHT_Ping_FullMethodName      = "/napster.DHT/Ping"
+	DHT_FindNode_FullMethodName  = "/napster.DHT/FindNode"
+	DHT_FindValue_FullMethodName = "/napster.DHT/FindValue"
+	DHT_Store_FullMethodName     = "/napster.DHT/Store"
+)
+
+// DHTClient is the client API for DHT service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// DHT is a Kademlia distributed hash table run by every peer, so peers can
+// find torrents and seeders while no central server is reachable (see
+// client/dht.go). A node's ID is the SHA-1 of its address; every request
+// names the sender so the receiver can add it to its routing table.
+type DHTClient interface {
+	Ping(ctx context.Context, in *DHTPingRequest, opts ...grpc.CallOption) (*DHTPingResponse, error)
+	FindNode(ctx context.Context, in *FindNodeRequest, opts ...grpc.CallOption) (*FindNodeResponse, error)
+	FindValue(ctx context.Context, in *FindValueRequest, opts ...grpc.CallOption) (*FindValueResponse, error)
+	Store(ctx context.Context, in *StoreRequest, opts ...grpc.CallOption) (*StoreResponse, error)
+}
+
+type dHTClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewDHTClient(cc grpc.ClientConnInterface) DHTClient {
+	return &dHTClient{cc}
+}
+
+func (c *dHTClient) Ping(ctx context.Context, in *DHTPingRequest, opts ...grpc.CallOption) (*DHTPingResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DHTPingResponse)
+	err := c.cc.Invoke(ctx, DHT_Ping_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dHTClient) FindNode(ctx context.Context, in *FindNodeRequest, opts ...grpc.CallOption) (*FindNodeResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(FindNodeResponse)
+	err := c.cc.Invoke(ctx, DHT_FindNode_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dHTClient) FindValue(ctx context.Context, in *FindValueRequest, opts ...grpc.CallOption) (*FindValueResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(FindValueResponse)
+	err := c.cc.Invoke(ctx, DHT_FindValue_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dHTClient) Store(ctx context.Context, in *StoreRequest, opts ...grpc.CallOption) (*StoreResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(StoreResponse)
+	err := c.cc.Invoke(ctx, DHT_Store_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// DHTServer is the server API for DHT service.
+// All implementations must embed UnimplementedDHTServer
+// for forward compatibility.
+//
+// DHT is a Kademlia distributed hash table run by every peer, so peers can
+// find torrents and seeders while no central server is reachable (see
+// client/dht.go). A node's ID is the SHA-1 of its address; every request
+// names the sender so the receiver can add it to its routing table.
+type DHTServer interface {
+	Ping(context.Context, *DHTPingRequest) (*DHTPingResponse, error)
+	FindNode(context.Context, *FindNodeRequest) (*FindNodeResponse, error)
+	FindValue(context.Context, *FindValueRequest) (*FindValueResponse, error)
+	Store(context.Context, *StoreRequest) (*StoreResponse, error)
+	mustEmbedUnimplementedDHTServer()
+}
+
+// UnimplementedDHTServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedDHTServer struct{}
+
+func (UnimplementedDHTServer) Ping(context.Context, *DHTPingRequest) (*DHTPingResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Ping not implemented")
+}
+func (UnimplementedDHTServer) FindNode(context.Context, *FindNodeRequest) (*FindNodeResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method FindNode not implemented")
+}
+func (UnimplementedDHTServer) FindValue(context.Context, *FindValueRequest) (*FindValueResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method FindValue not implemented")
+}
+func (UnimplementedDHTServer) Store(context.Context, *StoreRequest) (*StoreResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Store not implemented")
+}
+func (UnimplementedDHTServer) mustEmbedUnimplementedDHTServer() {}
+func (UnimplementedDHTServer) testEmbeddedByValue()             {}
+
+// UnsafeDHTServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to DHTServer will
+// result in compilation errors.
+type UnsafeDHTServer interface {
+	mustEmbedUnimplementedDHTServer()
+}
+
+func RegisterDHTServer(s grpc.ServiceRegistrar, srv DHTServer) {
+	// If the following call pancis, it indicates UnimplementedDHTServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&DHT_ServiceDesc, srv)
+}
+
+func _DHT_Ping_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DHTPingRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DHTServer).Ping(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DHT_Ping_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DHTServer).Ping(ctx, req.(*DHTPingRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DHT_FindNode_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(FindNodeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DHTServer).FindNode(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DHT_FindNode_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DHTServer).FindNode(ctx, req.(*FindNodeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DHT_FindValue_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(FindValueRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DHTServer).FindValue(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DHT_FindValue_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DHTServer).FindValue(ctx, req.(*FindValueRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DHT_Store_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(StoreRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DHTServer).Store(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DHT_Store_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DHTServer).Store(ctx, req.(*StoreRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// DHT_ServiceDesc is the grpc.ServiceDesc for DHT service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var DHT_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "napster.DHT",
+	HandlerType: (*DHTServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Ping",
+			Handler:    _DHT_Ping_Handler,
+		},
+		{
+			MethodName: "FindNode",
+			Handler:    _DHT_FindNode_Handler,
+		},
+		{
+			MethodName: "FindValue",
+			Handler:    _DHT_FindValue_Handler,
+		},
+		{
+			MethodName: "Store",
+			Handler:    _DHT_Store_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "napster.proto",
+}
+
+const (
+	Consensus_RequestVote_FullMethodName     = "/napster.Consensus/RequestVote"
+	Consensus_AppendEntries_FullMethodName   = "/napster.Consensus/AppendEntries"
+	Consensus_InstallSnapshot_FullMethodName = "/napster.Consensus/InstallSnapshot"
+)
+
+// ConsensusClient is the client API for Consensus service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// Consensus replicates the file index between central servers. One of them
+// is elected leader and appends every index change to a log that the others
+// copy before it is applied (see server/raft.go).
+type ConsensusClient interface {
+	RequestVote(ctx context.Context, in *VoteRequest, opts ...grpc.CallOption) (*VoteResponse, error)
+	AppendEntries(ctx context.Context, in *AppendEntriesRequest, opts ...grpc.CallOption) (*AppendEntriesResponse, error)
+	InstallSnapshot(ctx context.Context, in *InstallSnapshotRequest, opts ...grpc.CallOption) (*InstallSnapshotResponse, error)
+}
+
+type consensusClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewConsensusClient(cc grpc.ClientConnInterface) ConsensusClient {
+	return &consensusClient{cc}
+}
+
+func (c *consensusClient) RequestVote(ctx context.Context, in *VoteRequest, opts ...grpc.CallOption) (*VoteResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(VoteResponse)
+	err := c.cc.Invoke(ctx, Consensus_RequestVote_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *consensusClient) AppendEntries(ctx context.Context, in *AppendEntriesRequest, opts ...grpc.CallOption) (*AppendEntriesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(AppendEntriesResponse)
+	err := c.cc.Invoke(ctx, Consensus_AppendEntries_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *consensusClient) InstallSnapshot(ctx context.Context, in *InstallSnapshotRequest, opts ...grpc.CallOption) (*InstallSnapshotResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(InstallSnapshotResponse)
+	err := c.cc.Invoke(ctx, Consensus_InstallSnapshot_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ConsensusServer is the server API for Consensus service.
+// All implementations must embed UnimplementedConsensusServer
+// for forward compatibility.
+//
+// Consensus replicates the file index between central servers. One of them
+// is elected leader and appends every index change to a log that the others
+// copy before it is applied (see server/raft.go).
+type ConsensusServer interface {
+	RequestVote(context.Context, *VoteRequest) (*VoteResponse, error)
+	AppendEntries(context.Context, *AppendEntriesRequest) (*AppendEntriesResponse, error)
+	InstallSnapshot(context.Context, *InstallSnapshotRequest) (*InstallSnapshotResponse, error)
+	mustEmbedUnimplementedConsensusServer()
+}
+
+// UnimplementedConsensusServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedConsensusServer struct{}
+
+func (UnimplementedConsensusServer) RequestVote(context.Context, *VoteRequest) (*VoteResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RequestVote not implemented")
+}
+func (UnimplementedConsensusServer) AppendEntries(context.Context, *AppendEntriesRequest) (*AppendEntriesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AppendEntries not implemented")
+}
+func (UnimplementedConsensusServer) InstallSnapshot(context.Context, *InstallSnapshotRequest) (*InstallSnapshotResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method InstallSnapshot not implemented")
+}
+func (UnimplementedConsensusServer) mustEmbedUnimplementedConsensusServer() {}
+func (UnimplementedConsensusServer) testEmbeddedByValue()                   {}
+
+// UnsafeConsensusServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to ConsensusServer will
+// result in compilation errors.
+type UnsafeConsensusServer interface {
+	mustEmbedUnimplementedConsensusServer()
+}
+
+func RegisterConsensusServer(s grpc.ServiceRegistrar, srv ConsensusServer) {
+	// If the following call pancis, it indicates UnimplementedConsensusServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&Consensus_ServiceDesc, srv)
+}
+
+func _Consensus_RequestVote_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(VoteRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ConsensusServer).RequestVote(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Consensus_RequestVote_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ConsensusServer).RequestVote(ctx, req.(*VoteRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Consensus_AppendEntries_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AppendEntriesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ConsensusServer).AppendEntries(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Consensus_AppendEntries_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ConsensusServer).AppendEntries(ctx, req.(*AppendEntriesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Consensus_InstallSnapshot_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(InstallSnapshotRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ConsensusServer).InstallSnapshot(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Consensus_InstallSnapshot_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ConsensusServer).InstallSnapshot(ctx, req.(*InstallSnapshotRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// Consensus_ServiceDesc is the grpc.ServiceDesc for Consensus service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Consensus_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "napster.Consensus",
+	HandlerType: (*ConsensusServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "RequestVote",
+			Handler:    _Consensus_RequestVote_Handler,
+		},
+		{
+			MethodName: "AppendEntries",
+			Handler:    _Consensus_AppendEntries_Handler,
+		},
+		{
+			MethodName: "InstallSnapshot",
+			Handler:    _Consensus_InstallSnapshot_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "napster.proto",
@@ -0,0 +1,367 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	pb "napster"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// indexCommand is a change to the file index. In a cluster it is the command
+// carried by the consensus log, so every server applies the same changes in
+// the same order.
+type indexCommand struct {
+	Op      string           `json:"op"`                // see applyCommand
+	Entry   IndexEntry       `json:"entry"`             // the entry put; only FileName for other ops
+	Torrent *TorrentMetadata `json:"torrent,omitempty"` // new torrent contents, nil if unchanged
+	Peer    string           `json:"peer,omitempty"`    // peer, contributor or shard holder changed
+	Shard   int              `json:"shard,omitempty"`
+}
+
+// followerMethods are the calls any server of a cluster answers from its copy
+// of the index. Everything else changes the index or the leases, which only
+// the leader holds, and is refused by followers.
+var followerMethods = map[string]bool{
+	pb.CentralServer_SearchFile_FullMethodName:        true,
+	pb.CentralServer_RankedSearch_FullMethodName:      true,
+	pb.CentralServer_GetTorrent_FullMethodName:        true,
+	pb.CentralServer_HealthCheckServer_FullMethodName: true,
+}
+
+// commit applies a change to the index. In a cluster the change goes through
+// the consensus log and commit returns once it is applied here. The change is
+// applied under s.mu, so callers must not hold it: waiting for the cluster
+// would stall every search and heartbeat.
+func (s *CentralServer) commit(cmd indexCommand) error {
+	if s.raft == nil {
+		return s.applyCommand(cmd)
+	}
+	data, err := json.Marshal(cmd)
+	if err != nil {
+		return err
+	}
+	return s.raft.Propose(data)
+}
+
+// applyCommand writes a change to the store, the torrent and the search
+// index. The ops are:
+//
+//	put                                  replace the entry and its torrent
+//	del                                  remove the entry
+//	add-peer, remove-peer                change the seeders, in the torrent too
+//	add-contributor, remove-contributor  change the contributors
+//	set-shard                            set a shard's holder, in the torrent too
+//
+// All but put and del change the entry as it is when they are applied, so
+// changes proposed at the same time do not overwrite each other.
+func (s *CentralServer) applyCommand(cmd indexCommand) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch cmd.Op {
+	case "put":
+		return s.putEntry(cmd.Entry, cmd.Torrent)
+	case "del":
+		if err := s.store.Delete(cmd.Entry.FileName); err != nil {
+			return err
+		}
+		s.searchIndex.Remove(cmd.Entry.FileName)
+		s.forgetChecksum(cmd.Entry.FileName)
+		return nil
+	}
+
+	entry, exists := s.store.Get(cmd.Entry.FileName)
+	if !exists {
+		return os.ErrNotExist
+	}
+	torrentChanged := true
+	switch cmd.Op {
+	case "add-peer":
+		if containsString(entry.Peers, cmd.Peer) {
+			return nil
+		}
+		entry.Peers = append(entry.Peers, cmd.Peer)
+	case "remove-peer":
+		if !containsString(entry.Peers, cmd.Peer) {
+			return nil
+		}
+		entry.Peers = removeString(entry.Peers, cmd.Peer)
+	case "add-contributor":
+		if containsString(entry.Contributors, cmd.Peer) {
+			return nil
+		}
+		entry.Contributors = append(entry.Contributors, cmd.Peer)
+		torrentChanged = false
+	case "remove-contributor":
+		if !containsString(entry.Contributors, cmd.Peer) {
+			return nil
+		}
+		entry.Contributors = removeString(entry.Contributors, cmd.Peer)
+		torrentChanged = false
+	case "set-shard":
+		if cmd.Shard < 0 || cmd.Shard >= len(entry.Shards) {
+			return fmt.Errorf("no shard %d of %s", cmd.Shard, entry.FileName)
+		}
+		entry.Shards[cmd.Shard] = cmd.Peer
+	default:
+		return fmt.Errorf("unknown index command %q", cmd.Op)
+	}
+	if !torrentChanged {
+		return s.putEntry(entry, nil)
+	}
+
+	metadata, err := readTorrent(entry.TorrentFile)
+	if err != nil {
+		return err
+	}
+	metadata.Peers = entry.Peers
+	if metadata.Erasure != nil {
+		metadata.Erasure.Holders = entry.Shards
+	}
+	return s.putEntry(entry, &metadata)
+}
+
+// putEntry stores an entry and, when given, its torrent, and updates the
+// search index and the checksums. Callers must hold s.mu.
+func (s *CentralServer) putEntry(entry IndexEntry, torrent *TorrentMetadata) error {
+	if err := s.store.Put(entry); err != nil {
+		return err
+	}
+	if torrent == nil {
+		return nil
+	}
+	if err := writeTorrent(entry.TorrentFile, torrent); err != nil {
+		return err
+	}
+	if _, indexed := s.searchIndex.Get(entry.FileName); indexed {
+		s.searchIndex.SetPeers(entry.FileName, torrent.Peers)
+	} else {
+		s.searchIndex.Put(indexedSong(torrent))
+	}
+	s.checksumsMu.Lock()
+	if _, exists := s.checksums[torrent.Checksum]; !exists {
+		s.checksums[torrent.Checksum] = entry.FileName
+	}
+	s.checksumsMu.Unlock()
+	return nil
+}
+
+// forgetChecksum stops deduplicating uploads into fileName once it is no
+// longer indexed.
+func (s *CentralServer) forgetChecksum(fileName string) {
+	s.checksumsMu.Lock()
+	defer s.checksumsMu.Unlock()
+	for checksum, canonical := range s.checksums {
+		if canonical == fileName {
+			delete(s.checksums, checksum)
+		}
+	}
+}
+
+// changeEntry commits op for peer on fileName unless the index already
+// reflects it. The check is only a shortcut; the op is applied to the entry
+// as it is when it commits.
+func (s *CentralServer) changeEntry(op string, fileName string, peer string, done func(IndexEntry) bool) error {
+	entry, exists := s.store.Get(fileName)
+	if !exists {
+		return os.ErrNotExist
+	}
+	if done(entry) {
+		return nil
+	}
+	return s.commit(indexCommand{Op: op, Entry: IndexEntry{FileName: fileName}, Peer: peer})
+}
+
+// addPeer lists peer as a seeder of fileName.
+func (s *CentralServer) addPeer(fileName string, peer string) error {
+	return s.changeEntry("add-peer", fileName, peer, func(e IndexEntry) bool { return containsString(e.Peers, peer) })
+}
+
+// removePeer takes peer off the seeders of fileName.
+func (s *CentralServer) removePeer(fileName string, peer string) error {
+	return s.changeEntry("remove-peer", fileName, peer, func(e IndexEntry) bool { return !containsString(e.Peers, peer) })
+}
+
+// applyReplicated is the consensus log's apply callback.
+func (s *CentralServer) applyReplicated(data []byte) {
+	var cmd indexCommand
+	if err := json.Unmarshal(data, &cmd); err != nil {
+		log.Printf("Skipping undecodable index command: %v", err)
+		return
+	}
+	if err := s.applyCommand(cmd); err != nil {
+		log.Printf("Failed to apply %s of %s: %v", cmd.Op, cmd.Entry.FileName, err)
+	}
+}
+
+// indexSnapshot is the index as the consensus log snapshots it.
+type indexSnapshot struct {
+	Entries  []IndexEntry               `json:"entries"`
+	Torrents map[string]TorrentMetadata `json:"torrents"` // by torrent file
+}
+
+// snapshotIndex encodes every entry and its torrent.
+func (s *CentralServer) snapshotIndex() ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	snapshot := indexSnapshot{Entries: s.store.All(), Torrents: make(map[string]TorrentMetadata)}
+	for _, entry := range snapshot.Entries {
+		metadata, err := readTorrent(entry.TorrentFile)
+		if err != nil {
+			return nil, err
+		}
+		snapshot.Torrents[entry.TorrentFile] = metadata
+	}
+	return json.Marshal(snapshot)
+}
+
+// restoreIndex replaces the store, the torrents, the search index and the
+// checksums with a snapshot. Torrents of files the snapshot lacks are
+// removed, so RebuildIndex does not bring them back.
+func (s *CentralServer) restoreIndex(data []byte) error {
+	var snapshot indexSnapshot
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	old := s.store.All()
+	if err := s.store.Reset(snapshot.Entries); err != nil {
+		return err
+	}
+	kept := make(map[string]bool)
+	checksums := make(map[string]string)
+	for _, entry := range snapshot.Entries {
+		metadata := snapshot.Torrents[entry.TorrentFile]
+		if err := writeTorrent(entry.TorrentFile, &metadata); err != nil {
+			return err
+		}
+		kept[entry.FileName] = true
+		s.searchIndex.Put(indexedSong(&metadata))
+		if _, exists := checksums[metadata.Checksum]; !exists {
+			checksums[metadata.Checksum] = entry.FileName
+		}
+	}
+	for _, entry := range old {
+		if !kept[entry.FileName] {
+			s.searchIndex.Remove(entry.FileName)
+			os.Remove(filepath.Join(TORRENTS_DIR, entry.TorrentFile))
+		}
+	}
+	s.checksumsMu.Lock()
+	s.checksums = checksums
+	s.checksumsMu.Unlock()
+	return nil
+}
+
+// isLeader reports whether this server may change the index. A server
+// running alone always may.
+func (s *CentralServer) isLeader() bool {
+	return s.raft == nil || s.raft.IsLeader()
+}
+
+// leadershipChanged resets the state only the leader keeps. A new leader
+// starts a lease for every peer listed in the index, so peers that went away
+// with the old leader expire as usual while live ones simply heartbeat on. A
+// former leader forgets its leases and contributors; they move to the new one.
+func (s *CentralServer) leadershipChanged(leading bool) {
+	if leading {
+		expires := time.Now().Add(leaseDuration)
+		s.mu.Lock()
+		for _, entry := range s.store.All() {
+			for _, peer := range entry.Peers {
+				lease, exists := s.peerStatus[peer]
+				if !exists {
+					lease = &PeerLease{Expires: expires, Alive: true}
+					s.peerStatus[peer] = lease
+				}
+				if !containsString(lease.Files, entry.FileName) {
+					lease.Files = append(lease.Files, entry.FileName)
+				}
+			}
+		}
+		s.mu.Unlock()
+		log.Printf("Leading the cluster, %d peers on probation", len(s.peerStatus))
+		s.replication.Kick()
+		return
+	}
+
+	s.mu.Lock()
+	s.peerStatus = make(map[string]*PeerLease)
+	for addr, node := range s.cNodes {
+		s.ContributorHashring.Remove(addr)
+		node.conn.Close()
+	}
+	s.cNodes = make(map[string]*Contributor)
+	s.mu.Unlock()
+	log.Printf("No longer leading the cluster")
+}
+
+// notLeader is the error followers return for calls only the leader serves.
+func (s *CentralServer) notLeader() error {
+	if leaderAddr := s.raft.Leader(); leaderAddr != "" {
+		return status.Errorf(codes.Unavailable, "not the leader, try %s", leaderAddr)
+	}
+	return status.Error(codes.Unavailable, "no leader elected yet")
+}
+
+// leaderUnaryInterceptor refuses index changes on followers.
+func (s *CentralServer) leaderUnaryInterceptor(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	if strings.HasPrefix(info.FullMethod, "/napster.CentralServer/") && !followerMethods[info.FullMethod] && !s.isLeader() {
+		return nil, s.notLeader()
+	}
+	return handler(ctx, req)
+}
+
+// leaderStreamInterceptor refuses uploads on followers.
+func (s *CentralServer) leaderStreamInterceptor(srv any, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	if strings.HasPrefix(info.FullMethod, "/napster.CentralServer/") && !s.isLeader() {
+		return s.notLeader()
+	}
+	return handler(srv, stream)
+}
+
+// HealthCheckServer tells clients whether this server is up and which server
+// of the cluster accepts index changes.
+func (s *CentralServer) HealthCheckServer(ctx context.Context, req *pb.HealthCheckRequest) (*pb.HealthCheckResponse, error) {
+	res := &pb.HealthCheckResponse{Alive: true, Leader: s.isLeader()}
+	if s.raft != nil {
+		res.LeaderAddress = s.raft.Leader()
+	}
+	return res, nil
+}
+
+// parseCluster splits the -cluster flag into the addresses of the other
+// servers. self must be one of the listed addresses.
+func parseCluster(spec string, self string) ([]string, error) {
+	var peers []string
+	found := false
+	for _, addr := range strings.Split(spec, ",") {
+		addr = strings.TrimSpace(addr)
+		if addr == "" {
+			continue
+		}
+		if addr == self {
+			found = true
+		} else if !containsString(peers, addr) {
+			peers = append(peers, addr)
+		}
+	}
+	if !found {
+		return nil, fmt.Errorf("-cluster does not list this server's address %s (set -advertise)", self)
+	}
+	return peers, nil
+}
@@ -0,0 +1,204 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"sync"
+	"testing"
+)
+
+// newIndexTestServer runs a server alone with one erasure-coded file indexed.
+func newIndexTestServer(t *testing.T) *CentralServer {
+	t.Helper()
+	oldTorrents := TORRENTS_DIR
+	TORRENTS_DIR = t.TempDir()
+	t.Cleanup(func() { TORRENTS_DIR = oldTorrents })
+
+	store := openTestStore(t, t.TempDir())
+	t.Cleanup(func() { store.Close() })
+	s := NewCentralServer(store)
+
+	metadata := TorrentMetadata{
+		FileName: "song.mp3",
+		Checksum: "abc",
+		Peers:    []string{"uploader"},
+		Erasure:  &ErasureLayout{DataShards: 2, ParityShards: 1, Holders: []string{"", "", ""}},
+	}
+	entry := IndexEntry{FileName: "song.mp3", TorrentFile: torrentName("song.mp3"), Peers: metadata.Peers, Shards: metadata.Erasure.Holders, DataShards: 2}
+	if err := s.commit(indexCommand{Op: "put", Entry: entry, Torrent: &metadata}); err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func TestApplyCommand(t *testing.T) {
+	tests := []struct {
+		name         string
+		cmds         []indexCommand
+		peers        []string
+		contributors []string
+		shards       []string
+	}{
+		{
+			name:  "add peer",
+			cmds:  []indexCommand{{Op: "add-peer", Peer: "p1"}, {Op: "add-peer", Peer: "p1"}},
+			peers: []string{"uploader", "p1"},
+		},
+		{
+			name:  "remove peer",
+			cmds:  []indexCommand{{Op: "remove-peer", Peer: "uploader"}, {Op: "remove-peer", Peer: "nobody"}},
+			peers: []string{},
+		},
+		{
+			name:         "contributors",
+			cmds:         []indexCommand{{Op: "add-contributor", Peer: "c1"}, {Op: "add-contributor", Peer: "c2"}, {Op: "remove-contributor", Peer: "c1"}},
+			contributors: []string{"c2"},
+		},
+		{
+			name:   "shards",
+			cmds:   []indexCommand{{Op: "set-shard", Shard: 2, Peer: "c1"}, {Op: "set-shard", Shard: 0, Peer: "c2"}, {Op: "set-shard", Shard: 2, Peer: ""}},
+			shards: []string{"c2", "", ""},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newIndexTestServer(t)
+			for _, cmd := range tt.cmds {
+				cmd.Entry.FileName = "song.mp3"
+				if err := s.commit(cmd); err != nil {
+					t.Fatalf("%s %s: %v", cmd.Op, cmd.Peer, err)
+				}
+			}
+
+			entry, _ := s.store.Get("song.mp3")
+			metadata, err := readTorrent(entry.TorrentFile)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.peers == nil {
+				tt.peers = []string{"uploader"}
+			}
+			if !slices.Equal(entry.Peers, tt.peers) || !slices.Equal(metadata.Peers, tt.peers) {
+				t.Errorf("peers = %v, torrent %v, want %v", entry.Peers, metadata.Peers, tt.peers)
+			}
+			if !slices.Equal(entry.Contributors, tt.contributors) {
+				t.Errorf("contributors = %v, want %v", entry.Contributors, tt.contributors)
+			}
+			if tt.shards == nil {
+				tt.shards = []string{"", "", ""}
+			}
+			if !slices.Equal(entry.Shards, tt.shards) || !slices.Equal(metadata.Erasure.Holders, tt.shards) {
+				t.Errorf("shards = %v, torrent %v, want %v", entry.Shards, metadata.Erasure.Holders, tt.shards)
+			}
+		})
+	}
+}
+
+func TestApplyCommandErrors(t *testing.T) {
+	s := newIndexTestServer(t)
+	tests := []indexCommand{
+		{Op: "add-peer", Entry: IndexEntry{FileName: "missing.mp3"}, Peer: "p1"},
+		{Op: "set-shard", Entry: IndexEntry{FileName: "song.mp3"}, Shard: 3, Peer: "c1"},
+		{Op: "rename", Entry: IndexEntry{FileName: "song.mp3"}},
+	}
+	for _, cmd := range tests {
+		if err := s.commit(cmd); err == nil {
+			t.Errorf("%s of %s succeeded", cmd.Op, cmd.Entry.FileName)
+		}
+	}
+	if err := s.addPeer("missing.mp3", "p1"); !os.IsNotExist(err) {
+		t.Errorf("addPeer of a missing file: err = %v, want not exist", err)
+	}
+}
+
+// Deleting a file stops uploads of its content from being deduplicated into it.
+func TestDeleteForgetsChecksum(t *testing.T) {
+	s := newIndexTestServer(t)
+	if _, ok := s.deduplicate("abc", "second"); !ok {
+		t.Fatal("upload of indexed content not deduplicated")
+	}
+	if err := s.commit(indexCommand{Op: "del", Entry: IndexEntry{FileName: "song.mp3"}}); err != nil {
+		t.Fatal(err)
+	}
+	if res, ok := s.deduplicate("abc", "third"); ok {
+		t.Errorf("upload deduplicated into deleted file: %+v", res)
+	}
+	s.checksumsMu.Lock()
+	defer s.checksumsMu.Unlock()
+	if len(s.checksums) != 0 {
+		t.Errorf("checksums = %v after delete", s.checksums)
+	}
+}
+
+// Changes proposed at the same time are applied to the entry as it is when
+// they commit, so none of them is lost.
+func TestConcurrentPeerChanges(t *testing.T) {
+	s := newIndexTestServer(t)
+	var wg sync.WaitGroup
+	var want []string
+	for i := range 20 {
+		peer := fmt.Sprintf("peer%d", i)
+		want = append(want, peer)
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			s.addPeer("song.mp3", peer)
+		}()
+		go func() {
+			defer wg.Done()
+			s.addContributor("song.mp3", peer)
+		}()
+	}
+	wg.Wait()
+
+	entry, _ := s.store.Get("song.mp3")
+	for _, peer := range want {
+		if !containsString(entry.Peers, peer) || !containsString(entry.Contributors, peer) {
+			t.Fatalf("%s lost: peers %v, contributors %v", peer, entry.Peers, entry.Contributors)
+		}
+	}
+}
+
+func TestIndexSnapshotRestore(t *testing.T) {
+	s := newIndexTestServer(t)
+	if err := s.addPeer("song.mp3", "p1"); err != nil {
+		t.Fatal(err)
+	}
+	snapshot, err := s.snapshotIndex()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A server that indexed other files ends up with exactly the snapshot.
+	other := newIndexTestServer(t)
+	stale := TorrentMetadata{FileName: "stale.mp3", Checksum: "def", Peers: []string{"p2"}}
+	if err := other.commit(indexCommand{Op: "put", Entry: IndexEntry{FileName: "stale.mp3", TorrentFile: torrentName("stale.mp3"), Peers: stale.Peers}, Torrent: &stale}); err != nil {
+		t.Fatal(err)
+	}
+	if err := other.restoreIndex(snapshot); err != nil {
+		t.Fatal(err)
+	}
+
+	if got, want := other.store.All(), s.store.All(); !reflect.DeepEqual(got, want) {
+		t.Errorf("store = %+v, want %+v", got, want)
+	}
+	metadata, err := readTorrent(torrentName("song.mp3"))
+	if err != nil || !slices.Equal(metadata.Peers, []string{"uploader", "p1"}) {
+		t.Errorf("torrent peers = %v (%v)", metadata.Peers, err)
+	}
+	if _, err := os.Stat(filepath.Join(TORRENTS_DIR, torrentName("stale.mp3"))); !os.IsNotExist(err) {
+		t.Errorf("torrent of a file missing from the snapshot kept: %v", err)
+	}
+	if _, indexed := other.searchIndex.Get("stale.mp3"); indexed {
+		t.Error("stale.mp3 still searchable")
+	}
+	if song, _ := other.searchIndex.Get("song.mp3"); !slices.Equal(song.Peers, []string{"uploader", "p1"}) {
+		t.Errorf("search index peers = %v", song.Peers)
+	}
+	if other.checksums["abc"] != "song.mp3" || other.checksums["def"] != "" {
+		t.Errorf("checksums = %v", other.checksums)
+	}
+}
@@ -0,0 +1,237 @@
+package main
+
+import (
+	"context"
+	"log"
+	"os"
+	"sort"
+	"time"
+
+	pb "napster"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// Contributor is a node that stores replicas for the swarm. It stays on the
+// hash ring while it renews its lease with ContributorHeartbeat.
+type Contributor struct {
+	Client        pb.PeerServiceClient
+	conn          *grpc.ClientConn
+	CapacityBytes int64 // 0 means unlimited
+	FreeBytes     int64
+	Expires       time.Time
+}
+
+// hasRoom reports whether the contributor can store size more bytes.
+func (c *Contributor) hasRoom(size int64) bool {
+	return c.CapacityBytes == 0 || c.FreeBytes >= size
+}
+
+// RegisterContributor adds a contributor to the hash ring. Registering again
+// only refreshes its capacity and lease.
+func (s *CentralServer) RegisterContributor(ctx context.Context, req *pb.ContributorRequest) (*pb.GenResponse, error) {
+	if req.ContriAddr == "" {
+		return &pb.GenResponse{Status: 400}, nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if node, exists := s.cNodes[req.ContriAddr]; exists {
+		node.renew(req)
+		return &pb.GenResponse{Status: 204}, nil
+	}
+
+	conn, err := grpc.NewClient(req.ContriAddr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return &pb.GenResponse{Status: 500}, nil
+	}
+
+	node := &Contributor{Client: pb.NewPeerServiceClient(conn), conn: conn}
+	node.renew(req)
+	s.cNodes[req.ContriAddr] = node
+	s.ContributorHashring.Add(req.ContriAddr)
+
+	log.Printf("Added Contributor %s (capacity %d, free %d)", req.ContriAddr, req.CapacityBytes, req.FreeBytes)
+	s.replication.Kick()
+
+	return &pb.GenResponse{Status: 200}, nil
+}
+
+// ContributorHeartbeat renews a contributor's lease and records its free
+// space. Unknown contributors are asked to register again.
+func (s *CentralServer) ContributorHeartbeat(ctx context.Context, req *pb.ContributorRequest) (*pb.HeartbeatResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	node, exists := s.cNodes[req.ContriAddr]
+	if !exists {
+		return &pb.HeartbeatResponse{Success: false, Reregister: true}, nil
+	}
+	node.renew(req)
+
+	return &pb.HeartbeatResponse{
+		Success:      true,
+		LeaseSeconds: int32(leaseDuration / time.Second),
+	}, nil
+}
+
+// DeregisterContributor takes a contributor off the ring. Its files are
+// re-replicated elsewhere; it keeps seeding them as a regular peer meanwhile,
+// so the new replicas can be fetched from it.
+func (s *CentralServer) DeregisterContributor(ctx context.Context, req *pb.ContributorRequest) (*pb.GenResponse, error) {
+	if !s.removeContributor(req.ContriAddr) {
+		return &pb.GenResponse{Status: 404}, nil
+	}
+	log.Printf("Contributor %s deregistered", req.ContriAddr)
+	return &pb.GenResponse{Status: 200}, nil
+}
+
+// ListContributors reports every contributor with its capacity and the
+// replicas it holds.
+func (s *CentralServer) ListContributors(ctx context.Context, req *pb.ListContributorsRequest) (*pb.ListContributorsResponse, error) {
+	s.mu.Lock()
+	infos := make(map[string]*pb.ContributorInfo, len(s.cNodes))
+	now := time.Now()
+	for addr, node := range s.cNodes {
+		infos[addr] = &pb.ContributorInfo{
+			Address:       addr,
+			CapacityBytes: node.CapacityBytes,
+			FreeBytes:     node.FreeBytes,
+			Alive:         now.Before(node.Expires),
+		}
+	}
+	s.mu.Unlock()
+
+	for _, entry := range s.store.All() {
+		song, _ := s.searchIndex.Get(entry.FileName)
+		stored := song.FileSize
+		if entry.DataShards > 0 {
+			stored = shardBytes(song.FileSize, entry.DataShards)
+		}
+		for _, contributor := range entry.Contributors {
+			if info, ok := infos[contributor]; ok {
+				info.FileCount++
+				info.StoredBytes += stored
+			}
+		}
+	}
+
+	res := &pb.ListContributorsResponse{}
+	for _, info := range infos {
+		res.Contributors = append(res.Contributors, info)
+	}
+	sort.Slice(res.Contributors, func(i, j int) bool {
+		return res.Contributors[i].Address < res.Contributors[j].Address
+	})
+	return res, nil
+}
+
+// contributorLiveness returns a snapshot of the contributors holding a
+// lease, as liveness does for peers. Contributors heartbeat apart from their
+// peer lease, if they hold one at all. Followers trust the index, from which
+// the leader removes evicted contributors.
+func (s *CentralServer) contributorLiveness() func(addr string) bool {
+	if !s.isLeader() {
+		return func(string) bool { return true }
+	}
+	live := s.liveContributors()
+	return func(addr string) bool {
+		_, alive := live[addr]
+		return alive
+	}
+}
+
+// MonitorContributors evicts contributors whose lease lapsed.
+func (s *CentralServer) MonitorContributors() {
+	for {
+		for _, addr := range s.evictExpiredContributors(time.Now()) {
+			log.Printf("Contributor %s is offline, evicted from the ring", addr)
+		}
+		time.Sleep(5 * time.Second)
+	}
+}
+
+// evictExpiredContributors removes the contributors whose lease lapsed
+// before now and returns them.
+func (s *CentralServer) evictExpiredContributors(now time.Time) []string {
+	var expired []string
+	s.mu.Lock()
+	for addr, node := range s.cNodes {
+		if now.After(node.Expires) {
+			expired = append(expired, addr)
+		}
+	}
+	s.mu.Unlock()
+
+	var evicted []string
+	for _, addr := range expired {
+		if s.removeContributor(addr) {
+			evicted = append(evicted, addr)
+		}
+	}
+	return evicted
+}
+
+// removeContributor drops a contributor from the ring, from the replica
+// lists of its files and from the shards it holds, then lets the replication
+// manager hand those files to the remaining contributors.
+func (s *CentralServer) removeContributor(addr string) bool {
+	s.mu.Lock()
+	node, exists := s.cNodes[addr]
+	if exists {
+		delete(s.cNodes, addr)
+		s.ContributorHashring.Remove(addr)
+		node.conn.Close()
+	}
+	s.mu.Unlock()
+
+	if !exists {
+		return false
+	}
+
+	for _, entry := range s.store.All() {
+		if containsString(entry.Contributors, addr) {
+			s.removeReplica(entry.FileName, addr)
+		}
+		for shard, holder := range entry.Shards {
+			if holder == addr {
+				if err := s.setShardHolder(entry.FileName, shard, ""); err != nil {
+					log.Printf("Failed to release shard %d of %s: %v", shard, entry.FileName, err)
+				}
+			}
+		}
+	}
+	s.replication.Kick()
+	return true
+}
+
+// removeReplica forgets that contributor holds a replica of fileName.
+func (s *CentralServer) removeReplica(fileName string, contributor string) {
+	err := s.changeEntry("remove-contributor", fileName, contributor, func(e IndexEntry) bool {
+		return !containsString(e.Contributors, contributor)
+	})
+	if err != nil && !os.IsNotExist(err) {
+		log.Printf("Failed to remove contributor %s from %s: %v", contributor, fileName, err)
+	}
+}
+
+// reserveSpace lowers a contributor's free space by size until its next
+// heartbeat reports the real figure, so one round does not overfill it.
+func (s *CentralServer) reserveSpace(addr string, size int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if node, exists := s.cNodes[addr]; exists && node.CapacityBytes > 0 {
+		node.FreeBytes = max(node.FreeBytes-size, 0)
+	}
+}
+
+// renew applies the capacity reported by the contributor and extends its
+// lease. Callers must hold s.mu.
+func (c *Contributor) renew(req *pb.ContributorRequest) {
+	c.CapacityBytes = req.CapacityBytes
+	c.FreeBytes = req.FreeBytes
+	c.Expires = time.Now().Add(leaseDuration)
+}
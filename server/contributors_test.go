@@ -0,0 +1,185 @@
+package main
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	pb "napster"
+)
+
+func TestRegisterContributor(t *testing.T) {
+	s := newReplicationTestServer(t, 0, 0)
+	ctx := context.Background()
+
+	if res, _ := s.RegisterContributor(ctx, &pb.ContributorRequest{}); res.Status != 400 {
+		t.Errorf("registering without an address: status %d", res.Status)
+	}
+	addFakeContributor(t, s, "c1", 5000)
+	if members := s.ContributorHashring.Members(); !slices.Equal(members, []string{"c1"}) {
+		t.Errorf("ring = %v, want [c1]", members)
+	}
+
+	// Registering again only refreshes capacity and lease.
+	s.mu.Lock()
+	node := s.cNodes["c1"]
+	node.Expires = time.Now()
+	s.mu.Unlock()
+	res, _ := s.RegisterContributor(ctx, &pb.ContributorRequest{ContriAddr: "c1", CapacityBytes: 8000, FreeBytes: 7000})
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if res.Status != 204 || s.cNodes["c1"] != node || node.CapacityBytes != 8000 || node.FreeBytes != 7000 {
+		t.Errorf("registering again: status %d, contributor %+v", res.Status, s.cNodes["c1"])
+	}
+	if time.Until(node.Expires) < leaseDuration-time.Second {
+		t.Errorf("lease not renewed: expires %v", node.Expires)
+	}
+}
+
+func TestContributorHeartbeat(t *testing.T) {
+	s := newReplicationTestServer(t, 0, 0)
+	ctx := context.Background()
+
+	res, _ := s.ContributorHeartbeat(ctx, &pb.ContributorRequest{ContriAddr: "c1"})
+	if res.Success || !res.Reregister {
+		t.Errorf("heartbeat of an unknown contributor: %+v", res)
+	}
+
+	addFakeContributor(t, s, "c1", 5000)
+	s.mu.Lock()
+	s.cNodes["c1"].Expires = time.Now()
+	s.mu.Unlock()
+	res, _ = s.ContributorHeartbeat(ctx, &pb.ContributorRequest{ContriAddr: "c1", CapacityBytes: 5000, FreeBytes: 1234})
+	if !res.Success || res.LeaseSeconds != int32(leaseDuration/time.Second) {
+		t.Errorf("heartbeat: %+v", res)
+	}
+	if _, alive := s.liveContributors()["c1"]; !alive {
+		t.Error("lease not renewed")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if free := s.cNodes["c1"].FreeBytes; free != 1234 {
+		t.Errorf("free bytes = %d, want the reported 1234", free)
+	}
+}
+
+// A contributor that leaves, by deregistering or by letting its lease
+// lapse, is taken off the ring and out of the replica lists and shards.
+func TestRemoveContributor(t *testing.T) {
+	tests := []struct {
+		name   string
+		remove func(s *CentralServer) bool
+	}{
+		{"deregister", func(s *CentralServer) bool {
+			res, _ := s.DeregisterContributor(context.Background(), &pb.ContributorRequest{ContriAddr: "c1"})
+			return res.Status == 200
+		}},
+		{"lease lapsed", func(s *CentralServer) bool {
+			s.mu.Lock()
+			s.cNodes["c1"].Expires = time.Now().Add(-time.Second)
+			s.mu.Unlock()
+			return slices.Equal(s.evictExpiredContributors(time.Now()), []string{"c1"})
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newIndexTestServer(t)
+			addFakeContributor(t, s, "c1", 0)
+			addFakeContributor(t, s, "c2", 0)
+			for _, cmd := range []indexCommand{
+				{Op: "add-contributor", Peer: "c1"},
+				{Op: "add-contributor", Peer: "c2"},
+				{Op: "set-shard", Shard: 0, Peer: "c1"},
+				{Op: "set-shard", Shard: 1, Peer: "c2"},
+			} {
+				cmd.Entry.FileName = "song.mp3"
+				if err := s.commit(cmd); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			if !tt.remove(s) {
+				t.Fatal("c1 not removed")
+			}
+			entry, _ := s.store.Get("song.mp3")
+			if !slices.Equal(entry.Contributors, []string{"c2"}) || !slices.Equal(entry.Shards, []string{"", "c2", ""}) {
+				t.Errorf("contributors %v, shards %v after removing c1", entry.Contributors, entry.Shards)
+			}
+			if members := s.ContributorHashring.Members(); !slices.Equal(members, []string{"c2"}) {
+				t.Errorf("ring = %v, want [c2]", members)
+			}
+			if s.removeContributor("c1") {
+				t.Error("c1 removed twice")
+			}
+			if evicted := s.evictExpiredContributors(time.Now()); len(evicted) != 0 {
+				t.Errorf("evicted %v holding a lease", evicted)
+			}
+		})
+	}
+
+	s := newReplicationTestServer(t, 0, 0)
+	if res, _ := s.DeregisterContributor(context.Background(), &pb.ContributorRequest{ContriAddr: "c1"}); res.Status != 404 {
+		t.Errorf("deregistering an unknown contributor: status %d", res.Status)
+	}
+}
+
+func TestListContributors(t *testing.T) {
+	s := newReplicationTestServer(t, 3, 1000)
+	addFakeContributor(t, s, "c1", 5000)
+	addFakeContributor(t, s, "c2", 0)
+	s.mu.Lock()
+	s.cNodes["c2"].Expires = time.Now().Add(-time.Second)
+	s.mu.Unlock()
+	for _, cmd := range []indexCommand{
+		{Op: "add-contributor", Entry: IndexEntry{FileName: "song00.mp3"}, Peer: "c1"},
+		{Op: "add-contributor", Entry: IndexEntry{FileName: "song01.mp3"}, Peer: "c1"},
+		{Op: "add-contributor", Entry: IndexEntry{FileName: "song01.mp3"}, Peer: "c2"},
+	} {
+		if err := s.commit(cmd); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	res, err := s.ListContributors(context.Background(), &pb.ListContributorsRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []*pb.ContributorInfo{
+		{Address: "c1", CapacityBytes: 5000, FreeBytes: 5000, Alive: true, FileCount: 2, StoredBytes: 2000},
+		{Address: "c2", FileCount: 1, StoredBytes: 1000},
+	}
+	if len(res.Contributors) != len(want) {
+		t.Fatalf("listed %v", res.Contributors)
+	}
+	for i, info := range res.Contributors {
+		w := want[i]
+		if info.Address != w.Address || info.CapacityBytes != w.CapacityBytes || info.FreeBytes != w.FreeBytes ||
+			info.Alive != w.Alive || info.FileCount != w.FileCount || info.StoredBytes != w.StoredBytes {
+			t.Errorf("contributor %d = %v, want %v", i, info, w)
+		}
+	}
+}
+
+func TestReserveSpace(t *testing.T) {
+	s := newReplicationTestServer(t, 0, 0)
+	addFakeContributor(t, s, "limited", 5000)
+	addFakeContributor(t, s, "unlimited", 0)
+
+	s.reserveSpace("limited", 2000)
+	s.reserveSpace("limited", 4000)
+	s.reserveSpace("unlimited", 4000)
+	s.reserveSpace("unknown", 4000)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if free := s.cNodes["limited"].FreeBytes; free != 0 {
+		t.Errorf("limited has %d bytes free, want 0", free)
+	}
+	if !s.cNodes["unlimited"].hasRoom(1 << 40) {
+		t.Error("unlimited contributor ran out of room")
+	}
+	if _, exists := s.cNodes["unknown"]; exists {
+		t.Error("reserving space registered a contributor")
+	}
+}
@@ -0,0 +1,205 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	pb "napster"
+	"napster/erasure"
+)
+
+// ErasureLayout describes how an erasure-coded file is spread over
+// contributors. Data chunks are grouped into stripes of DataShards chunks, the
+// last stripe padded with zero chunks, and every stripe gets ParityShards
+// parity chunks. Shard i is chunk i of every stripe, so data shard j holds the
+// data chunks stripe*DataShards+j and parity shard p holds the parity chunks
+// stripe*ParityShards+p. Holders[i] is the contributor storing shard i.
+type ErasureLayout struct {
+	DataShards      int            `json:"data_shards"`
+	ParityShards    int            `json:"parity_shards"`
+	ParityChecksums map[int]string `json:"parity_checksums"` // parity chunk number -> checksum
+	Holders         []string       `json:"holders"`
+}
+
+// parseErasureSpec parses the -erasure flag, e.g. "4+2". An empty spec turns
+// erasure coding off.
+func parseErasureSpec(spec string) (int, int, error) {
+	if spec == "" {
+		return 0, 0, nil
+	}
+	var k, m int
+	if _, err := fmt.Sscanf(spec, "%d+%d", &k, &m); err != nil {
+		return 0, 0, fmt.Errorf("invalid erasure layout %q, want k+m", spec)
+	}
+	if _, err := erasure.New(k, m); err != nil {
+		return 0, 0, err
+	}
+	return k, m, nil
+}
+
+// shardBytes is the size of one shard of a file: one chunk per stripe.
+func shardBytes(fileSize int64, dataShards int) int64 {
+	chunks := (fileSize + ChunkSize - 1) / ChunkSize
+	stripes := (chunks + int64(dataShards) - 1) / int64(dataShards)
+	return stripes * ChunkSize
+}
+
+// stripeEncoder computes the parity checksums of a file while its chunks are
+// streamed in. The parity chunks themselves are rebuilt by the uploader, which
+// keeps the file, so the server never stores file data.
+type stripeEncoder struct {
+	coder  *erasure.Coder
+	stripe [][]byte
+	layout *ErasureLayout
+	count  int // stripes encoded so far
+}
+
+func newStripeEncoder(k, m int) (*stripeEncoder, error) {
+	coder, err := erasure.New(k, m)
+	if err != nil {
+		return nil, err
+	}
+	return &stripeEncoder{
+		coder: coder,
+		layout: &ErasureLayout{
+			DataShards:      k,
+			ParityShards:    m,
+			ParityChecksums: make(map[int]string),
+			Holders:         make([]string, k+m),
+		},
+	}, nil
+}
+
+// add appends the next data chunk, encoding the stripe once it is full.
+func (e *stripeEncoder) add(chunk []byte) error {
+	e.stripe = append(e.stripe, erasure.Pad(chunk, ChunkSize))
+	if len(e.stripe) < e.coder.DataShards {
+		return nil
+	}
+	return e.flush()
+}
+
+func (e *stripeEncoder) flush() error {
+	for len(e.stripe) < e.coder.DataShards {
+		e.stripe = append(e.stripe, make([]byte, ChunkSize))
+	}
+	parity, err := e.coder.Encode(e.stripe)
+	if err != nil {
+		return err
+	}
+	for p, chunk := range parity {
+		e.layout.ParityChecksums[e.count*e.coder.ParityShards+p] = computeDataChecksum(chunk)
+	}
+	e.count++
+	e.stripe = e.stripe[:0]
+	return nil
+}
+
+// finish encodes the last, partial stripe and returns the layout.
+func (e *stripeEncoder) finish() (*ErasureLayout, error) {
+	if len(e.stripe) > 0 {
+		if err := e.flush(); err != nil {
+			return nil, err
+		}
+	}
+	return e.layout, nil
+}
+
+// reconcileShards places every unplaced shard, or one whose holder is gone, on
+// a live contributor that holds no other shard of the file, walking the hash
+// ring from the file's position. Losing a contributor therefore costs at most
+// one shard, and downloads survive as long as DataShards shards remain.
+func (r *ReplicationManager) reconcileShards(entry IndexEntry, song IndexedSong, contributors map[string]*Contributor) {
+	ring := r.server.ContributorHashring
+	preferred, err := ring.GetN(entry.FileName, len(ring.Members()))
+	if err != nil {
+		return
+	}
+	size := shardBytes(song.FileSize, entry.DataShards)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	taken := make(map[string]bool)
+	for _, holder := range entry.Shards {
+		if _, alive := contributors[holder]; alive {
+			taken[holder] = true
+		}
+	}
+	for _, holder := range r.placing[entry.FileName] {
+		taken[holder] = true
+	}
+
+	for shard, holder := range entry.Shards {
+		if _, alive := contributors[holder]; alive {
+			continue
+		}
+		if _, placing := r.placing[entry.FileName][shard]; placing {
+			continue
+		}
+		if r.inFlight() >= maxReplicationsInFlight {
+			return
+		}
+		for _, candidate := range preferred {
+			node, alive := contributors[candidate]
+			if !alive || taken[candidate] || !node.hasRoom(size) {
+				continue
+			}
+			taken[candidate] = true
+			r.startPlacement(entry.FileName, shard, candidate, node, size)
+			break
+		}
+	}
+}
+
+// startPlacement reserves space on the contributor and sends it the shard in
+// the background. Callers must hold r.mu.
+func (r *ReplicationManager) startPlacement(fileName string, shard int, contributor string, node *Contributor, size int64) {
+	if r.placing[fileName] == nil {
+		r.placing[fileName] = make(map[int]string)
+	}
+	r.placing[fileName][shard] = contributor
+	node.FreeBytes -= size
+	r.server.reserveSpace(contributor, size)
+	go r.placeShard(fileName, shard, contributor, node.Client)
+}
+
+// placeShard asks contributor to store a shard and records it as the holder
+// once the contributor has every chunk of it.
+func (r *ReplicationManager) placeShard(fileName string, shard int, contributor string, client pb.PeerServiceClient) {
+	defer func() {
+		r.mu.Lock()
+		delete(r.placing[fileName], shard)
+		if len(r.placing[fileName]) == 0 {
+			delete(r.placing, fileName)
+		}
+		r.mu.Unlock()
+	}()
+
+	log.Printf("Placing shard %d of %s on %s", shard, fileName, contributor)
+	ctx, cancel := context.WithTimeout(context.Background(), replicationTimeout)
+	defer cancel()
+
+	resp, err := client.StoreShard(ctx, &pb.ShardRequest{FileName: fileName, Shard: int32(shard)})
+	if err != nil || resp.Status != 200 {
+		log.Printf("Placing shard %d of %s on %s failed: %v", shard, fileName, contributor, err)
+		return
+	}
+	if err := r.server.setShardHolder(fileName, shard, contributor); err != nil {
+		log.Printf("Failed to record shard %d of %s on %s: %v", shard, fileName, contributor, err)
+		return
+	}
+	r.server.addContributor(fileName, contributor)
+	log.Printf("Placed shard %d of %s on %s", shard, fileName, contributor)
+}
+
+// setShardHolder records the holder of a shard in the store and mirrors the
+// layout into the torrent file. An empty holder marks the shard as lost.
+func (s *CentralServer) setShardHolder(fileName string, shard int, holder string) error {
+	entry, exists := s.store.Get(fileName)
+	if !exists || shard < 0 || shard >= len(entry.Shards) {
+		return fmt.Errorf("no shard %d of %s", shard, fileName)
+	}
+	return s.commit(indexCommand{Op: "set-shard", Entry: IndexEntry{FileName: fileName}, Peer: holder, Shard: shard})
+}
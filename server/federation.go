@@ -0,0 +1,188 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	pb "napster"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+const (
+	defaultFederationHops = 2
+	federationTimeout     = 3 * time.Second        // longest a forwarded search may take
+	federationMargin      = 500 * time.Millisecond // left to merge before the caller gives up
+	seenQueryTTL          = time.Minute
+)
+
+// Federation forwards searches to the central servers of other, independent
+// indexes. Each forwarded query carries an ID, so a server reached along
+// several paths answers it only once, and a hop budget that bounds how far it
+// spreads.
+type Federation struct {
+	self    string // origin reported for this server's own results
+	maxHops int
+	addrs   []string
+	clients map[string]pb.CentralServerClient
+
+	mu   sync.Mutex
+	seen map[string]time.Time // query ID -> when it first arrived
+}
+
+// NewFederation connects to the federated servers at addrs.
+func NewFederation(self string, addrs []string, maxHops int) (*Federation, error) {
+	f := &Federation{
+		self:    self,
+		maxHops: maxHops,
+		clients: make(map[string]pb.CentralServerClient),
+		seen:    make(map[string]time.Time),
+	}
+	for _, addr := range addrs {
+		if addr == self {
+			continue
+		}
+		if _, exists := f.clients[addr]; exists {
+			continue
+		}
+		conn, err := grpc.NewClient(addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+		if err != nil {
+			return nil, fmt.Errorf("federated server %s: %v", addr, err)
+		}
+		f.addrs = append(f.addrs, addr)
+		f.clients[addr] = pb.NewCentralServerClient(conn)
+	}
+	return f, nil
+}
+
+// firstSeen records a query ID and reports whether it had not arrived before.
+func (f *Federation) firstSeen(queryID string) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	now := time.Now()
+	for id, at := range f.seen {
+		if now.Sub(at) > seenQueryTTL {
+			delete(f.seen, id)
+		}
+	}
+	if _, seen := f.seen[queryID]; seen {
+		return false
+	}
+	f.seen[queryID] = now
+	return true
+}
+
+// forward sends a search to every federated server at once and collects the
+// results of those that answer in time.
+func (f *Federation) forward(ctx context.Context, req *pb.SearchRequest) []*pb.SongInfo {
+	timeout := federationTimeout
+	if deadline, ok := ctx.Deadline(); ok {
+		timeout = min(timeout, time.Until(deadline)-federationMargin)
+	}
+	if timeout <= 0 {
+		return nil
+	}
+	fctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	var (
+		mu      sync.Mutex
+		results []*pb.SongInfo
+		wg      sync.WaitGroup
+	)
+	for _, addr := range f.addrs {
+		wg.Add(1)
+		go func(addr string) {
+			defer wg.Done()
+			res, err := f.clients[addr].SearchFile(fctx, req)
+			if err != nil {
+				if debug_mode {
+					log.Printf("Federated search on %s failed: %v", addr, err)
+				}
+				return
+			}
+			for _, song := range res.Results {
+				if song.Origin == "" {
+					song.Origin = addr
+				}
+			}
+			mu.Lock()
+			results = append(results, res.Results...)
+			mu.Unlock()
+		}(addr)
+	}
+	wg.Wait()
+	return results
+}
+
+// federatedResults adds the matches of the federated indexes to this
+// server's own, dropping songs that arrived along more than one path. A query
+// this server has answered before yields no results at all.
+func (s *CentralServer) federatedResults(ctx context.Context, req *pb.SearchRequest, local []*pb.SongInfo) []*pb.SongInfo {
+	f := s.federation
+	queryID, hops := req.QueryId, int(req.Hops)
+	if queryID == "" {
+		queryID, hops = newQueryID(), f.maxHops
+	}
+	if !f.firstSeen(queryID) {
+		return nil
+	}
+	for _, song := range local {
+		song.Origin = f.self
+	}
+	if hops = min(hops, f.maxHops); hops <= 0 || len(f.addrs) == 0 {
+		return local
+	}
+
+	remote := f.forward(ctx, &pb.SearchRequest{
+		Query:         req.Query,
+		OnlyAvailable: req.OnlyAvailable,
+		QueryId:       queryID,
+		Hops:          int32(hops - 1),
+		MaxResults:    req.MaxResults,
+	})
+
+	seen := make(map[string]bool)
+	merged := make([]*pb.SongInfo, 0, len(local)+len(remote))
+	for _, song := range append(local, remote...) {
+		key := song.Origin + "\x00" + song.FileName
+		if !seen[key] {
+			seen[key] = true
+			merged = append(merged, song)
+		}
+	}
+	sort.SliceStable(merged, func(i, j int) bool {
+		if merged[i].Score != merged[j].Score {
+			return merged[i].Score > merged[j].Score
+		}
+		return merged[i].LiveSeeders > merged[j].LiveSeeders
+	})
+	return merged
+}
+
+// newQueryID returns a random ID for a search entering the federation.
+func newQueryID() string {
+	id := make([]byte, 16)
+	rand.Read(id)
+	return hex.EncodeToString(id)
+}
+
+// parseFederation splits the -federate flag into server addresses.
+func parseFederation(spec string) []string {
+	var addrs []string
+	for _, addr := range strings.Split(spec, ",") {
+		if addr = strings.TrimSpace(addr); addr != "" {
+			addrs = append(addrs, addr)
+		}
+	}
+	return addrs
+}
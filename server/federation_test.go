@@ -0,0 +1,208 @@
+package main
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
+
+	pb "napster"
+)
+
+// federatedPeer forwards searches straight to another server's SearchFile,
+// counting the queries that arrive.
+type federatedPeer struct {
+	pb.CentralServerClient
+	server *CentralServer
+
+	mu      sync.Mutex
+	queries []*pb.SearchRequest
+}
+
+func (p *federatedPeer) SearchFile(ctx context.Context, in *pb.SearchRequest, opts ...grpc.CallOption) (*pb.SearchResponse, error) {
+	p.mu.Lock()
+	p.queries = append(p.queries, in)
+	p.mu.Unlock()
+	return p.server.SearchFile(ctx, in)
+}
+
+// newFederatedTestServer runs a server named self indexing songs, each
+// seeded by a live peer, federated with no one yet.
+func newFederatedTestServer(t *testing.T, self string, maxHops int, songs ...string) *CentralServer {
+	t.Helper()
+	store := openTestStore(t, t.TempDir())
+	t.Cleanup(func() { store.Close() })
+	s := NewCentralServer(store)
+	s.peerStatus["peer"] = &PeerLease{Alive: true, Expires: time.Now().Add(time.Hour)}
+	for _, name := range songs {
+		s.searchIndex.Put(IndexedSong{FileName: name, Peers: []string{"peer"}})
+		store.Put(IndexEntry{FileName: name, Peers: []string{"peer"}})
+	}
+	s.federation = &Federation{
+		self:    self,
+		maxHops: maxHops,
+		clients: make(map[string]pb.CentralServerClient),
+		seen:    make(map[string]time.Time),
+	}
+	return s
+}
+
+// federate lets from forward searches to each of to, by name.
+func federate(from *CentralServer, to map[string]*CentralServer) map[string]*federatedPeer {
+	peers := make(map[string]*federatedPeer)
+	for name, server := range to {
+		peers[name] = &federatedPeer{server: server}
+		from.federation.addrs = append(from.federation.addrs, name)
+		from.federation.clients[name] = peers[name]
+	}
+	slices.Sort(from.federation.addrs)
+	return peers
+}
+
+// originsOf lists the results as origin/file name.
+func originsOf(results []*pb.SongInfo) []string {
+	var names []string
+	for _, song := range results {
+		names = append(names, song.Origin+"/"+song.FileName)
+	}
+	slices.Sort(names)
+	return names
+}
+
+func TestFederatedSearchMesh(t *testing.T) {
+	a := newFederatedTestServer(t, "a", 2, "Yesterday.mp3")
+	b := newFederatedTestServer(t, "b", 2, "Yesterday.mp3")
+	c := newFederatedTestServer(t, "c", 2, "Yesterday Live.mp3")
+	var links []*federatedPeer
+	for _, peers := range []map[string]*federatedPeer{
+		federate(a, map[string]*CentralServer{"b": b, "c": c}),
+		federate(b, map[string]*CentralServer{"a": a, "c": c}),
+		federate(c, map[string]*CentralServer{"a": a, "b": b}),
+	} {
+		for _, peer := range peers {
+			links = append(links, peer)
+		}
+	}
+
+	res, err := a.SearchFile(context.Background(), &pb.SearchRequest{Query: "yesterday"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The same file name on two indexes is two songs; a song reached along
+	// several paths is one.
+	want := []string{"a/Yesterday.mp3", "b/Yesterday.mp3", "c/Yesterday Live.mp3"}
+	if got := originsOf(res.Results); !slices.Equal(got, want) {
+		t.Errorf("results = %v, want %v", got, want)
+	}
+
+	// Every forwarded copy carries the ID a gave the query and a hop budget
+	// lowered on each hop.
+	var queries []*pb.SearchRequest
+	for _, link := range links {
+		queries = append(queries, link.queries...)
+	}
+	if len(queries) < 2 {
+		t.Fatalf("query forwarded %d times", len(queries))
+	}
+	for _, query := range queries {
+		if query.QueryId != queries[0].QueryId || query.QueryId == "" || query.Hops > 1 {
+			t.Errorf("forwarded %v, first %v", query, queries[0])
+		}
+	}
+	// A query ID is answered once.
+	for _, s := range []*CentralServer{a, b, c} {
+		if again, _ := s.SearchFile(context.Background(), queries[0]); len(again.Results) != 0 {
+			t.Errorf("%s answered a seen query with %v", s.federation.self, originsOf(again.Results))
+		}
+	}
+}
+
+func TestFederatedSearchHopLimit(t *testing.T) {
+	chain := []*CentralServer{
+		newFederatedTestServer(t, "a", 2, "song a.mp3"),
+		newFederatedTestServer(t, "b", 5, "song b.mp3"),
+		newFederatedTestServer(t, "c", 5, "song c.mp3"),
+		newFederatedTestServer(t, "d", 5, "song d.mp3"),
+	}
+	var toD *federatedPeer
+	for i, name := range []string{"b", "c", "d"} {
+		toD = federate(chain[i], map[string]*CentralServer{name: chain[i+1]})[name]
+	}
+
+	// a forwards with its own budget of 2 hops, whatever the others allow.
+	res, _ := chain[0].SearchFile(context.Background(), &pb.SearchRequest{Query: "song"})
+	want := []string{"a/song a.mp3", "b/song b.mp3", "c/song c.mp3"}
+	if got := originsOf(res.Results); !slices.Equal(got, want) {
+		t.Errorf("results = %v, want %v", got, want)
+	}
+	if len(toD.queries) != 0 {
+		t.Errorf("query went %d hops past the limit", len(toD.queries))
+	}
+
+	// A server lowers a query's budget to its own.
+	chain[1].federation.maxHops = 1
+	res, _ = chain[1].SearchFile(context.Background(), &pb.SearchRequest{Query: "song", QueryId: "q", Hops: 5})
+	want = []string{"b/song b.mp3", "c/song c.mp3"}
+	if got := originsOf(res.Results); !slices.Equal(got, want) {
+		t.Errorf("results = %v, want %v", got, want)
+	}
+}
+
+// staticPeer answers every search with the same results.
+type staticPeer struct {
+	pb.CentralServerClient
+	results []*pb.SongInfo
+}
+
+func (p *staticPeer) SearchFile(ctx context.Context, in *pb.SearchRequest, opts ...grpc.CallOption) (*pb.SearchResponse, error) {
+	var results []*pb.SongInfo
+	for _, song := range p.results {
+		results = append(results, proto.Clone(song).(*pb.SongInfo))
+	}
+	return &pb.SearchResponse{Results: results}, nil
+}
+
+func TestFederatedResultsMerge(t *testing.T) {
+	s := newFederatedTestServer(t, "a", 2, "Yesterday.mp3")
+	s.federation.addrs = []string{"b"}
+	s.federation.clients["b"] = &staticPeer{results: []*pb.SongInfo{
+		{FileName: "Yesterday.mp3", Score: 5},              // b's own, origin filled in
+		{FileName: "Yesterday.mp3", Origin: "b", Score: 5}, // the same song again
+		{FileName: "Yesterday.mp3", Origin: "a", Score: 5}, // a's song, forwarded back
+		{FileName: "Yesterday Live.mp3", Origin: "c", Score: 9},
+	}}
+
+	res, _ := s.SearchFile(context.Background(), &pb.SearchRequest{Query: "yesterday"})
+	want := []string{"a/Yesterday.mp3", "b/Yesterday.mp3", "c/Yesterday Live.mp3"}
+	if got := originsOf(res.Results); !slices.Equal(got, want) {
+		t.Errorf("results = %v, want %v", got, want)
+	}
+	for i := 1; i < len(res.Results); i++ {
+		if res.Results[i].Score > res.Results[i-1].Score {
+			t.Errorf("result %d scores %v, above %v before it", i, res.Results[i].Score, res.Results[i-1].Score)
+		}
+	}
+}
+
+func TestFederationFirstSeen(t *testing.T) {
+	f := &Federation{seen: make(map[string]time.Time)}
+	if !f.firstSeen("q1") || f.firstSeen("q1") {
+		t.Fatal("query q1 not deduplicated")
+	}
+	if !f.firstSeen("q2") {
+		t.Fatal("query q2 taken for q1")
+	}
+
+	// IDs are forgotten after seenQueryTTL.
+	f.seen["q1"] = time.Now().Add(-seenQueryTTL - time.Second)
+	if !f.firstSeen("q1") {
+		t.Error("expired query ID still seen")
+	}
+	if _, kept := f.seen["q2"]; !kept || len(f.seen) != 2 {
+		t.Errorf("seen = %v", f.seen)
+	}
+}
@@ -0,0 +1,346 @@
+package main
+
+import (
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/lithammer/fuzzysearch/fuzzy"
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+// IndexedSong is the searchable view of a torrent kept in memory.
+type IndexedSong struct {
+	FileName   string
+	ArtistName string
+	CreatedAt  string
+	Duration   int64
+	FileSize   int64
+	Peers      []string
+}
+
+// SearchIndex is an inverted index from name/artist tokens to songs. A
+// query token is only compared with the tokens that share its first letters
+// or enough of its trigrams, found through two more indexes over the distinct
+// tokens, so a query does not scan the vocabulary, let alone the songs.
+type SearchIndex struct {
+	mu       sync.RWMutex
+	songs    map[string]*IndexedSong        // file name -> song
+	postings map[string]map[string]struct{} // token -> file names
+	prefixes map[string]map[string]struct{} // first shortPrefix letters or fewer -> tokens
+	trigrams map[string]map[string]struct{} // trigram -> tokens containing it
+}
+
+func NewSearchIndex() *SearchIndex {
+	return &SearchIndex{
+		songs:    make(map[string]*IndexedSong),
+		postings: make(map[string]map[string]struct{}),
+		prefixes: make(map[string]map[string]struct{}),
+		trigrams: make(map[string]map[string]struct{}),
+	}
+}
+
+// shortPrefix is the longest query token looked up by prefix alone; longer
+// ones are looked up by trigram.
+const shortPrefix = 2
+
+// tokenTrigrams returns the distinct runs of three letters in token.
+func tokenTrigrams(token string) []string {
+	letters := []rune(token)
+	var grams []string
+	for i := 0; i+3 <= len(letters); i++ {
+		gram := string(letters[i : i+3])
+		if !slices.Contains(grams, gram) {
+			grams = append(grams, gram)
+		}
+	}
+	return grams
+}
+
+// tokenPrefixes returns the prefixes of token up to shortPrefix letters.
+func tokenPrefixes(token string) []string {
+	letters := []rune(token)
+	var prefixes []string
+	for n := 1; n <= min(shortPrefix, len(letters)); n++ {
+		prefixes = append(prefixes, string(letters[:n]))
+	}
+	return prefixes
+}
+
+func addPosting(index map[string]map[string]struct{}, key string, value string) {
+	values, exists := index[key]
+	if !exists {
+		values = make(map[string]struct{})
+		index[key] = values
+	}
+	values[value] = struct{}{}
+}
+
+func removePosting(index map[string]map[string]struct{}, key string, value string) {
+	values := index[key]
+	delete(values, value)
+	if len(values) == 0 {
+		delete(index, key)
+	}
+}
+
+// foldDiacritics returns a transformer stripping accents, so "Beyoncé" and
+// "beyonce" share a token. Transformers keep state, so each call needs its own.
+func foldDiacritics() transform.Transformer {
+	return transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC)
+}
+
+// tokenize lowercases s, strips diacritics and splits it into letter/digit runs.
+func tokenize(s string) []string {
+	folded, _, err := transform.String(foldDiacritics(), strings.ToLower(s))
+	if err != nil {
+		folded = strings.ToLower(s)
+	}
+	return strings.FieldsFunc(folded, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// songTokens returns the distinct tokens of a song's name (without extension) and artist.
+func songTokens(song *IndexedSong) []string {
+	name := strings.TrimSuffix(song.FileName, filepath.Ext(song.FileName))
+	seen := make(map[string]bool)
+	var tokens []string
+	for _, token := range append(tokenize(name), tokenize(song.ArtistName)...) {
+		if !seen[token] {
+			seen[token] = true
+			tokens = append(tokens, token)
+		}
+	}
+	return tokens
+}
+
+// Put adds or replaces a song.
+func (idx *SearchIndex) Put(song IndexedSong) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	if old, exists := idx.songs[song.FileName]; exists {
+		idx.unlink(old)
+	}
+	song.Peers = append([]string(nil), song.Peers...)
+	idx.songs[song.FileName] = &song
+
+	for _, token := range songTokens(&song) {
+		if _, known := idx.postings[token]; !known {
+			for _, prefix := range tokenPrefixes(token) {
+				addPosting(idx.prefixes, prefix, token)
+			}
+			for _, gram := range tokenTrigrams(token) {
+				addPosting(idx.trigrams, gram, token)
+			}
+		}
+		addPosting(idx.postings, token, song.FileName)
+	}
+}
+
+// SetPeers replaces the peer list of an indexed song.
+func (idx *SearchIndex) SetPeers(fileName string, peers []string) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	if song, exists := idx.songs[fileName]; exists {
+		song.Peers = append([]string(nil), peers...)
+	}
+}
+
+// Get returns a copy of an indexed song.
+func (idx *SearchIndex) Get(fileName string) (IndexedSong, bool) {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	song, exists := idx.songs[fileName]
+	if !exists {
+		return IndexedSong{}, false
+	}
+	return *song, true
+}
+
+// Remove drops a song from the index.
+func (idx *SearchIndex) Remove(fileName string) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	if song, exists := idx.songs[fileName]; exists {
+		idx.unlink(song)
+		delete(idx.songs, fileName)
+	}
+}
+
+// unlink removes song from the postings. Callers must hold idx.mu.
+func (idx *SearchIndex) unlink(song *IndexedSong) {
+	for _, token := range songTokens(song) {
+		removePosting(idx.postings, token, song.FileName)
+		if _, used := idx.postings[token]; used {
+			continue
+		}
+		for _, prefix := range tokenPrefixes(token) {
+			removePosting(idx.prefixes, prefix, token)
+		}
+		for _, gram := range tokenTrigrams(token) {
+			removePosting(idx.trigrams, gram, token)
+		}
+	}
+}
+
+// Token match scores; a song's relevance is the sum over query tokens.
+const (
+	scoreExact  = 3.0
+	scorePrefix = 2.0
+	scoreFuzzy  = 1.0
+)
+
+// SearchHit is a song matched by the index with its token relevance.
+type SearchHit struct {
+	Song      IndexedSong
+	Relevance float64
+}
+
+// matchToken scores the files having a token that equals, starts with or
+// fuzzily matches the query token, keeping the best score per file. A token
+// matches fuzzily when the query token is a subsequence of it ("terday" in
+// "yesterday") or within a few edits ("beatels"). Callers must hold idx.mu.
+func (idx *SearchIndex) matchToken(queryToken string) map[string]float64 {
+	matches := make(map[string]float64)
+	for _, token := range idx.candidates(queryToken) {
+		var score float64
+		switch {
+		case token == queryToken:
+			score = scoreExact
+		case strings.HasPrefix(token, queryToken):
+			score = scorePrefix
+		case fuzzy.Match(queryToken, token) || fuzzy.LevenshteinDistance(queryToken, token) <= maxEdits(queryToken):
+			score = scoreFuzzy
+		default:
+			continue
+		}
+		for name := range idx.postings[token] {
+			if score > matches[name] {
+				matches[name] = score
+			}
+		}
+	}
+	return matches
+}
+
+// maxEdits is how many typos a fuzzy match of queryToken may contain; a
+// swap of two letters counts as two.
+func maxEdits(queryToken string) int {
+	return 1 + utf8.RuneCountInString(queryToken)/6
+}
+
+// candidates returns the tokens worth comparing with a query token. Short
+// query tokens only match by prefix. Longer ones must share at least a third
+// of their trigrams with a token; any such token is in one of the n-t+1
+// rarest trigrams' postings (t shared out of n), so only those are walked.
+// Callers must hold idx.mu.
+func (idx *SearchIndex) candidates(queryToken string) []string {
+	grams := tokenTrigrams(queryToken)
+	if len(grams) == 0 {
+		var tokens []string
+		for token := range idx.prefixes[queryToken] {
+			tokens = append(tokens, token)
+		}
+		return tokens
+	}
+
+	needed := (len(grams) + 2) / 3
+	postings := make([]map[string]struct{}, len(grams))
+	for i, gram := range grams {
+		postings[i] = idx.trigrams[gram]
+	}
+	slices.SortFunc(postings, func(a, b map[string]struct{}) int { return len(a) - len(b) })
+
+	seen := make(map[string]bool)
+	var tokens []string
+	for _, rare := range postings[:len(postings)-needed+1] {
+		for token := range rare {
+			if seen[token] {
+				continue
+			}
+			seen[token] = true
+			shared := 0
+			for _, posting := range postings {
+				if _, ok := posting[token]; ok {
+					shared++
+				}
+			}
+			if shared >= needed {
+				tokens = append(tokens, token)
+			}
+		}
+	}
+	return tokens
+}
+
+// Search returns a copy of every song matching all tokens of the query,
+// scored by how well each token matched. An empty query matches the whole
+// catalogue with zero relevance.
+func (idx *SearchIndex) Search(query string) []SearchHit {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	tokens := tokenize(query)
+	if len(tokens) == 0 {
+		hits := make([]SearchHit, 0, len(idx.songs))
+		for _, song := range idx.songs {
+			hits = append(hits, SearchHit{Song: *song})
+		}
+		return hits
+	}
+
+	var candidates map[string]float64
+	for _, token := range tokens {
+		matches := idx.matchToken(token)
+		if candidates == nil {
+			candidates = matches
+			continue
+		}
+		for name, score := range candidates {
+			if tokenScore, ok := matches[name]; ok {
+				candidates[name] = score + tokenScore
+			} else {
+				delete(candidates, name)
+			}
+		}
+		if len(candidates) == 0 {
+			break
+		}
+	}
+
+	hits := make([]SearchHit, 0, len(candidates))
+	for name, score := range candidates {
+		hits = append(hits, SearchHit{Song: *idx.songs[name], Relevance: score})
+	}
+	return hits
+}
+
+// Len returns the number of indexed songs.
+func (idx *SearchIndex) Len() int {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	return len(idx.songs)
+}
+
+// indexedSong converts torrent metadata into its searchable form.
+func indexedSong(metadata *TorrentMetadata) IndexedSong {
+	return IndexedSong{
+		FileName:   metadata.FileName,
+		ArtistName: metadata.ArtistName,
+		CreatedAt:  metadata.CreatedAt,
+		Duration:   metadata.Duration,
+		FileSize:   metadata.FileSize,
+		Peers:      metadata.Peers,
+	}
+}
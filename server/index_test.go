@@ -0,0 +1,232 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	pb "napster"
+)
+
+func hitNames(hits []SearchHit) []string {
+	names := make([]string, 0, len(hits))
+	for _, hit := range hits {
+		names = append(names, hit.Song.FileName)
+	}
+	slices.Sort(names)
+	return names
+}
+
+func TestSearchIndex(t *testing.T) {
+	idx := NewSearchIndex()
+	for _, song := range []IndexedSong{
+		{FileName: "Yesterday.mp3", ArtistName: "The Beatles"},
+		{FileName: "Let It Be.mp3", ArtistName: "The Beatles"},
+		{FileName: "Halo.mp3", ArtistName: "Beyoncé"},
+		{FileName: "Hallelujah.mp3", ArtistName: "Leonard Cohen"},
+	} {
+		idx.Put(song)
+	}
+
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{"beatles", []string{"Let It Be.mp3", "Yesterday.mp3"}},
+		{"BEATLES yesterday", []string{"Yesterday.mp3"}},
+		{"beat", []string{"Let It Be.mp3", "Yesterday.mp3"}},
+		{"eatles", []string{"Let It Be.mp3", "Yesterday.mp3"}},
+		{"beatels", []string{"Let It Be.mp3", "Yesterday.mp3"}},
+		{"yestreday", []string{"Yesterday.mp3"}},
+		{"terday", []string{"Yesterday.mp3"}},
+		{"beyonce", []string{"Halo.mp3"}},
+		{"hal", []string{"Hallelujah.mp3", "Halo.mp3"}},
+		{"mp3", nil},
+		{"beatles cohen", nil},
+		{"", []string{"Hallelujah.mp3", "Halo.mp3", "Let It Be.mp3", "Yesterday.mp3"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			if got := hitNames(idx.Search(tt.query)); !slices.Equal(got, tt.want) {
+				t.Errorf("Search(%q) = %v, want %v", tt.query, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSearchIndexRelevance(t *testing.T) {
+	idx := NewSearchIndex()
+	idx.Put(IndexedSong{FileName: "Halo.mp3"})
+	idx.Put(IndexedSong{FileName: "Halogen.mp3"})
+	idx.Put(IndexedSong{FileName: "Hallowed.mp3"})
+
+	relevance := make(map[string]float64)
+	for _, hit := range idx.Search("halo") {
+		relevance[hit.Song.FileName] = hit.Relevance
+	}
+	want := map[string]float64{"Halo.mp3": scoreExact, "Halogen.mp3": scorePrefix, "Hallowed.mp3": scoreFuzzy}
+	for name, score := range want {
+		if relevance[name] != score {
+			t.Errorf("relevance of %s = %v, want %v", name, relevance[name], score)
+		}
+	}
+}
+
+func TestSearchIndexUpdates(t *testing.T) {
+	idx := NewSearchIndex()
+	idx.Put(IndexedSong{FileName: "Halo.mp3", ArtistName: "Beyonce", Peers: []string{"localhost:7001"}})
+
+	idx.Put(IndexedSong{FileName: "Halo.mp3", ArtistName: "Someone Else"})
+	if got := hitNames(idx.Search("beyonce")); len(got) != 0 {
+		t.Errorf("replaced artist still found: %v", got)
+	}
+	if got := hitNames(idx.Search("someone")); !slices.Equal(got, []string{"Halo.mp3"}) {
+		t.Errorf("new artist not found: %v", got)
+	}
+
+	idx.SetPeers("Halo.mp3", []string{"localhost:7002"})
+	if song, _ := idx.Get("Halo.mp3"); !slices.Equal(song.Peers, []string{"localhost:7002"}) {
+		t.Errorf("peers = %v, want [localhost:7002]", song.Peers)
+	}
+
+	idx.Remove("Halo.mp3")
+	if got := idx.Search("halo"); len(got) != 0 || idx.Len() != 0 {
+		t.Errorf("removed song still indexed: %v", hitNames(got))
+	}
+	if len(idx.postings) != 0 || len(idx.prefixes) != 0 || len(idx.trigrams) != 0 {
+		t.Errorf("postings left after removing every song: %v %v %v", idx.postings, idx.prefixes, idx.trigrams)
+	}
+}
+
+// Searches run concurrently with each other and with updates; run with -race.
+func TestSearchIndexConcurrent(t *testing.T) {
+	idx := NewSearchIndex()
+	idx.Put(IndexedSong{FileName: "Halo.mp3", ArtistName: "Beyoncé"})
+	var wg sync.WaitGroup
+	for i := range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range 50 {
+				idx.Put(IndexedSong{FileName: fmt.Sprintf("Déjà vu %d %d.mp3", i, j), ArtistName: "Beyoncé"})
+				if len(idx.Search("beyonce")) == 0 {
+					t.Error("Halo not found")
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	if n := len(idx.Search("deja")); n != 8*50 {
+		t.Errorf("%d songs found, want %d", n, 8*50)
+	}
+}
+
+var syntheticSyllables = []string{
+	"ka", "lo", "mi", "ra", "ne", "so", "ta", "vi", "be", "du",
+	"fa", "go", "hi", "ju", "ke", "la", "mo", "nu", "pe", "qui",
+	"ri", "sa", "to", "ul", "ve", "wa", "xo", "ya", "ze", "an",
+}
+
+// syntheticCatalogue indexes n generated songs on a server running alone,
+// the same every run. Its vocabulary grows with the catalogue, as a real
+// one does; built from few syllables, it has more near misses the larger it
+// gets. The queries returned are one or two words, every fourth misspelt.
+func syntheticCatalogue(b *testing.B, n int) (*CentralServer, []string) {
+	b.Helper()
+	rng := rand.New(rand.NewSource(1))
+	vocabulary := make([]string, n/2)
+	for i := range vocabulary {
+		var word strings.Builder
+		for range 2 + rng.Intn(3) {
+			word.WriteString(syntheticSyllables[rng.Intn(len(syntheticSyllables))])
+		}
+		vocabulary[i] = word.String()
+	}
+	word := func() string { return vocabulary[rng.Intn(len(vocabulary))] }
+
+	store := openTestStore(b, b.TempDir())
+	b.Cleanup(func() { store.Close() })
+	s := NewCentralServer(store)
+	entries := make([]IndexEntry, 0, n)
+	for i := range n {
+		song := IndexedSong{
+			FileName:   fmt.Sprintf("%s %s %s.mp3", word(), word(), word()),
+			ArtistName: fmt.Sprintf("%s %s", word(), word()),
+			CreatedAt:  time.Unix(1700000000+int64(i), 0).Format(time.RFC3339),
+			Duration:   int64(120 + rng.Intn(240)),
+			FileSize:   int64(2_000_000 + rng.Intn(8_000_000)),
+			Peers:      []string{fmt.Sprintf("peer%d", rng.Intn(1000))},
+		}
+		s.searchIndex.Put(song)
+		entries = append(entries, IndexEntry{FileName: song.FileName, Peers: song.Peers})
+	}
+	if err := store.Reset(entries); err != nil {
+		b.Fatal(err)
+	}
+
+	queries := make([]string, 1000)
+	for i := range queries {
+		queries[i] = word()
+		if i%4 == 0 {
+			// Swap two letters in the middle.
+			q := []byte(queries[i])
+			q[2], q[3] = q[3], q[2]
+			queries[i] = string(q)
+		}
+		if i%3 == 0 {
+			queries[i] += " " + word()
+		}
+	}
+	return s, queries
+}
+
+// benchmarkSizes are the catalogue sizes search is measured at. The time
+// per query should grow with the hits, not with the catalogue.
+var benchmarkSizes = []int{10_000, 100_000}
+
+// BenchmarkSearchIndex measures index lookups alone.
+func BenchmarkSearchIndex(b *testing.B) {
+	for _, n := range benchmarkSizes {
+		b.Run(fmt.Sprintf("songs=%d", n), func(b *testing.B) {
+			s, queries := syntheticCatalogue(b, n)
+			hits := 0
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := range b.N {
+				hits += len(s.searchIndex.Search(queries[i%len(queries)]))
+			}
+			b.ReportMetric(float64(hits)/float64(b.N), "hits/op")
+		})
+	}
+}
+
+// BenchmarkSearchFile measures the SearchFile RPC as clients call it:
+// lookup, liveness, ranking and the first 100 results, from parallel callers.
+func BenchmarkSearchFile(b *testing.B) {
+	for _, n := range benchmarkSizes {
+		b.Run(fmt.Sprintf("songs=%d", n), func(b *testing.B) {
+			s, queries := syntheticCatalogue(b, n)
+			for i := range 1000 {
+				s.peerStatus[fmt.Sprintf("peer%d", i)] = &PeerLease{Alive: true, Expires: time.Now().Add(time.Hour)}
+			}
+			b.ReportAllocs()
+			b.ResetTimer()
+			b.RunParallel(func(p *testing.PB) {
+				i := 0
+				for p.Next() {
+					req := &pb.SearchRequest{Query: queries[i%len(queries)], MaxResults: 100}
+					if _, err := s.SearchFile(context.Background(), req); err != nil {
+						b.Error(err)
+					}
+					i++
+				}
+			})
+		})
+	}
+}
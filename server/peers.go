@@ -0,0 +1,141 @@
+package main
+
+import (
+	"context"
+	"log"
+	"time"
+
+	pb "napster"
+)
+
+// leaseDuration is how long a peer stays alive without a heartbeat.
+const leaseDuration = 30 * time.Second
+
+// PeerLease tracks a registered peer and the files it announced.
+type PeerLease struct {
+	Files   []string
+	Expires time.Time
+	Alive   bool
+}
+
+// RegisterPeer grants a lease to the peer and lists it as a seeder of every
+// announced file the index knows about.
+func (s *CentralServer) RegisterPeer(ctx context.Context, req *pb.RegisterRequest) (*pb.RegisterResponse, error) {
+	if req.PeerAddress == "" {
+		return &pb.RegisterResponse{Success: false, Message: "missing peer address"}, nil
+	}
+
+	s.mu.Lock()
+	s.peerStatus[req.PeerAddress] = &PeerLease{
+		Files:   req.FileNames,
+		Expires: time.Now().Add(leaseDuration),
+		Alive:   true,
+	}
+	s.mu.Unlock()
+
+	s.announceFiles(req.PeerAddress, req.FileNames)
+
+	log.Printf("Registered peer %s seeding %d files", req.PeerAddress, len(req.FileNames))
+	return &pb.RegisterResponse{
+		Success:      true,
+		Message:      "registered",
+		LeaseSeconds: int32(leaseDuration / time.Second),
+	}, nil
+}
+
+// Heartbeat renews a peer's lease. Peers the server does not know are asked
+// to register again, which happens after a server restart.
+func (s *CentralServer) Heartbeat(ctx context.Context, req *pb.HeartbeatRequest) (*pb.HeartbeatResponse, error) {
+	s.mu.Lock()
+	lease, exists := s.peerStatus[req.PeerAddress]
+	var added []string
+	if exists {
+		for _, file := range req.FileNames {
+			if !containsString(lease.Files, file) {
+				added = append(added, file)
+			}
+		}
+		lease.Files = req.FileNames
+		lease.Expires = time.Now().Add(leaseDuration)
+		lease.Alive = true
+	}
+	s.mu.Unlock()
+
+	if !exists {
+		return &pb.HeartbeatResponse{Success: false, Reregister: true}, nil
+	}
+
+	s.announceFiles(req.PeerAddress, added)
+
+	return &pb.HeartbeatResponse{
+		Success:      true,
+		LeaseSeconds: int32(leaseDuration / time.Second),
+	}, nil
+}
+
+// announceFiles adds peer to the peer list of every indexed file in files.
+func (s *CentralServer) announceFiles(peer string, files []string) {
+	for _, file := range files {
+		if err := s.addPeer(file, peer); err != nil && debug_mode {
+			log.Printf("Ignoring announced file %s from %s: %v", file, peer, err)
+		}
+	}
+}
+
+// trackSeeding keeps the announced file list of a leased peer in sync with
+// EnableSeeding / StopSeeding calls.
+func (s *CentralServer) trackSeeding(peer string, file string, seeding bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	lease, exists := s.peerStatus[peer]
+	if !exists {
+		return
+	}
+	if seeding && !containsString(lease.Files, file) {
+		lease.Files = append(lease.Files, file)
+	} else if !seeding {
+		lease.Files = removeString(lease.Files, file)
+	}
+}
+
+// isAlive reports whether peer holds an unexpired lease. Followers of a
+// cluster hold no leases and trust the index, from which the leader removes
+// expired peers. Callers must hold s.mu.
+func (s *CentralServer) isAlive(peer string) bool {
+	if !s.isLeader() {
+		return true
+	}
+	lease, exists := s.peerStatus[peer]
+	return exists && lease.Alive && time.Now().Before(lease.Expires)
+}
+
+// liveness returns a snapshot of isAlive, so long scans such as a search
+// over the whole catalogue check peers without holding s.mu.
+func (s *CentralServer) liveness() func(peer string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.isLeader() {
+		return func(string) bool { return true }
+	}
+	alive := make(map[string]bool, len(s.peerStatus))
+	for peer := range s.peerStatus {
+		if s.isAlive(peer) {
+			alive[peer] = true
+		}
+	}
+	return func(peer string) bool { return alive[peer] }
+}
+
+// expirePeer removes a peer whose lease lapsed from every torrent it is listed in.
+func (s *CentralServer) expirePeer(peer string) {
+	for _, entry := range s.store.All() {
+		if !containsString(entry.Peers, peer) {
+			continue
+		}
+		if err := s.removePeer(entry.FileName, peer); err != nil {
+			log.Printf("Failed to remove expired peer %s from %s: %v", peer, entry.FileName, err)
+		}
+	}
+}
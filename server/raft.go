@@ -0,0 +1,940 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	pb "napster"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	heartbeatInterval  = 300 * time.Millisecond
+	electionTimeoutMin = 1500 * time.Millisecond
+	electionTimeoutMax = 3000 * time.Millisecond
+	consensusTimeout   = time.Second      // per RequestVote / AppendEntries call
+	proposeTimeout     = 10 * time.Second // how long a change may take to commit
+	maxEntriesPerCall  = 256
+	snapshotEvery      = 1000    // applied entries before the log is compacted into a snapshot
+	snapshotChunkSize  = 1 << 20 // bytes of a snapshot sent per InstallSnapshot call
+
+	raftLogFile      = "raft.log"
+	raftStateFile    = "raft.state"
+	raftSnapshotFile = "raft.snapshot"
+)
+
+// ErrNotLeader is returned when a change is proposed to a follower.
+var ErrNotLeader = errors.New("not the leader")
+
+type raftRole int
+
+const (
+	follower raftRole = iota
+	candidate
+	leader
+)
+
+// logEntry is one command of the replicated log.
+type logEntry struct {
+	Term    uint64 `json:"term"`
+	Index   uint64 `json:"index"`
+	Command []byte `json:"command,omitempty"`
+}
+
+// raftSnapshot is the state machine as of an applied entry, which replaces
+// the log up to that entry.
+type raftSnapshot struct {
+	LastIndex uint64 `json:"last_index"`
+	LastTerm  uint64 `json:"last_term"`
+	Data      []byte `json:"data"`
+}
+
+// stateMachine is what the committed commands are applied to.
+type stateMachine interface {
+	applyReplicated(command []byte)
+	snapshotIndex() ([]byte, error)     // the state after the last applied command
+	restoreIndex(snapshot []byte) error // replaces the state with a snapshot
+}
+
+// raftState is the part of the state that must survive a restart besides the log.
+type raftState struct {
+	Term     uint64 `json:"term"`
+	VotedFor string `json:"voted_for"`
+}
+
+// Raft replicates a log of commands between the central servers of a cluster
+// and hands every committed command, in order, to apply. It follows the Raft
+// algorithm: servers elect a leader for a term by majority vote, the leader
+// appends proposed commands to its log and copies them to the followers, and a
+// command is committed once a majority stored it. Followers that stop hearing
+// from the leader start an election.
+//
+// Every snapshotEvery applied entries, the state machine is saved as a
+// snapshot and the log up to it is dropped, on disk and in memory. A restart
+// restores the snapshot and applies only the entries after it; a follower
+// missing entries the leader dropped is sent the snapshot instead.
+type Raft struct {
+	pb.UnimplementedConsensusServer
+	mu    sync.Mutex
+	self  string                        // address the other servers reach this one at
+	peers map[string]pb.ConsensusClient // the other servers of the cluster
+	conns []*grpc.ClientConn
+	kicks map[string]chan struct{} // wake a peer's replication loop
+	dir   string
+
+	role        raftRole
+	term        uint64
+	votedFor    string
+	leader      string     // leader of the current term, if known
+	log         []logEntry // log[0] is the last entry the snapshot covers, or a sentinel
+	logFile     *os.File
+	snapshot    []byte // state machine as of log[0]
+	commitIndex uint64
+	lastApplied uint64
+	proposals   map[uint64]uint64 // index proposed here -> term of the entry applied there, 0 until applied
+	nextIndex   map[string]uint64 // leader: next entry to send to each peer
+	matchIndex  map[string]uint64 // leader: highest entry known stored by each server
+
+	incoming      []byte // snapshot being received
+	snapshotEvery uint64
+
+	lastContact     time.Time // last message from a leader, or vote granted
+	electionTimeout time.Duration
+	applied         *sync.Cond    // broadcast when lastApplied or the term moves
+	commitReady     chan struct{} // wakes the applier
+	roleChanges     chan bool     // leadership changes, delivered in order
+	stop            chan struct{} // closed by Stop
+	stopped         bool
+
+	machine      stateMachine
+	onRoleChange func(leader bool)
+}
+
+// NewRaft loads the persisted term, vote, snapshot and log from dir.
+// Committed commands are applied to machine; onRoleChange is called when this
+// server gains or loses leadership.
+func NewRaft(self string, peers []string, dir string, machine stateMachine, onRoleChange func(bool)) (*Raft, error) {
+	r := &Raft{
+		self:          self,
+		peers:         make(map[string]pb.ConsensusClient),
+		kicks:         make(map[string]chan struct{}),
+		dir:           dir,
+		log:           []logEntry{{}},
+		proposals:     make(map[uint64]uint64),
+		nextIndex:     make(map[string]uint64),
+		matchIndex:    make(map[string]uint64),
+		snapshotEvery: snapshotEvery,
+		lastContact:   time.Now(),
+		commitReady:   make(chan struct{}, 1),
+		roleChanges:   make(chan bool, 16),
+		stop:          make(chan struct{}),
+		machine:       machine,
+		onRoleChange:  onRoleChange,
+	}
+	r.applied = sync.NewCond(&r.mu)
+	r.resetElectionTimeout()
+
+	for _, addr := range peers {
+		conn, err := grpc.NewClient(addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+		if err != nil {
+			return nil, err
+		}
+		r.peers[addr] = pb.NewConsensusClient(conn)
+		r.conns = append(r.conns, conn)
+		r.kicks[addr] = make(chan struct{}, 1)
+	}
+
+	if err := r.loadState(); err != nil {
+		return nil, err
+	}
+	if err := r.loadSnapshot(); err != nil {
+		return nil, err
+	}
+	if err := r.loadLog(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Run drives elections and heartbeats until Stop is called.
+func (r *Raft) Run() {
+	go r.runApplier()
+	go func() {
+		for {
+			select {
+			case leading := <-r.roleChanges:
+				r.onRoleChange(leading)
+			case <-r.stop:
+				return
+			}
+		}
+	}()
+
+	ticker := time.NewTicker(heartbeatInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+		case <-r.stop:
+			return
+		}
+		r.mu.Lock()
+		if r.role != leader && time.Since(r.lastContact) > r.electionTimeout {
+			r.startElection()
+		}
+		// Wakes proposers so they notice a timeout or a lost leadership.
+		r.applied.Broadcast()
+		r.mu.Unlock()
+	}
+}
+
+// Stop ends elections, replication and applying, and closes the log. The
+// caller stops serving the Consensus RPCs.
+func (r *Raft) Stop() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.stopped {
+		return
+	}
+	r.stopped = true
+	r.role = follower
+	close(r.stop)
+	r.applied.Broadcast()
+	r.logFile.Close()
+	for _, conn := range r.conns {
+		conn.Close()
+	}
+}
+
+// Propose appends command to the log and waits until it is committed and
+// applied on this server.
+func (r *Raft) Propose(command []byte) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.role != leader {
+		return ErrNotLeader
+	}
+	entry := logEntry{Term: r.term, Index: r.lastIndex() + 1, Command: command}
+	if err := r.appendLog(entry); err != nil {
+		return err
+	}
+	r.proposals[entry.Index] = 0
+	defer delete(r.proposals, entry.Index)
+	r.matchIndex[r.self] = entry.Index
+	r.advanceCommit()
+	r.kickPeers()
+
+	deadline := time.Now().Add(proposeTimeout)
+	for r.proposals[entry.Index] == 0 {
+		if r.term != entry.Term || r.stopped {
+			return ErrNotLeader
+		}
+		if time.Now().After(deadline) {
+			return errors.New("timed out waiting for the cluster")
+		}
+		r.applied.Wait()
+	}
+	// Committed entries are never replaced, so this is the final verdict.
+	if r.proposals[entry.Index] != entry.Term {
+		return ErrNotLeader
+	}
+	return nil
+}
+
+// IsLeader reports whether this server currently accepts proposals.
+func (r *Raft) IsLeader() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.role == leader
+}
+
+// Leader returns the address of the current leader, or "" while unknown.
+func (r *Raft) Leader() string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.leader
+}
+
+// RequestVote grants the candidate this server's vote for its term if no
+// other candidate got it and the candidate's log is at least as up to date.
+func (r *Raft) RequestVote(ctx context.Context, req *pb.VoteRequest) (*pb.VoteResponse, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if req.Term > r.term {
+		if err := r.stepDown(req.Term, ""); err != nil {
+			return nil, err
+		}
+	}
+
+	lastTerm := r.termAt(r.lastIndex())
+	upToDate := req.LastLogTerm > lastTerm || (req.LastLogTerm == lastTerm && req.LastLogIndex >= r.lastIndex())
+	granted := false
+	if req.Term == r.term && (r.votedFor == "" || r.votedFor == req.Candidate) && upToDate {
+		r.votedFor = req.Candidate
+		if err := r.saveState(); err != nil {
+			return nil, err
+		}
+		r.lastContact = time.Now()
+		granted = true
+	}
+	return &pb.VoteResponse{Term: r.term, Granted: granted}, nil
+}
+
+// AppendEntries stores the leader's entries after checking that the log
+// matches the leader's up to them, replacing any conflicting suffix.
+func (r *Raft) AppendEntries(ctx context.Context, req *pb.AppendEntriesRequest) (*pb.AppendEntriesResponse, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if req.Term < r.term {
+		return &pb.AppendEntriesResponse{Term: r.term, LastLogIndex: r.lastIndex()}, nil
+	}
+	if req.Term > r.term || r.role != follower {
+		if err := r.stepDown(req.Term, req.Leader); err != nil {
+			return nil, err
+		}
+	}
+	r.leader = req.Leader
+	r.lastContact = time.Now()
+
+	// Entries up to the snapshot are committed, so they match the leader's.
+	if req.PrevLogIndex > r.lastIndex() || (req.PrevLogIndex > r.log[0].Index && r.termAt(req.PrevLogIndex) != req.PrevLogTerm) {
+		return &pb.AppendEntriesResponse{
+			Term:         r.term,
+			LastLogIndex: min(r.lastIndex(), req.PrevLogIndex-1),
+		}, nil
+	}
+
+	var fresh []logEntry
+	for i, e := range req.Entries {
+		if e.Index <= r.log[0].Index {
+			continue
+		}
+		if e.Index <= r.lastIndex() {
+			if r.termAt(e.Index) == e.Term {
+				continue
+			}
+			if err := r.truncateLog(e.Index); err != nil {
+				return nil, err
+			}
+		}
+		for _, e := range req.Entries[i:] {
+			fresh = append(fresh, logEntry{Term: e.Term, Index: e.Index, Command: e.Command})
+		}
+		break
+	}
+	if err := r.appendLog(fresh...); err != nil {
+		return nil, err
+	}
+
+	lastNew := req.PrevLogIndex + uint64(len(req.Entries))
+	if req.LeaderCommit > r.commitIndex {
+		r.commitIndex = min(req.LeaderCommit, lastNew)
+		r.signalCommit()
+	}
+	return &pb.AppendEntriesResponse{Term: r.term, Success: true, LastLogIndex: r.lastIndex()}, nil
+}
+
+// InstallSnapshot collects the leader's snapshot piece by piece. Once it is
+// complete it replaces the log up to the snapshot, and the applier replaces
+// the state machine with it.
+func (r *Raft) InstallSnapshot(ctx context.Context, req *pb.InstallSnapshotRequest) (*pb.InstallSnapshotResponse, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if req.Term < r.term {
+		return &pb.InstallSnapshotResponse{Term: r.term}, nil
+	}
+	if req.Term > r.term || r.role != follower {
+		if err := r.stepDown(req.Term, req.Leader); err != nil {
+			return nil, err
+		}
+	}
+	r.leader = req.Leader
+	r.lastContact = time.Now()
+
+	if req.Offset == 0 {
+		r.incoming = nil
+	}
+	if req.Offset != uint64(len(r.incoming)) {
+		return nil, status.Errorf(codes.InvalidArgument, "snapshot piece at %d, expected %d", req.Offset, len(r.incoming))
+	}
+	r.incoming = append(r.incoming, req.Data...)
+	if !req.Done {
+		return &pb.InstallSnapshotResponse{Term: r.term}, nil
+	}
+	data := r.incoming
+	r.incoming = nil
+
+	// Everything the snapshot covers is applied from the log already.
+	if req.LastIndex <= r.commitIndex {
+		return &pb.InstallSnapshotResponse{Term: r.term}, nil
+	}
+	if err := r.saveSnapshot(raftSnapshot{LastIndex: req.LastIndex, LastTerm: req.LastTerm, Data: data}); err != nil {
+		return nil, err
+	}
+	// Entries after the snapshot are kept if the log agrees with it up to there.
+	var rest []logEntry
+	if req.LastIndex <= r.lastIndex() && r.termAt(req.LastIndex) == req.LastTerm {
+		rest = r.log[req.LastIndex-r.log[0].Index+1:]
+	}
+	if err := r.compactLog(logEntry{Term: req.LastTerm, Index: req.LastIndex}, rest); err != nil {
+		return nil, err
+	}
+	r.snapshot = data
+	r.commitIndex = req.LastIndex
+	r.signalCommit()
+	log.Printf("Raft: installed the leader's snapshot up to entry %d", req.LastIndex)
+	return &pb.InstallSnapshotResponse{Term: r.term}, nil
+}
+
+// startElection makes this server a candidate for the next term and asks the
+// others for their votes. Callers must hold r.mu.
+func (r *Raft) startElection() {
+	r.role = candidate
+	r.term++
+	r.votedFor = r.self
+	r.leader = ""
+	r.lastContact = time.Now()
+	r.resetElectionTimeout()
+	if err := r.saveState(); err != nil {
+		log.Printf("Raft: cannot persist term %d: %v", r.term, err)
+		return
+	}
+	log.Printf("Raft: starting election for term %d", r.term)
+
+	if len(r.peers) == 0 {
+		r.becomeLeader()
+		return
+	}
+
+	term := r.term
+	req := &pb.VoteRequest{
+		Term:         term,
+		Candidate:    r.self,
+		LastLogIndex: r.lastIndex(),
+		LastLogTerm:  r.termAt(r.lastIndex()),
+	}
+	votes := 1
+	for addr, client := range r.peers {
+		go func(addr string, client pb.ConsensusClient) {
+			ctx, cancel := context.WithTimeout(context.Background(), consensusTimeout)
+			resp, err := client.RequestVote(ctx, req)
+			cancel()
+			if err != nil {
+				return
+			}
+
+			r.mu.Lock()
+			defer r.mu.Unlock()
+			if resp.Term > r.term {
+				r.stepDown(resp.Term, "")
+				return
+			}
+			if r.role != candidate || r.term != term || !resp.Granted {
+				return
+			}
+			votes++
+			if r.isMajority(votes) {
+				r.becomeLeader()
+			}
+		}(addr, client)
+	}
+}
+
+// becomeLeader starts replicating to every peer. The no-op entry of the new
+// term commits whatever the previous leaders left uncommitted. Callers must
+// hold r.mu.
+func (r *Raft) becomeLeader() {
+	r.role = leader
+	r.leader = r.self
+	for addr := range r.peers {
+		r.nextIndex[addr] = r.lastIndex() + 1
+		r.matchIndex[addr] = 0
+	}
+	if err := r.appendLog(logEntry{Term: r.term, Index: r.lastIndex() + 1}); err != nil {
+		log.Printf("Raft: cannot append to the log: %v", err)
+		r.role = follower
+		r.leader = ""
+		return
+	}
+	r.matchIndex[r.self] = r.lastIndex()
+	r.advanceCommit()
+
+	log.Printf("Raft: elected leader for term %d", r.term)
+	for addr := range r.peers {
+		go r.replicateTo(addr, r.term)
+	}
+	r.roleChanges <- true
+}
+
+// stepDown moves to term as a follower of leader ("" if unknown). Callers
+// must hold r.mu.
+func (r *Raft) stepDown(term uint64, leaderAddr string) error {
+	wasLeader := r.role == leader
+	r.role = follower
+	r.leader = leaderAddr
+	if term > r.term {
+		r.term = term
+		r.votedFor = ""
+		if err := r.saveState(); err != nil {
+			return err
+		}
+	}
+	r.lastContact = time.Now()
+	r.applied.Broadcast()
+	if wasLeader {
+		log.Printf("Raft: stepping down in term %d", r.term)
+		r.roleChanges <- false
+	}
+	return nil
+}
+
+// replicateTo sends new entries, or a heartbeat, to one peer for as long as
+// this server leads term.
+func (r *Raft) replicateTo(addr string, term uint64) {
+	ticker := time.NewTicker(heartbeatInterval)
+	defer ticker.Stop()
+
+	for {
+		r.mu.Lock()
+		if r.role != leader || r.term != term {
+			r.mu.Unlock()
+			return
+		}
+		next := r.nextIndex[addr]
+		if next <= r.log[0].Index {
+			// The peer needs entries the log no longer has.
+			base, snapshot := r.log[0], r.snapshot
+			r.mu.Unlock()
+			if r.sendSnapshot(addr, term, base, snapshot) {
+				continue
+			}
+		} else {
+			req := &pb.AppendEntriesRequest{
+				Term:         term,
+				Leader:       r.self,
+				PrevLogIndex: next - 1,
+				PrevLogTerm:  r.termAt(next - 1),
+				LeaderCommit: r.commitIndex,
+			}
+			start := next - r.log[0].Index
+			for _, e := range r.log[start:min(uint64(len(r.log)), start+maxEntriesPerCall)] {
+				req.Entries = append(req.Entries, &pb.LogEntry{Term: e.Term, Index: e.Index, Command: e.Command})
+			}
+			r.mu.Unlock()
+
+			ctx, cancel := context.WithTimeout(context.Background(), consensusTimeout)
+			resp, err := r.peers[addr].AppendEntries(ctx, req)
+			cancel()
+			if err == nil && r.handleAppendResponse(addr, term, req, resp) {
+				// The peer is behind; keep sending without waiting.
+				continue
+			}
+		}
+
+		select {
+		case <-r.kicks[addr]:
+		case <-ticker.C:
+		case <-r.stop:
+			return
+		}
+	}
+}
+
+// sendSnapshot sends the snapshot up to base to a peer and reports whether
+// it was installed.
+func (r *Raft) sendSnapshot(addr string, term uint64, base logEntry, snapshot []byte) bool {
+	for offset := 0; ; offset += snapshotChunkSize {
+		end := min(offset+snapshotChunkSize, len(snapshot))
+		req := &pb.InstallSnapshotRequest{
+			Term:      term,
+			Leader:    r.self,
+			LastIndex: base.Index,
+			LastTerm:  base.Term,
+			Offset:    uint64(offset),
+			Data:      snapshot[offset:end],
+			Done:      end == len(snapshot),
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), consensusTimeout)
+		resp, err := r.peers[addr].InstallSnapshot(ctx, req)
+		cancel()
+		if err != nil {
+			return false
+		}
+
+		r.mu.Lock()
+		if resp.Term > r.term {
+			r.stepDown(resp.Term, "")
+		}
+		if r.role != leader || r.term != term {
+			r.mu.Unlock()
+			return false
+		}
+		if req.Done {
+			r.matchIndex[addr] = max(r.matchIndex[addr], base.Index)
+			r.nextIndex[addr] = base.Index + 1
+			r.advanceCommit()
+			r.mu.Unlock()
+			log.Printf("Raft: sent the snapshot up to entry %d to %s", base.Index, addr)
+			return true
+		}
+		r.mu.Unlock()
+	}
+}
+
+// handleAppendResponse records a peer's progress and reports whether more
+// entries should be sent to it right away.
+func (r *Raft) handleAppendResponse(addr string, term uint64, req *pb.AppendEntriesRequest, resp *pb.AppendEntriesResponse) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if resp.Term > r.term {
+		r.stepDown(resp.Term, "")
+		return false
+	}
+	if r.role != leader || r.term != term {
+		return false
+	}
+	if !resp.Success {
+		r.nextIndex[addr] = max(1, min(req.PrevLogIndex, resp.LastLogIndex+1))
+		return true
+	}
+
+	match := req.PrevLogIndex + uint64(len(req.Entries))
+	if match > r.matchIndex[addr] {
+		r.matchIndex[addr] = match
+	}
+	r.nextIndex[addr] = match + 1
+	r.advanceCommit()
+	return r.nextIndex[addr] <= r.lastIndex()
+}
+
+// advanceCommit commits the newest entry of the current term that a
+// majority stored, and with it every entry before it. Callers must hold r.mu.
+func (r *Raft) advanceCommit() {
+	for n := r.lastIndex(); n > r.commitIndex; n-- {
+		if r.termAt(n) != r.term {
+			break
+		}
+		stored := 0
+		for _, match := range r.matchIndex {
+			if match >= n {
+				stored++
+			}
+		}
+		if r.isMajority(stored) {
+			r.commitIndex = n
+			r.signalCommit()
+			return
+		}
+	}
+}
+
+// runApplier applies committed entries in log order, restoring the snapshot
+// first when the state machine is behind it, and takes a snapshot every
+// snapshotEvery entries. It alone changes the state machine.
+func (r *Raft) runApplier() {
+	for {
+		select {
+		case <-r.commitReady:
+		case <-r.stop:
+			return
+		}
+		for r.applyCommitted() {
+		}
+		r.snapshotIfDue()
+	}
+}
+
+// applyCommitted restores the snapshot or applies the committed entries
+// after lastApplied, and reports whether there may be more to do.
+func (r *Raft) applyCommitted() bool {
+	r.mu.Lock()
+	base := r.log[0]
+	if r.lastApplied < base.Index {
+		snapshot := r.snapshot
+		r.mu.Unlock()
+		if err := r.machine.restoreIndex(snapshot); err != nil {
+			log.Printf("Raft: cannot restore the snapshot up to entry %d: %v", base.Index, err)
+			return false
+		}
+		r.mu.Lock()
+		r.lastApplied = base.Index
+		r.applied.Broadcast()
+		r.mu.Unlock()
+		return true
+	}
+	entries := append([]logEntry(nil), r.log[r.lastApplied+1-base.Index:r.commitIndex+1-base.Index]...)
+	r.mu.Unlock()
+
+	for _, e := range entries {
+		if len(e.Command) > 0 {
+			r.machine.applyReplicated(e.Command)
+		}
+		r.mu.Lock()
+		r.lastApplied = e.Index
+		if _, proposed := r.proposals[e.Index]; proposed {
+			r.proposals[e.Index] = e.Term
+		}
+		r.applied.Broadcast()
+		r.mu.Unlock()
+	}
+	return len(entries) > 0
+}
+
+// snapshotIfDue saves the state machine once snapshotEvery entries were
+// applied since the last snapshot, and drops them from the log.
+func (r *Raft) snapshotIfDue() {
+	r.mu.Lock()
+	index := r.lastApplied
+	due := index >= r.log[0].Index+r.snapshotEvery
+	r.mu.Unlock()
+	if !due {
+		return
+	}
+
+	// Only this goroutine applies entries, so the state is still at index.
+	data, err := r.machine.snapshotIndex()
+	if err != nil {
+		log.Printf("Raft: cannot snapshot the state machine: %v", err)
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.stopped || index <= r.log[0].Index {
+		// Stopped, or a newer snapshot from the leader was installed.
+		return
+	}
+	base := logEntry{Term: r.termAt(index), Index: index}
+	if err := r.saveSnapshot(raftSnapshot{LastIndex: base.Index, LastTerm: base.Term, Data: data}); err != nil {
+		log.Printf("Raft: cannot save a snapshot: %v", err)
+		return
+	}
+	if err := r.compactLog(base, r.log[index-r.log[0].Index+1:]); err != nil {
+		log.Printf("Raft: cannot compact the log: %v", err)
+	}
+	r.snapshot = data
+}
+
+// signalCommit wakes the applier. Callers must hold r.mu.
+func (r *Raft) signalCommit() {
+	select {
+	case r.commitReady <- struct{}{}:
+	default:
+	}
+}
+
+// kickPeers wakes every replication loop. Callers must hold r.mu.
+func (r *Raft) kickPeers() {
+	for _, kick := range r.kicks {
+		select {
+		case kick <- struct{}{}:
+		default:
+		}
+	}
+}
+
+func (r *Raft) isMajority(count int) bool {
+	return count > (len(r.peers)+1)/2
+}
+
+func (r *Raft) lastIndex() uint64 {
+	return r.log[0].Index + uint64(len(r.log)-1)
+}
+
+// termAt returns the term of the entry at index, which must be in the log
+// or be the last one the snapshot covers. Callers must hold r.mu.
+func (r *Raft) termAt(index uint64) uint64 {
+	return r.log[index-r.log[0].Index].Term
+}
+
+func (r *Raft) resetElectionTimeout() {
+	r.electionTimeout = electionTimeoutMin + time.Duration(rand.Int63n(int64(electionTimeoutMax-electionTimeoutMin)))
+}
+
+// --- Persistence ---
+
+func (r *Raft) loadState() error {
+	data, err := os.ReadFile(filepath.Join(r.dir, raftStateFile))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	var state raftState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return err
+	}
+	r.term, r.votedFor = state.Term, state.VotedFor
+	return nil
+}
+
+// saveState persists the term and vote. Callers must hold r.mu.
+func (r *Raft) saveState() error {
+	data, err := json.Marshal(raftState{Term: r.term, VotedFor: r.votedFor})
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(filepath.Join(r.dir, raftStateFile), data)
+}
+
+// loadSnapshot reads the last saved snapshot. The log starts after it, and
+// the applier restores it before applying anything else.
+func (r *Raft) loadSnapshot() error {
+	data, err := os.ReadFile(filepath.Join(r.dir, raftSnapshotFile))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	var snapshot raftSnapshot
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return fmt.Errorf("corrupt snapshot: %v", err)
+	}
+	r.log = []logEntry{{Term: snapshot.LastTerm, Index: snapshot.LastIndex}}
+	r.snapshot = snapshot.Data
+	r.commitIndex = snapshot.LastIndex
+	r.signalCommit()
+	return nil
+}
+
+// saveSnapshot persists a snapshot. Callers must hold r.mu.
+func (r *Raft) saveSnapshot(snapshot raftSnapshot) error {
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(filepath.Join(r.dir, raftSnapshotFile), data)
+}
+
+// loadLog reads the log written by appendLog, truncating a torn or damaged
+// tail the same way the store does. Entries the snapshot covers are left
+// from a crash before the log was compacted and are skipped.
+func (r *Raft) loadLog() error {
+	f, err := os.OpenFile(filepath.Join(r.dir, raftLogFile), os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return err
+	}
+
+	reader := bufio.NewReader(f)
+	var offset int64
+	for {
+		line, err := reader.ReadString('\n')
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			f.Close()
+			return err
+		}
+		payload, ok := verifyLine(line)
+		var entry logEntry
+		if !ok || json.Unmarshal(payload, &entry) != nil || (entry.Index != r.lastIndex()+1 && entry.Index > r.log[0].Index) {
+			log.Printf("Raft: dropping damaged log entry at offset %d", offset)
+			break
+		}
+		if entry.Index > r.log[0].Index {
+			r.log = append(r.log, entry)
+		}
+		offset += int64(len(line))
+	}
+
+	if err := f.Truncate(offset); err != nil {
+		f.Close()
+		return err
+	}
+	if _, err := f.Seek(offset, io.SeekStart); err != nil {
+		f.Close()
+		return err
+	}
+	r.logFile = f
+	return nil
+}
+
+// appendLog durably appends entries to the log. Callers must hold r.mu.
+func (r *Raft) appendLog(entries ...logEntry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+	var data []byte
+	for _, e := range entries {
+		payload, err := json.Marshal(e)
+		if err != nil {
+			return err
+		}
+		data = append(data, checksumLine(payload)...)
+	}
+	if _, err := r.logFile.Write(data); err != nil {
+		return err
+	}
+	if err := r.logFile.Sync(); err != nil {
+		return err
+	}
+	r.log = append(r.log, entries...)
+	return nil
+}
+
+// truncateLog drops the entries from index on, rewriting the log file.
+// Callers must hold r.mu.
+func (r *Raft) truncateLog(index uint64) error {
+	kept := r.log[:index-r.log[0].Index]
+	if err := r.rewriteLog(kept[1:]); err != nil {
+		return err
+	}
+	r.log = kept
+	return nil
+}
+
+// compactLog replaces the log with the entries after base, the last entry
+// a snapshot covers. Callers must hold r.mu.
+func (r *Raft) compactLog(base logEntry, rest []logEntry) error {
+	if err := r.rewriteLog(rest); err != nil {
+		return err
+	}
+	r.log = append([]logEntry{{Term: base.Term, Index: base.Index}}, rest...)
+	return nil
+}
+
+// rewriteLog replaces the log file with entries. Callers must hold r.mu.
+func (r *Raft) rewriteLog(entries []logEntry) error {
+	var data []byte
+	for _, e := range entries {
+		payload, err := json.Marshal(e)
+		if err != nil {
+			return err
+		}
+		data = append(data, checksumLine(payload)...)
+	}
+	path := filepath.Join(r.dir, raftLogFile)
+	if err := writeFileAtomic(path, data); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	r.logFile.Close()
+	r.logFile = f
+	return nil
+}
@@ -0,0 +1,320 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+
+	pb "napster"
+)
+
+// testMachine records the commands applied to it; its snapshot is the list.
+type testMachine struct {
+	mu       sync.Mutex
+	commands []string
+}
+
+func (m *testMachine) applyReplicated(command []byte) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.commands = append(m.commands, string(command))
+}
+
+func (m *testMachine) snapshotIndex() ([]byte, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return json.Marshal(m.commands)
+}
+
+func (m *testMachine) restoreIndex(snapshot []byte) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.commands = nil
+	return json.Unmarshal(snapshot, &m.commands)
+}
+
+func (m *testMachine) applied() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return slices.Clone(m.commands)
+}
+
+// testNode is one server of an in-process cluster, serving Consensus on
+// 127.0.0.1.
+type testNode struct {
+	addr          string
+	peers         []string
+	dir           string
+	snapshotEvery uint64
+	raft          *Raft
+	machine       *testMachine
+	server        *grpc.Server
+}
+
+func newTestCluster(t *testing.T, size int, snapshotEvery uint64) []*testNode {
+	t.Helper()
+	listeners := make([]net.Listener, size)
+	addrs := make([]string, size)
+	for i := range listeners {
+		lis, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatal(err)
+		}
+		listeners[i], addrs[i] = lis, lis.Addr().String()
+	}
+
+	nodes := make([]*testNode, size)
+	for i, addr := range addrs {
+		nodes[i] = &testNode{
+			addr:          addr,
+			peers:         slices.Delete(slices.Clone(addrs), i, i+1),
+			dir:           t.TempDir(),
+			snapshotEvery: snapshotEvery,
+		}
+		nodes[i].start(t, listeners[i])
+	}
+	t.Cleanup(func() {
+		for _, node := range nodes {
+			node.stop()
+		}
+	})
+	return nodes
+}
+
+func (n *testNode) start(t *testing.T, lis net.Listener) {
+	t.Helper()
+	n.machine = &testMachine{}
+	r, err := NewRaft(n.addr, n.peers, n.dir, n.machine, func(bool) {})
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.snapshotEvery = n.snapshotEvery
+	n.raft = r
+	n.server = grpc.NewServer()
+	pb.RegisterConsensusServer(n.server, r)
+	go n.server.Serve(lis)
+	go r.Run()
+}
+
+func (n *testNode) stop() {
+	if n.server != nil {
+		n.server.Stop()
+		n.raft.Stop()
+		n.server = nil
+	}
+}
+
+// restart starts the node again from what it persisted.
+func (n *testNode) restart(t *testing.T) {
+	t.Helper()
+	lis, err := net.Listen("tcp", n.addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	n.start(t, lis)
+}
+
+func (n *testNode) running() bool {
+	return n.server != nil
+}
+
+func (n *testNode) term() uint64 {
+	n.raft.mu.Lock()
+	defer n.raft.mu.Unlock()
+	return n.raft.term
+}
+
+// waitLeader returns the running node that leads, once the other running
+// nodes follow it.
+func waitLeader(t *testing.T, nodes []*testNode) *testNode {
+	t.Helper()
+	deadline := time.Now().Add(15 * time.Second)
+	for time.Now().Before(deadline) {
+		var leading *testNode
+		for _, n := range nodes {
+			if n.running() && n.raft.IsLeader() {
+				leading = n
+			}
+		}
+		agreed := leading != nil
+		for _, n := range nodes {
+			if agreed && n.running() && n.raft.Leader() != leading.addr {
+				agreed = false
+			}
+		}
+		if agreed {
+			return leading
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	t.Fatal("no leader elected")
+	return nil
+}
+
+// propose commits commands through whichever node leads.
+func propose(t *testing.T, nodes []*testNode, commands ...string) {
+	t.Helper()
+	for _, command := range commands {
+		deadline := time.Now().Add(20 * time.Second)
+		for {
+			err := waitLeader(t, nodes).raft.Propose([]byte(command))
+			if err == nil {
+				break
+			}
+			if !errors.Is(err, ErrNotLeader) || time.Now().After(deadline) {
+				t.Fatalf("proposing %s: %v", command, err)
+			}
+		}
+	}
+}
+
+// waitApplied waits until every running node applied the same commands,
+// want among them in order.
+func waitApplied(t *testing.T, nodes []*testNode, want []string) {
+	t.Helper()
+	deadline := time.Now().Add(15 * time.Second)
+	var got [][]string
+	for time.Now().Before(deadline) {
+		got = nil
+		same := true
+		for _, n := range nodes {
+			if !n.running() {
+				continue
+			}
+			applied := n.machine.applied()
+			if len(got) > 0 && !slices.Equal(applied, got[0]) {
+				same = false
+			}
+			got = append(got, applied)
+		}
+		if same && isSubsequence(want, got[0]) {
+			return
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	t.Fatalf("applied %v, want all to apply %v", got, want)
+}
+
+func isSubsequence(want, got []string) bool {
+	i := 0
+	for _, command := range got {
+		if i < len(want) && command == want[i] {
+			i++
+		}
+	}
+	return i == len(want)
+}
+
+func commands(prefix string, n int) []string {
+	var out []string
+	for i := range n {
+		out = append(out, fmt.Sprintf("%s%d", prefix, i))
+	}
+	return out
+}
+
+func TestRaftElection(t *testing.T) {
+	t.Parallel()
+	nodes := newTestCluster(t, 3, snapshotEvery)
+
+	first := waitLeader(t, nodes)
+	firstTerm := first.term()
+	first.stop()
+
+	second := waitLeader(t, nodes)
+	if second == first {
+		t.Fatal("stopped node still leads")
+	}
+	if second.term() <= firstTerm {
+		t.Fatalf("new leader in term %d, old one led term %d", second.term(), firstTerm)
+	}
+
+	// The old leader rejoins and follows the new one.
+	first.restart(t)
+	waitLeader(t, nodes)
+}
+
+func TestRaftReplication(t *testing.T) {
+	t.Parallel()
+	nodes := newTestCluster(t, 3, snapshotEvery)
+
+	want := commands("a", 20)
+	propose(t, nodes, want...)
+	waitApplied(t, nodes, want)
+
+	// A follower misses changes while down and catches up from the log.
+	leading := waitLeader(t, nodes)
+	follower := nodes[0]
+	if follower == leading {
+		follower = nodes[1]
+	}
+	follower.stop()
+	want = append(want, commands("b", 10)...)
+	propose(t, nodes, want[20:]...)
+	follower.restart(t)
+	waitApplied(t, nodes, want)
+
+	// Changes keep committing after the leader fails.
+	leading.stop()
+	want = append(want, commands("c", 10)...)
+	propose(t, nodes, want[30:]...)
+	waitApplied(t, nodes, want)
+	leading.restart(t)
+	waitApplied(t, nodes, want)
+}
+
+func TestRaftSnapshots(t *testing.T) {
+	t.Parallel()
+	nodes := newTestCluster(t, 3, 5)
+
+	want := commands("a", 10)
+	propose(t, nodes, want...)
+	waitApplied(t, nodes, want)
+
+	// The lagging follower's entries are compacted away on the leader, so it
+	// catches up from the leader's snapshot.
+	leading := waitLeader(t, nodes)
+	follower := nodes[0]
+	if follower == leading {
+		follower = nodes[1]
+	}
+	follower.stop()
+	want = append(want, commands("b", 20)...)
+	propose(t, nodes, want[10:]...)
+	follower.restart(t)
+	waitApplied(t, nodes, want)
+
+	for _, n := range nodes {
+		n.raft.mu.Lock()
+		base, entries := n.raft.log[0].Index, len(n.raft.log)
+		n.raft.mu.Unlock()
+		if base < 20 || entries > 10 {
+			t.Errorf("%s: log starts at %d and holds %d entries, want it compacted", n.addr, base, entries)
+		}
+	}
+
+	// A restarted node restores its snapshot rather than replaying from the start.
+	leading = waitLeader(t, nodes)
+	leading.stop()
+	leading.restart(t)
+	leading.raft.mu.Lock()
+	base := leading.raft.log[0].Index
+	leading.raft.mu.Unlock()
+	if base < 20 {
+		t.Fatalf("restarted log starts at %d", base)
+	}
+	waitApplied(t, nodes, want)
+	leading.raft.mu.Lock()
+	applied := leading.raft.lastApplied
+	leading.raft.mu.Unlock()
+	if applied < base {
+		t.Fatalf("restarted node applied up to %d, snapshot covers %d", applied, base)
+	}
+}
@@ -0,0 +1,279 @@
+package main
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	pb "napster"
+)
+
+const (
+	replicationInterval     = 15 * time.Second // how often replica placement is checked
+	replicationTimeout      = 5 * time.Minute  // how long a contributor may take to fetch a file
+	dropTimeout             = 30 * time.Second
+	maxReplicationsInFlight = 8 // fetches of any kind running at once
+	maxMovesInFlight        = 2 // fetches that only move a file to its new owner
+)
+
+// ReplicationManager keeps every file on replicationFactor distinct live
+// contributors with room for it. A contributor counts as a replica once it
+// finished its download and listed itself as a seeder (StartDownload ends
+// with EnableSeeding), so replicas that disappear or never finish are
+// replaced.
+//
+// The owners of a file are the first replicationFactor usable contributors
+// found walking the hash ring from the file's position. When the ring changes,
+// new owners fetch the file and, once all of them hold it, contributors that
+// are no longer owners are told to drop it. Repairs of under-replicated files
+// take precedence; moves are limited to maxMovesInFlight so a join does not
+// saturate the swarm. Erasure-coded files are not replicated; their shards are
+// placed by reconcileShards instead.
+type ReplicationManager struct {
+	server   *CentralServer
+	mu       sync.Mutex
+	pending  map[string]map[string]bool // file name -> contributors fetching it
+	dropping map[string]map[string]bool // file name -> contributors dropping it
+	placing  map[string]map[int]string  // file name -> shard -> contributor storing it
+	moves    int
+	kick     chan struct{}
+}
+
+func NewReplicationManager(server *CentralServer) *ReplicationManager {
+	return &ReplicationManager{
+		server:   server,
+		pending:  make(map[string]map[string]bool),
+		dropping: make(map[string]map[string]bool),
+		placing:  make(map[string]map[int]string),
+		kick:     make(chan struct{}, 1),
+	}
+}
+
+// Kick asks for a replication round without waiting for the next tick.
+func (r *ReplicationManager) Kick() {
+	select {
+	case r.kick <- struct{}{}:
+	default:
+	}
+}
+
+// Run checks replica placement periodically and whenever kicked.
+func (r *ReplicationManager) Run() {
+	ticker := time.NewTicker(replicationInterval)
+	defer ticker.Stop()
+
+	for {
+		r.reconcile()
+		select {
+		case <-ticker.C:
+		case <-r.kick:
+		}
+	}
+}
+
+// reconcile starts the fetches and drops needed to bring every file onto its owners.
+func (r *ReplicationManager) reconcile() {
+	s := r.server
+	contributors := s.liveContributors()
+	if len(contributors) == 0 {
+		return
+	}
+	target := min(s.replicationFactor, len(contributors))
+
+	for _, entry := range s.store.All() {
+		song, _ := s.searchIndex.Get(entry.FileName)
+		if entry.DataShards > 0 {
+			r.reconcileShards(entry, song, contributors)
+			continue
+		}
+		replicas := liveReplicas(entry, contributors)
+		owners := r.owners(entry.FileName, song.FileSize, target, contributors, replicas)
+
+		r.mu.Lock()
+		settled := len(owners) == target
+		for _, owner := range owners {
+			if replicas[owner] {
+				continue
+			}
+			settled = false
+			if r.pending[entry.FileName][owner] {
+				continue
+			}
+			move := len(replicas)+len(r.pending[entry.FileName]) >= target
+			if r.inFlight() >= maxReplicationsInFlight || (move && r.moves >= maxMovesInFlight) {
+				continue
+			}
+			r.startFetch(entry.FileName, owner, contributors[owner], song.FileSize, move)
+		}
+
+		// Only drop surplus copies the manager placed itself, and only after
+		// every owner confirmed its replica.
+		if settled && len(r.pending[entry.FileName]) == 0 {
+			for _, contributor := range entry.Contributors {
+				node, alive := contributors[contributor]
+				if !alive || containsString(owners, contributor) || r.dropping[entry.FileName][contributor] {
+					continue
+				}
+				r.startDrop(entry.FileName, contributor, node.Client)
+			}
+		}
+		r.mu.Unlock()
+	}
+}
+
+// owners walks the hash ring from the file's position and returns the first
+// target contributors that are alive and either hold the file or have room for it.
+func (r *ReplicationManager) owners(fileName string, size int64, target int, contributors map[string]*Contributor, replicas map[string]bool) []string {
+	ring := r.server.ContributorHashring
+	preferred, err := ring.GetN(fileName, len(ring.Members()))
+	if err != nil {
+		return nil
+	}
+
+	var owners []string
+	for _, contributor := range preferred {
+		if len(owners) == target {
+			break
+		}
+		node, alive := contributors[contributor]
+		if alive && (replicas[contributor] || node.hasRoom(size)) {
+			owners = append(owners, contributor)
+		}
+	}
+	return owners
+}
+
+// inFlight counts running fetches and shard placements. Callers must hold r.mu.
+func (r *ReplicationManager) inFlight() int {
+	count := 0
+	for _, contributors := range r.pending {
+		count += len(contributors)
+	}
+	for _, shards := range r.placing {
+		count += len(shards)
+	}
+	return count
+}
+
+// startFetch reserves space on the contributor and fetches the file in the
+// background. Callers must hold r.mu.
+func (r *ReplicationManager) startFetch(fileName string, contributor string, node *Contributor, size int64, move bool) {
+	if r.pending[fileName] == nil {
+		r.pending[fileName] = make(map[string]bool)
+	}
+	r.pending[fileName][contributor] = true
+	if move {
+		r.moves++
+	}
+	node.FreeBytes -= size
+	r.server.reserveSpace(contributor, size)
+	go r.replicate(fileName, contributor, node.Client, move)
+}
+
+// replicate asks contributor to download fileName and records it as a
+// replica once it shows up as a seeder.
+func (r *ReplicationManager) replicate(fileName string, contributor string, client pb.PeerServiceClient, move bool) {
+	defer func() {
+		r.mu.Lock()
+		delete(r.pending[fileName], contributor)
+		if len(r.pending[fileName]) == 0 {
+			delete(r.pending, fileName)
+		}
+		if move {
+			r.moves--
+		}
+		r.mu.Unlock()
+	}()
+
+	if move {
+		log.Printf("Moving %s to its new owner %s", fileName, contributor)
+	} else {
+		log.Printf("Replicating %s to %s", fileName, contributor)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), replicationTimeout)
+	defer cancel()
+
+	resp, err := client.DownloadThisFile(ctx, &pb.SearchRequest{Query: fileName})
+	if err != nil || resp.Status != 200 {
+		log.Printf("Replication of %s to %s failed: %v", fileName, contributor, err)
+		return
+	}
+
+	entry, exists := r.server.store.Get(fileName)
+	if !exists || !containsString(entry.Peers, contributor) {
+		log.Printf("Replication of %s to %s did not complete", fileName, contributor)
+		return
+	}
+	r.server.addContributor(fileName, contributor)
+	log.Printf("Replicated %s to %s", fileName, contributor)
+
+	// A confirmed replica may let surplus copies be dropped.
+	r.Kick()
+}
+
+// startDrop tells a contributor that is no longer an owner to delete its
+// copy. Callers must hold r.mu.
+func (r *ReplicationManager) startDrop(fileName string, contributor string, client pb.PeerServiceClient) {
+	if r.dropping[fileName] == nil {
+		r.dropping[fileName] = make(map[string]bool)
+	}
+	r.dropping[fileName][contributor] = true
+	go r.drop(fileName, contributor, client)
+}
+
+// drop removes contributor's replica of fileName and stops listing it as a seeder.
+func (r *ReplicationManager) drop(fileName string, contributor string, client pb.PeerServiceClient) {
+	defer func() {
+		r.mu.Lock()
+		delete(r.dropping[fileName], contributor)
+		if len(r.dropping[fileName]) == 0 {
+			delete(r.dropping, fileName)
+		}
+		r.mu.Unlock()
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), dropTimeout)
+	defer cancel()
+
+	resp, err := client.DropFile(ctx, &pb.SearchRequest{Query: fileName})
+	if err != nil || resp.Status != 200 {
+		log.Printf("Dropping %s from %s failed: %v", fileName, contributor, err)
+		return
+	}
+
+	s := r.server
+	s.removeReplica(fileName, contributor)
+	s.trackSeeding(contributor, fileName, false)
+	if err := s.removePeer(fileName, contributor); err != nil {
+		log.Printf("Failed to remove %s from the seeders of %s: %v", contributor, fileName, err)
+	}
+	log.Printf("Dropped %s from %s", fileName, contributor)
+}
+
+// liveReplicas returns the live contributors already seeding the file.
+func liveReplicas(entry IndexEntry, contributors map[string]*Contributor) map[string]bool {
+	replicas := make(map[string]bool)
+	for _, peer := range entry.Peers {
+		if _, ok := contributors[peer]; ok {
+			replicas[peer] = true
+		}
+	}
+	return replicas
+}
+
+// liveContributors returns a copy of the contributors holding a lease.
+func (s *CentralServer) liveContributors() map[string]*Contributor {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	live := make(map[string]*Contributor)
+	now := time.Now()
+	for addr, node := range s.cNodes {
+		if now.Before(node.Expires) {
+			copied := *node
+			live[addr] = &copied
+		}
+	}
+	return live
+}
@@ -0,0 +1,313 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+
+	pb "napster"
+)
+
+// fakeContributor stands in for a contributor's peer service. A fetch lists
+// it as a seeder of the file, as StartDownload does through EnableSeeding.
+type fakeContributor struct {
+	pb.PeerServiceClient
+	addr   string
+	server *CentralServer
+	hold   chan struct{} // when set, fetches wait until it is closed
+
+	mu      sync.Mutex
+	fetched []string
+	dropped []string
+}
+
+func (c *fakeContributor) DownloadThisFile(ctx context.Context, req *pb.SearchRequest, opts ...grpc.CallOption) (*pb.GenResponse, error) {
+	if c.hold != nil {
+		select {
+		case <-c.hold:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	c.mu.Lock()
+	c.fetched = append(c.fetched, req.Query)
+	c.mu.Unlock()
+	if err := c.server.addPeer(req.Query, c.addr); err != nil {
+		return nil, err
+	}
+	return &pb.GenResponse{Status: 200}, nil
+}
+
+func (c *fakeContributor) DropFile(ctx context.Context, req *pb.SearchRequest, opts ...grpc.CallOption) (*pb.GenResponse, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.dropped = append(c.dropped, req.Query)
+	return &pb.GenResponse{Status: 200}, nil
+}
+
+func (c *fakeContributor) calls() (fetched, dropped []string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return slices.Clone(c.fetched), slices.Clone(c.dropped)
+}
+
+// newReplicationTestServer runs a server alone with n files of size bytes
+// indexed, each seeded by an uploader.
+func newReplicationTestServer(t *testing.T, n int, size int64) *CentralServer {
+	t.Helper()
+	oldTorrents := TORRENTS_DIR
+	TORRENTS_DIR = t.TempDir()
+	t.Cleanup(func() { TORRENTS_DIR = oldTorrents })
+
+	store := openTestStore(t, t.TempDir())
+	t.Cleanup(func() { store.Close() })
+	s := NewCentralServer(store)
+
+	for i := range n {
+		name := fmt.Sprintf("song%02d.mp3", i)
+		metadata := TorrentMetadata{FileName: name, FileSize: size, Checksum: name, Peers: []string{"uploader"}}
+		entry := IndexEntry{FileName: name, TorrentFile: torrentName(name), Peers: metadata.Peers}
+		if err := s.commit(indexCommand{Op: "put", Entry: entry, Torrent: &metadata}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return s
+}
+
+// addFakeContributor registers a contributor with free bytes of room, or
+// unlimited room when free is 0, and answers its peer service with a fake.
+func addFakeContributor(t *testing.T, s *CentralServer, addr string, free int64) *fakeContributor {
+	t.Helper()
+	req := &pb.ContributorRequest{ContriAddr: addr, CapacityBytes: free, FreeBytes: free}
+	if res, err := s.RegisterContributor(context.Background(), req); err != nil || res.Status != 200 {
+		t.Fatalf("registering %s: %v %v", addr, res, err)
+	}
+	fake := &fakeContributor{addr: addr, server: s}
+	s.mu.Lock()
+	s.cNodes[addr].Client = fake
+	s.mu.Unlock()
+	return fake
+}
+
+// waitIdle waits until no fetch or drop is running.
+func waitIdle(t *testing.T, r *ReplicationManager) {
+	t.Helper()
+	deadline := time.Now().Add(10 * time.Second)
+	for {
+		r.mu.Lock()
+		idle := r.inFlight() == 0 && len(r.dropping) == 0
+		r.mu.Unlock()
+		if idle {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("replication still running")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+// settle runs replication rounds until one changes nothing in the index.
+func settle(t *testing.T, s *CentralServer) {
+	t.Helper()
+	for range 20 {
+		before := s.store.All()
+		s.replication.reconcile()
+		waitIdle(t, s.replication)
+		if reflect.DeepEqual(before, s.store.All()) {
+			return
+		}
+	}
+	t.Fatal("replication did not settle")
+}
+
+func TestReplicationPlacement(t *testing.T) {
+	s := newReplicationTestServer(t, 10, 1000)
+	for i := range 5 {
+		addFakeContributor(t, s, fmt.Sprintf("c%d", i), 0)
+	}
+	settle(t, s)
+
+	contributors := s.liveContributors()
+	for _, entry := range s.store.All() {
+		replicas := liveReplicas(entry, contributors)
+		owners := s.replication.owners(entry.FileName, 1000, s.replicationFactor, contributors, replicas)
+		slices.Sort(owners)
+		got := slices.Clone(entry.Contributors)
+		slices.Sort(got)
+		if len(got) != s.replicationFactor || !slices.Equal(got, owners) {
+			t.Errorf("%s replicated to %v, want its owners %v", entry.FileName, got, owners)
+		}
+		for _, contributor := range got {
+			if !containsString(entry.Peers, contributor) {
+				t.Errorf("%s lists contributor %s, which does not seed it", entry.FileName, contributor)
+			}
+		}
+	}
+}
+
+// A contributor takes no more files than its free space holds, even within
+// one round.
+func TestReplicationRespectsCapacity(t *testing.T) {
+	s := newReplicationTestServer(t, 4, 1000)
+	full := addFakeContributor(t, s, "full", 999)
+	small := addFakeContributor(t, s, "small", 2500)
+	addFakeContributor(t, s, "big1", 0)
+	addFakeContributor(t, s, "big2", 0)
+	settle(t, s)
+
+	if fetched, _ := full.calls(); len(fetched) != 0 {
+		t.Errorf("full contributor fetched %v", fetched)
+	}
+	fetched, _ := small.calls()
+	s.mu.Lock()
+	free := s.cNodes["small"].FreeBytes
+	s.mu.Unlock()
+	if len(fetched) != 2 || free != 500 {
+		t.Errorf("contributor with room for 2 files fetched %v, %d bytes left", fetched, free)
+	}
+	replicas := make(map[int]int) // replica count -> files
+	for _, entry := range s.store.All() {
+		replicas[len(entry.Contributors)]++
+	}
+	if want := map[int]int{2: 2, 3: 2}; !reflect.DeepEqual(replicas, want) {
+		t.Errorf("files per replica count = %v, want %v", replicas, want)
+	}
+}
+
+// Replicas on a contributor whose lease lapsed are placed elsewhere.
+func TestReplicationReplacesDeadContributor(t *testing.T) {
+	s := newReplicationTestServer(t, 6, 1000)
+	for i := range 4 {
+		addFakeContributor(t, s, fmt.Sprintf("c%d", i), 0)
+	}
+	settle(t, s)
+
+	var dead string
+	for _, entry := range s.store.All() {
+		dead = entry.Contributors[0]
+		break
+	}
+	s.mu.Lock()
+	s.cNodes[dead].Expires = time.Now().Add(-time.Second)
+	s.mu.Unlock()
+	settle(t, s)
+
+	for _, entry := range s.store.All() {
+		live := slices.DeleteFunc(slices.Clone(entry.Contributors), func(c string) bool { return c == dead })
+		if len(live) != s.replicationFactor {
+			t.Errorf("%s replicated to %v with %s dead", entry.FileName, entry.Contributors, dead)
+		}
+	}
+}
+
+func TestReplicationInFlightCap(t *testing.T) {
+	s := newReplicationTestServer(t, 10, 1000)
+	hold := make(chan struct{})
+	for i := range 3 {
+		addFakeContributor(t, s, fmt.Sprintf("c%d", i), 0).hold = hold
+	}
+
+	for range 3 {
+		s.replication.reconcile()
+	}
+	s.replication.mu.Lock()
+	running := s.replication.inFlight()
+	s.replication.mu.Unlock()
+	if running != maxReplicationsInFlight {
+		t.Errorf("%d fetches running, want %d", running, maxReplicationsInFlight)
+	}
+
+	close(hold)
+	settle(t, s)
+	for _, entry := range s.store.All() {
+		if len(entry.Contributors) != 3 {
+			t.Errorf("%s replicated to %v", entry.FileName, entry.Contributors)
+		}
+	}
+}
+
+// A new contributor takes over only the files it now owns, at most
+// maxMovesInFlight at a time, and an old owner drops its copy only once the
+// new one is confirmed.
+func TestReplicationRebalance(t *testing.T) {
+	s := newReplicationTestServer(t, 20, 1000)
+	s.replicationFactor = 2
+	old := make(map[string]*fakeContributor)
+	for _, addr := range []string{"c0", "c1", "c2", "c3"} {
+		old[addr] = addFakeContributor(t, s, addr, 0)
+	}
+	settle(t, s)
+	before := make(map[string][]string)
+	for _, entry := range s.store.All() {
+		before[entry.FileName] = entry.Contributors
+	}
+	fetchedBefore := make(map[string]int)
+	for addr, fake := range old {
+		fetched, _ := fake.calls()
+		fetchedBefore[addr] = len(fetched)
+	}
+
+	joining := addFakeContributor(t, s, "c4", 0)
+	joining.hold = make(chan struct{})
+	moved := make(map[string]bool)
+	contributors := s.liveContributors()
+	for _, entry := range s.store.All() {
+		owners := s.replication.owners(entry.FileName, 1000, 2, contributors, liveReplicas(entry, contributors))
+		if containsString(owners, "c4") {
+			moved[entry.FileName] = true
+		}
+	}
+	if len(moved) <= maxMovesInFlight {
+		t.Fatalf("c4 owns only %d files", len(moved))
+	}
+
+	for range 3 {
+		s.replication.reconcile()
+	}
+	s.replication.mu.Lock()
+	moves, running := s.replication.moves, s.replication.inFlight()
+	s.replication.mu.Unlock()
+	if moves != maxMovesInFlight || running != maxMovesInFlight {
+		t.Errorf("%d moves of %d fetches running, want %d", moves, running, maxMovesInFlight)
+	}
+	for addr, fake := range old {
+		if _, dropped := fake.calls(); len(dropped) > 0 {
+			t.Errorf("%s dropped %v before c4 held them", addr, dropped)
+		}
+	}
+
+	close(joining.hold)
+	settle(t, s)
+	fetched, _ := joining.calls()
+	for _, entry := range s.store.All() {
+		name := entry.FileName
+		if moved[name] != containsString(fetched, name) {
+			t.Errorf("%s moved %v, fetched by c4 %v", name, moved[name], containsString(fetched, name))
+		}
+		if !moved[name] && !slices.Equal(entry.Contributors, before[name]) {
+			t.Errorf("%s moved from %v to %v", name, before[name], entry.Contributors)
+		}
+		if len(entry.Contributors) != 2 || moved[name] != containsString(entry.Contributors, "c4") {
+			t.Errorf("%s replicated to %v", name, entry.Contributors)
+		}
+	}
+	for addr, fake := range old {
+		fetched, dropped := fake.calls()
+		if len(fetched) != fetchedBefore[addr] {
+			t.Errorf("%s fetched %v after c4 joined", addr, fetched[fetchedBefore[addr]:])
+		}
+		for _, name := range dropped {
+			entry, _ := s.store.Get(name)
+			if !moved[name] || !containsString(before[name], addr) || containsString(entry.Contributors, addr) {
+				t.Errorf("%s dropped %s", addr, name)
+			}
+		}
+	}
+}
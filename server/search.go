@@ -0,0 +1,171 @@
+package main
+
+import (
+	"context"
+	"encoding/base64"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lithammer/fuzzysearch/fuzzy"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "napster"
+)
+
+const (
+	defaultPageSize = 20
+	maxPageSize     = 100
+
+	// wholeQueryBonus rewards songs whose full name or artist fuzzily
+	// contains the query as typed, on top of the per-token scores.
+	wholeQueryBonus = 1.0
+)
+
+// searchFilter holds the optional constraints of a search. Zero values are ignored.
+type searchFilter struct {
+	artist         string
+	minDuration    int64
+	maxDuration    int64
+	uploadedAfter  time.Time
+	uploadedBefore time.Time
+	minSeeders     int
+	onlyAvailable  bool
+}
+
+func (f *searchFilter) matches(song *IndexedSong, info *pb.SongInfo) bool {
+	if f.artist != "" && !strings.Contains(strings.ToLower(song.ArtistName), f.artist) {
+		return false
+	}
+	if f.minDuration > 0 && song.Duration < f.minDuration {
+		return false
+	}
+	if f.maxDuration > 0 && song.Duration > f.maxDuration {
+		return false
+	}
+	if !f.uploadedAfter.IsZero() || !f.uploadedBefore.IsZero() {
+		createdAt, err := time.Parse(time.RFC3339, song.CreatedAt)
+		if err != nil {
+			return false
+		}
+		if !f.uploadedAfter.IsZero() && createdAt.Before(f.uploadedAfter) {
+			return false
+		}
+		if !f.uploadedBefore.IsZero() && createdAt.After(f.uploadedBefore) {
+			return false
+		}
+	}
+	if int(info.LiveSeeders) < f.minSeeders {
+		return false
+	}
+	if f.onlyAvailable && !info.Available {
+		return false
+	}
+	return true
+}
+
+// rankedResults runs the query against the search index, applies the filter
+// and orders the results by relevance, then live seeders, then name.
+func (s *CentralServer) rankedResults(query string, filter searchFilter) []*pb.SongInfo {
+	hits := s.searchIndex.Search(query)
+	query = strings.ToLower(query)
+
+	// Only the leases need s.mu; the hits are copies, so scoring and
+	// filtering them does not hold up uploads and heartbeats.
+	alive, contributing := s.liveness(), s.contributorLiveness()
+	results := make([]*pb.SongInfo, 0, len(hits))
+	for _, hit := range hits {
+		info := s.songInfo(&hit.Song, alive, contributing)
+		if !filter.matches(&hit.Song, info) {
+			continue
+		}
+		info.Score = hit.Relevance
+		if query != "" && (fuzzy.MatchNormalized(query, strings.ToLower(hit.Song.FileName)) ||
+			fuzzy.MatchNormalized(query, strings.ToLower(hit.Song.ArtistName))) {
+			info.Score += wholeQueryBonus
+		}
+		results = append(results, info)
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		if results[i].LiveSeeders != results[j].LiveSeeders {
+			return results[i].LiveSeeders > results[j].LiveSeeders
+		}
+		return results[i].FileName < results[j].FileName
+	})
+	return results
+}
+
+// RankedSearch returns one page of relevance-ordered, filtered search results.
+func (s *CentralServer) RankedSearch(ctx context.Context, req *pb.RankedSearchRequest) (*pb.RankedSearchResponse, error) {
+	filter := searchFilter{
+		artist:      strings.ToLower(strings.TrimSpace(req.Artist)),
+		minDuration: int64(req.MinDuration),
+		maxDuration: int64(req.MaxDuration),
+		minSeeders:  int(req.MinSeeders),
+	}
+	var err error
+	if req.UploadedAfter != "" {
+		if filter.uploadedAfter, err = time.Parse(time.RFC3339, req.UploadedAfter); err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "uploaded_after: %v", err)
+		}
+	}
+	if req.UploadedBefore != "" {
+		if filter.uploadedBefore, err = time.Parse(time.RFC3339, req.UploadedBefore); err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "uploaded_before: %v", err)
+		}
+	}
+
+	pageSize := int(req.PageSize)
+	if pageSize <= 0 {
+		pageSize = defaultPageSize
+	} else if pageSize > maxPageSize {
+		pageSize = maxPageSize
+	}
+	offset, err := decodePageToken(req.PageToken)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid page_token")
+	}
+
+	results := s.rankedResults(req.Query, filter)
+	total := len(results)
+	if offset > total {
+		offset = total
+	}
+	end := min(offset+pageSize, total)
+
+	nextToken := ""
+	if end < total {
+		nextToken = encodePageToken(end)
+	}
+	return &pb.RankedSearchResponse{
+		Results:       results[offset:end],
+		NextPageToken: nextToken,
+		TotalResults:  int32(total),
+	}, nil
+}
+
+// Page tokens are opaque to clients; they encode the offset of the next page.
+func encodePageToken(offset int) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.Itoa(offset)))
+}
+
+func decodePageToken(token string) (int, error) {
+	if token == "" {
+		return 0, nil
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return 0, err
+	}
+	offset, err := strconv.Atoi(string(raw))
+	if err != nil || offset < 0 {
+		return 0, status.Error(codes.InvalidArgument, "bad offset")
+	}
+	return offset, nil
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	pb "napster"
+)
+
+func newSearchTestServer(t *testing.T) *CentralServer {
+	t.Helper()
+	store := openTestStore(t, t.TempDir())
+	t.Cleanup(func() { store.Close() })
+	s := NewCentralServer(store)
+
+	live := PeerLease{Alive: true, Expires: time.Now().Add(time.Hour)}
+	s.peerStatus["peer1"] = &live
+	s.peerStatus["peer2"] = &live
+	s.peerStatus["gone"] = &PeerLease{Alive: true, Expires: time.Now().Add(-time.Minute)}
+
+	for _, song := range []IndexedSong{
+		{FileName: "Yesterday.mp3", ArtistName: "The Beatles", Duration: 125, CreatedAt: "2024-01-10T00:00:00Z", Peers: []string{"peer1", "peer2"}},
+		{FileName: "Let It Be.mp3", ArtistName: "The Beatles", Duration: 243, CreatedAt: "2024-03-01T00:00:00Z", Peers: []string{"peer1"}},
+		{FileName: "Help.mp3", ArtistName: "The Beatles", Duration: 138, CreatedAt: "2024-02-01T00:00:00Z", Peers: []string{"gone"}},
+		{FileName: "Hallelujah.mp3", ArtistName: "Leonard Cohen", Duration: 280, CreatedAt: "2023-06-01T00:00:00Z", Peers: []string{"peer2"}},
+	} {
+		s.searchIndex.Put(song)
+		store.Put(IndexEntry{FileName: song.FileName, Peers: song.Peers})
+	}
+	return s
+}
+
+func resultNames(results []*pb.SongInfo) []string {
+	var names []string
+	for _, info := range results {
+		names = append(names, info.FileName)
+	}
+	return names
+}
+
+func TestRankedResults(t *testing.T) {
+	s := newSearchTestServer(t)
+
+	tests := []struct {
+		name   string
+		query  string
+		filter searchFilter
+		want   []string
+	}{
+		// Equal relevance is ordered by live seeders, then by name.
+		{"by seeders", "beatles", searchFilter{}, []string{"Yesterday.mp3", "Let It Be.mp3", "Help.mp3"}},
+		{"exact before fuzzy", "help", searchFilter{}, []string{"Help.mp3"}},
+		{"whole query bonus", "let it", searchFilter{}, []string{"Let It Be.mp3"}},
+		{"artist", "", searchFilter{artist: "cohen"}, []string{"Hallelujah.mp3"}},
+		{"duration", "beatles", searchFilter{minDuration: 130, maxDuration: 250}, []string{"Let It Be.mp3", "Help.mp3"}},
+		{"uploaded", "beatles", searchFilter{uploadedAfter: time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC)}, []string{"Let It Be.mp3", "Help.mp3"}},
+		{"min seeders", "", searchFilter{minSeeders: 1}, []string{"Yesterday.mp3", "Hallelujah.mp3", "Let It Be.mp3"}},
+		{"only available", "beatles", searchFilter{onlyAvailable: true}, []string{"Yesterday.mp3", "Let It Be.mp3"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := resultNames(s.rankedResults(tt.query, tt.filter)); !slices.Equal(got, tt.want) {
+				t.Errorf("rankedResults(%q) = %v, want %v", tt.query, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRankedResultsCountsLivePeers(t *testing.T) {
+	s := newSearchTestServer(t)
+	results := s.rankedResults("help", searchFilter{})
+	if len(results) != 1 || results[0].LiveSeeders != 0 || results[0].Available {
+		t.Fatalf("expired seeder counted: %+v", results)
+	}
+}
+
+func TestRankedSearchPages(t *testing.T) {
+	s := newSearchTestServer(t)
+
+	var names []string
+	token := ""
+	for page := 0; ; page++ {
+		res, err := s.RankedSearch(context.Background(), &pb.RankedSearchRequest{PageSize: 3, PageToken: token})
+		if err != nil {
+			t.Fatalf("page %d: %v", page, err)
+		}
+		if res.TotalResults != 4 {
+			t.Fatalf("TotalResults = %d, want 4", res.TotalResults)
+		}
+		names = append(names, resultNames(res.Results)...)
+		if token = res.NextPageToken; token == "" {
+			break
+		}
+	}
+	want := resultNames(s.rankedResults("", searchFilter{}))
+	if !slices.Equal(names, want) {
+		t.Fatalf("pages = %v, want %v", names, want)
+	}
+
+	if _, err := s.RankedSearch(context.Background(), &pb.RankedSearchRequest{PageToken: "!!"}); err == nil {
+		t.Fatal("bad page token accepted")
+	}
+}
+
+// Contributors count by their contributor lease, whether or not they also
+// registered as peers.
+func TestSongInfoCountsLiveContributors(t *testing.T) {
+	s := newSearchTestServer(t)
+	s.cNodes["c1"] = &Contributor{Expires: time.Now().Add(time.Hour)}
+	s.cNodes["c2"] = &Contributor{Expires: time.Now().Add(time.Hour)}
+	s.cNodes["lapsed"] = &Contributor{Expires: time.Now().Add(-time.Minute)}
+	s.searchIndex.Put(IndexedSong{FileName: "Replicated.mp3"})
+	s.store.Put(IndexEntry{FileName: "Replicated.mp3", Contributors: []string{"c1", "lapsed", "peer1"}})
+	s.searchIndex.Put(IndexedSong{FileName: "Sharded.mp3"})
+	s.store.Put(IndexEntry{FileName: "Sharded.mp3", Shards: []string{"c1", "c2", "lapsed"}, DataShards: 2})
+	s.searchIndex.Put(IndexedSong{FileName: "Shardless.mp3"})
+	s.store.Put(IndexEntry{FileName: "Shardless.mp3", Shards: []string{"c1", "lapsed", ""}, DataShards: 2})
+
+	tests := []struct {
+		query        string
+		contributors int32
+		available    bool
+	}{
+		{"replicated", 1, false},
+		{"sharded", 0, true},
+		{"shardless", 0, false},
+	}
+	for _, tt := range tests {
+		results := s.rankedResults(tt.query, searchFilter{})
+		if len(results) != 1 || results[0].Contributors != tt.contributors || results[0].Available != tt.available {
+			t.Errorf("%s: %+v, want %d contributors, available %v", tt.query, results, tt.contributors, tt.available)
+		}
+	}
+}
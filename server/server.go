@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"crypto/rand"
 	"crypto/sha256"
 	"encoding/hex"
 	"encoding/json"
@@ -11,79 +12,155 @@ import (
 	"log"
 	"net"
 	"os"
+	"os/signal"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"sync"
+	"syscall"
 	"time"
 
-	"github.com/lithammer/fuzzysearch/fuzzy"
 	"github.com/stathat/consistent"
 
 	pb "napster"
 
 	"google.golang.org/grpc"
-	"google.golang.org/grpc/credentials/insecure"
 )
 
 var debug_mode = false;
 var TORRENTS_DIR = "./torrents";
 
-// CentralServer holds the peer status and a mapping from original file path to peers.
+// CentralServer holds the peer status and the durable file index.
 type CentralServer struct {
 	pb.UnimplementedCentralServerServer
 	mu                	sync.Mutex
-	fileMap			  	map[string]string
+	store			  	*Store				// file name -> torrent, peers, contributors
+	searchIndex			*SearchIndex		// tokens of name/artist -> songs
+	checksums			map[string]string	// full-file checksum -> canonical file name
+	checksumsMu			sync.Mutex			// guards checksums, which index changes update without s.mu
+	uploading			map[string]bool		// file names claimed by uploads not indexed yet
+	uploadingMu			sync.Mutex			// guards uploading
 	// chunkIndex       map[string][]string // mapping: original filePath -> list of peerAddresses
-	peerStatus        	map[string]bool     // Peer health status
+	peerStatus        	map[string]*PeerLease // Peer leases, renewed by heartbeats
 	replicationFactor 	int                 // Number of replicas per file
 	ContributorHashring *consistent.Consistent
-	cNodes				map[string]pb.PeerServiceClient
+	cNodes				map[string]*Contributor	// contributor address -> connection, capacity and lease
+	replication			*ReplicationManager	// keeps replicationFactor live copies of every file
+	dataShards			int					// erasure layout of new uploads, 0 for full replication
+	parityShards		int
+	raft				*Raft				// consensus log shared with the other servers, nil when running alone
+	federation			*Federation			// other indexes searches are forwarded to, nil when not federated
 }
 
-func NewCentralServer() *CentralServer {
-	return &CentralServer{
-		fileMap:        make(map[string]string),
-		peerStatus:        make(map[string]bool),
+func NewCentralServer(store *Store) *CentralServer {
+	s := &CentralServer{
+		store:          store,
+		searchIndex:    NewSearchIndex(),
+		checksums:      make(map[string]string),
+		uploading:      make(map[string]bool),
+		peerStatus:        make(map[string]*PeerLease),
 		replicationFactor: 3,
 		ContributorHashring: consistent.New(),
-		cNodes: make(map[string]pb.PeerServiceClient),
+		cNodes: make(map[string]*Contributor),
 	}
+	s.replication = NewReplicationManager(s)
+	return s
 }
 
-func (s *CentralServer) RegisterContributor(ctx context.Context, req *pb.ContributorRequest) (*pb.GenResponse, error) {
+// RebuildIndex reconciles the store with the torrents present in TORRENTS_DIR.
+// Torrents missing from the store (e.g. uploaded before the store existed) are
+// indexed from their metadata, entries whose torrent was deleted are dropped,
+// and torrents whose peer list diverged from the store (crash between the two
+// writes) are rewritten from the store, which is authoritative.
+func (s *CentralServer) RebuildIndex() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
 
-	if _, exists := s.cNodes[req.ContriAddr]; exists {
-		return &pb.GenResponse{Status: 204}, nil
+	os.MkdirAll(TORRENTS_DIR, os.ModePerm)
+	files, err := os.ReadDir(TORRENTS_DIR)
+	if err != nil {
+		return err
 	}
 
-	if (len(s.cNodes) > s.replicationFactor * 5) {
-		return &pb.GenResponse{Status: 400}, nil
+	seen := make(map[string]bool)
+	for _, file := range files {
+		if !strings.HasSuffix(file.Name(), ".torrent") {
+			continue
+		}
+		metadata, err := readTorrent(file.Name())
+		if err != nil || metadata.FileName == "" {
+			log.Printf("Skipping unreadable torrent %s: %v", file.Name(), err)
+			continue
+		}
+		seen[metadata.FileName] = true
+
+		entry, exists := s.store.Get(metadata.FileName)
+		if !exists {
+			entry = IndexEntry{
+				FileName:    metadata.FileName,
+				TorrentFile: file.Name(),
+				Peers:       metadata.Peers,
+			}
+			if metadata.Erasure != nil {
+				entry.Shards = metadata.Erasure.Holders
+				entry.DataShards = metadata.Erasure.DataShards
+			}
+			if err := s.store.Put(entry); err != nil {
+				return err
+			}
+		} else if !equalStrings(entry.Peers, metadata.Peers) ||
+			(metadata.Erasure != nil && !equalStrings(entry.Shards, metadata.Erasure.Holders)) {
+			metadata.Peers = entry.Peers
+			if metadata.Erasure != nil {
+				metadata.Erasure.Holders = entry.Shards
+			}
+			if err := writeTorrent(entry.TorrentFile, &metadata); err != nil {
+				log.Printf("Failed to repair torrent %s: %v", entry.TorrentFile, err)
+			}
+		}
+		s.searchIndex.Put(indexedSong(&metadata))
+		s.checksumsMu.Lock()
+		if _, exists := s.checksums[metadata.Checksum]; !exists {
+			s.checksums[metadata.Checksum] = metadata.FileName
+		}
+		s.checksumsMu.Unlock()
 	}
 
-	conn, err := grpc.NewClient(req.ContriAddr, grpc.WithTransportCredentials(insecure.NewCredentials()))
-	if err != nil {
-		return &pb.GenResponse{}, err
+	for _, entry := range s.store.All() {
+		if !seen[entry.FileName] {
+			if err := s.store.Delete(entry.FileName); err != nil {
+				return err
+			}
+			s.searchIndex.Remove(entry.FileName)
+		}
 	}
-	
-	client := pb.NewPeerServiceClient(conn)
-	s.cNodes[req.ContriAddr] = client
-	s.ContributorHashring.Add(req.ContriAddr)
 
-	log.Printf("Added Contributor %s", req.ContriAddr);
-	
-	return &pb.GenResponse{Status: 200}, nil
+	log.Printf("Index rebuilt: %d files", len(seen))
+	return nil
 }
 
 // --- UploadFile ---
-// Client-streaming RPC where the client sends file chunks. The server appends a random string
-// to the file name on the first chunk, computes per-chunk and overall checksums, and generates a torrent.
+// Client-streaming RPC where the client sends file chunks. The server makes the file name
+// unique on the first chunk, computes per-chunk and overall checksums, and generates a torrent.
 func (s *CentralServer) UploadFile(stream pb.CentralServer_UploadFileServer) error {
 	var metadata TorrentMetadata
 	metadata.ChunkChecksums = make(map[int]string)
 	sha256Hasher := sha256.New()
 	chunkIndex := 0
+	var receivedBytes int64
+	var declaredSize int64
+	var declaredChecksum string
+	lastChunkLen := ChunkSize
+
+	// In erasure-coded mode parity checksums are computed as stripes fill up.
+	var encoder *stripeEncoder
+	if s.dataShards > 0 {
+		var err error
+		if encoder, err = newStripeEncoder(s.dataShards, s.parityShards); err != nil {
+			return err
+		}
+	}
 
-    // var newFileName string
 	// Receive streamed file chunks.
 	for {
 		req, err := stream.Recv()
@@ -111,10 +188,39 @@ func (s *CentralServer) UploadFile(stream pb.CentralServer_UploadFileServer) err
 					Status:         401,
 					Message:        "File name contains '_chunk' which is not allowed.",
 				})
+			} else if req.DeclaredSize <= 0 || req.Checksum == "" {
+				return stream.SendAndClose(&pb.UploadResponse{
+					Status:         400,
+					Message:        "Chunk 0 must declare the file size and checksum",
+				})
 			}
+			declaredSize = req.DeclaredSize
+			declaredChecksum = req.Checksum
+
+			// Index the file under a collision-free name so uploads of
+			// different songs with the same name never overwrite each other.
+			metadata.FileName = s.uniqueFileName(req.FileName)
+			defer s.releaseFileName(metadata.FileName)
 		}
 		data := req.ChunkData
 
+		// Every chunk but the last must be exactly ChunkSize, otherwise the
+		// chunk checksums would not line up with the chunks peers serve.
+		if lastChunkLen != ChunkSize || len(data) == 0 || len(data) > ChunkSize {
+			return stream.SendAndClose(&pb.UploadResponse{
+				Status:         400,
+				Message:        fmt.Sprintf("Chunk %d has an invalid size", chunkIndex),
+			})
+		}
+		lastChunkLen = len(data)
+		receivedBytes += int64(len(data))
+		if receivedBytes > declaredSize {
+			return stream.SendAndClose(&pb.UploadResponse{
+				Status:         422,
+				Message:        fmt.Sprintf("Received more than the declared %d bytes", declaredSize),
+			})
+		}
+
 		// Update overall file checksum.
 		sha256Hasher.Write(data)
 
@@ -122,51 +228,134 @@ func (s *CentralServer) UploadFile(stream pb.CentralServer_UploadFileServer) err
 		chunkChecksum := computeDataChecksum(data)
 		metadata.ChunkChecksums[chunkIndex] = chunkChecksum
 		// log.Print(chunkIndex, chunkChecksum)
+		if encoder != nil {
+			if err := encoder.add(data); err != nil {
+				return err
+			}
+		}
 
 		chunkIndex++
 	}
 
+	if receivedBytes == 0 {
+		return stream.SendAndClose(&pb.UploadResponse{
+			Status:         400,
+			Message:        "Empty files cannot be uploaded",
+		})
+	}
+
 	// Finalize overall checksum and update metadata.
 	metadata.Checksum = hex.EncodeToString(sha256Hasher.Sum(nil))
 	metadata.CreatedAt = time.Now().Format(time.RFC3339)
-	metadata.FileSize = int64(chunkIndex * ChunkSize) // Assuming all chunks are full size.
+	metadata.FileSize = receivedBytes
+
+	if receivedBytes != declaredSize {
+		return stream.SendAndClose(&pb.UploadResponse{
+			Status:         422,
+			Message:        fmt.Sprintf("Received %d bytes, declared %d", receivedBytes, declaredSize),
+		})
+	}
+	if metadata.Checksum != declaredChecksum {
+		return stream.SendAndClose(&pb.UploadResponse{
+			Status:         422,
+			Message:        "Checksum of the received data does not match the declared checksum",
+		})
+	}
+
+	// Same content is already indexed: list the uploader as a seeder of the
+	// existing torrent. This waits for the whole stream, so only peers that
+	// actually sent the content become its seeders, not any client that
+	// knows its checksum.
+	if canonical, ok := s.deduplicate(metadata.Checksum, metadata.Peers[0]); ok {
+		return stream.SendAndClose(canonical)
+	}
 	
 	// metadata.Peers = --- During loadbalancing, this will be filled with the list of peers.
+	if encoder != nil {
+		layout, err := encoder.finish()
+		if err != nil {
+			return err
+		}
+		metadata.Erasure = layout
+	}
 
-	// Generate torrent file.
-	torrentsDir := TORRENTS_DIR
-	os.MkdirAll(torrentsDir, os.ModePerm)
-	torrentFileName, err := generateTorrentFile(&metadata, torrentsDir)
-	if err != nil {
-		log.Printf("Error generating torrent file: %v", err)
-		return err
+	// Index the file; the torrent is generated as part of the change.
+	torrentFileName := torrentName(metadata.FileName)
+	entry := IndexEntry{
+		FileName:    metadata.FileName,
+		TorrentFile: torrentFileName,
+		Peers:       metadata.Peers,
+	}
+	if metadata.Erasure != nil {
+		entry.Shards = metadata.Erasure.Holders
+		entry.DataShards = metadata.Erasure.DataShards
 	}
 
-	s.fileMap[metadata.FileName] = torrentFileName
+	if err := s.commit(indexCommand{Op: "put", Entry: entry, Torrent: &metadata}); err != nil {
+		log.Printf("Error indexing %s: %v", metadata.FileName, err)
+		return err
+	}
 
-	go func() {
-		for _ = range min(3, len(s.cNodes)) {
-			clientAddr, _ := s.ContributorHashring.Get(metadata.CreatedAt)
-			resp, err := s.cNodes[clientAddr].DownloadThisFile(context.Background(), &pb.SearchRequest{
-				Query: metadata.FileName,
-			})
-			if err != nil {
-				log.Printf("%s %v", resp, err)
-			}
-		}
-	}()
+	s.replication.Kick()
 
 	// Respond to the client with the torrent file info.
 	return stream.SendAndClose(&pb.UploadResponse{
 		Status:         200,
 		TorrentFileName: torrentFileName,
-		// RenamedFileName: newFileName,
+		RenamedFileName: metadata.FileName,
 		Message:         "Torrent file generated successfully",
 	})
 }
 
 // --- Functions for Chunking and Torrent File Generation ---
 
+const suffixAlphabet = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+
+// uniqueFileName keeps an uploaded file's name unless the index,
+// TORRENTS_DIR or another upload already holds it. On a collision it
+// appends a random suffix (musix.mp3 -> musix_k3Xq9a.mp3), retrying until
+// the name is free. The name is claimed until releaseFileName.
+func (s *CentralServer) uniqueFileName(original string) string {
+	s.uploadingMu.Lock()
+	defer s.uploadingMu.Unlock()
+
+	name := original
+	ext := filepath.Ext(original)
+	base := strings.TrimSuffix(original, ext)
+	for !s.fileNameFree(name) {
+		suffix := make([]byte, 6)
+		random := make([]byte, len(suffix))
+		rand.Read(random)
+		for i, b := range random {
+			suffix[i] = suffixAlphabet[int(b)%len(suffixAlphabet)]
+		}
+		name = fmt.Sprintf("%s_%s%s", base, suffix, ext)
+	}
+	s.uploading[name] = true
+	return name
+}
+
+// fileNameFree reports whether no upload claimed name and neither the index
+// nor TORRENTS_DIR holds it or its torrent. Callers must hold s.uploadingMu.
+func (s *CentralServer) fileNameFree(name string) bool {
+	if s.uploading[name] {
+		return false
+	}
+	if _, exists := s.store.Get(name); exists {
+		return false
+	}
+	_, err := os.Stat(filepath.Join(TORRENTS_DIR, torrentName(name)))
+	return err != nil
+}
+
+// releaseFileName ends an upload's claim on its name, which the index holds
+// from then on if the upload succeeded.
+func (s *CentralServer) releaseFileName(name string) {
+	s.uploadingMu.Lock()
+	defer s.uploadingMu.Unlock()
+	delete(s.uploading, name)
+}
+
 // ChunkSize is fixed at 64KB. (2^6 * 2^10)
 const ChunkSize = 1 << 18
 
@@ -182,6 +371,7 @@ type TorrentMetadata struct {
 	CreatedAt      string         `json:"created_at"`  // Creation timestamp
 	Duration       int64          `json:"duration"`    // e.g., number of chunks
 	Status 		   string 		  `json:"status"`
+	Erasure        *ErasureLayout `json:"erasure,omitempty"` // set for erasure-coded files
 }
 
 // computeDataChecksum returns the SHA-256 checksum for the given data.
@@ -190,149 +380,202 @@ func computeDataChecksum(data []byte) string {
 	return hex.EncodeToString(sum[:])
 }
 
-// generateTorrentFile writes the TorrentMetadata as a JSON file to outputDir and returns the file name.
-func generateTorrentFile(metadata *TorrentMetadata, outputDir string) (string, error) {
-	torrentFileName := fmt.Sprintf("%s.torrent", strings.TrimSuffix(metadata.FileName, filepath.Ext(metadata.FileName)))
-	torrentFilePath := filepath.Join(outputDir, torrentFileName)
-	data, err := json.MarshalIndent(metadata, "", "  ")
-	if err != nil {
-		return "", err
-	}
-	err = os.WriteFile(torrentFilePath, data, 0644)
+// torrentName returns the name of a file's torrent inside TORRENTS_DIR.
+func torrentName(fileName string) string {
+	return fmt.Sprintf("%s.torrent", strings.TrimSuffix(fileName, filepath.Ext(fileName)))
+}
+
+// readTorrent parses a torrent stored in TORRENTS_DIR.
+func readTorrent(torrentFile string) (TorrentMetadata, error) {
+	var metadata TorrentMetadata
+	data, err := os.ReadFile(filepath.Join(TORRENTS_DIR, torrentFile))
 	if err != nil {
-		return "", err
+		return metadata, err
 	}
-	return torrentFileName, nil
+	err = json.Unmarshal(data, &metadata)
+	return metadata, err
 }
 
-func (s *CentralServer) EnableSeeding(ctx context.Context, req *pb.SeedingRequest) (*pb.GenResponse, error) {
-	torrent_file := filepath.Join(TORRENTS_DIR, s.fileMap[req.FileName])
-
-	data, err := os.ReadFile(torrent_file)
+// writeTorrent atomically writes a torrent to TORRENTS_DIR.
+func writeTorrent(torrentFile string, metadata *TorrentMetadata) error {
+	data, err := json.MarshalIndent(metadata, "", "  ")
 	if err != nil {
-		return &pb.GenResponse{}, err
+		return err
 	}
+	os.MkdirAll(TORRENTS_DIR, os.ModePerm)
+	return writeFileAtomic(filepath.Join(TORRENTS_DIR, torrentFile), data)
+}
 
-	var metadata TorrentMetadata
-	if err := json.Unmarshal(data, &metadata); err != nil {
-		return &pb.GenResponse{}, err
+// deduplicate looks up an indexed file with the given content checksum. If
+// one exists, peer is added to its seeders and the response pointing the
+// client at the canonical file is returned. checksum must have been computed
+// from the data peer uploaded.
+func (s *CentralServer) deduplicate(checksum string, peer string) (*pb.UploadResponse, bool) {
+	s.checksumsMu.Lock()
+	canonical, exists := s.checksums[checksum]
+	s.checksumsMu.Unlock()
+	if !exists {
+		return nil, false
 	}
 
-	newPeer := req.ClientAddr
-	found := false
-	for _, peer := range metadata.Peers {
-		if peer == newPeer {
-			found = true
-			break
-		}
+	if err := s.addPeer(canonical, peer); err != nil {
+		log.Printf("Failed to deduplicate upload into %s: %v", canonical, err)
+		return nil, false
 	}
-	if !found {
-		metadata.Peers = append(metadata.Peers, newPeer)
+	s.trackSeeding(peer, canonical, true)
+
+	entry, _ := s.store.Get(canonical)
+	log.Printf("Deduplicated upload from %s into %s", peer, canonical)
+	return &pb.UploadResponse{
+		Status:          200,
+		TorrentFileName: entry.TorrentFile,
+		RenamedFileName: canonical,
+		Message:         "File already exists, added as a seeder",
+		Deduplicated:    true,
+	}, true
+}
+
+// addContributor records that contributor holds a replica of fileName.
+func (s *CentralServer) addContributor(fileName string, contributor string) {
+	err := s.changeEntry("add-contributor", fileName, contributor, func(e IndexEntry) bool {
+		return containsString(e.Contributors, contributor)
+	})
+	if err != nil && !os.IsNotExist(err) {
+		log.Printf("Failed to record contributor %s for %s: %v", contributor, fileName, err)
 	}
+}
 
-	// Marshal back and overwrite the .torrent file
-	updatedData, err := json.MarshalIndent(metadata, "", "  ")
-	if err != nil {
-		log.Printf("Failed to marshal updated torrent: %v", err)
-	} else {
-		err = os.WriteFile(torrent_file, updatedData, 0644)
-		if err != nil {
-			log.Printf("Failed to write updated torrent: %v", err)
-		}
+func (s *CentralServer) EnableSeeding(ctx context.Context, req *pb.SeedingRequest) (*pb.GenResponse, error) {
+	err := s.addPeer(req.FileName, req.ClientAddr)
+	if os.IsNotExist(err) {
+		return &pb.GenResponse{Status: 404}, nil
+	} else if err != nil {
+		log.Printf("Failed to enable seeding of %s for %s: %v", req.FileName, req.ClientAddr, err)
+		return &pb.GenResponse{Status: 500}, nil
 	}
+	s.trackSeeding(req.ClientAddr, req.FileName, true)
 
-	return &pb.GenResponse{}, nil
+	return &pb.GenResponse{Status: 200}, nil
 }
 
 func (s *CentralServer) StopSeeding(ctx context.Context, req *pb.SeedingRequest) (*pb.GenResponse, error) {
-	torrent_file := filepath.Join(TORRENTS_DIR, s.fileMap[req.FileName])
-
-	data, err := os.ReadFile(torrent_file)
-	if err != nil {
-		return &pb.GenResponse{}, err
+	err := s.removePeer(req.FileName, req.ClientAddr)
+	if os.IsNotExist(err) {
+		return &pb.GenResponse{Status: 404}, nil
+	} else if err != nil {
+		log.Printf("Failed to stop seeding of %s for %s: %v", req.FileName, req.ClientAddr, err)
+		return &pb.GenResponse{Status: 500}, nil
 	}
+	s.trackSeeding(req.ClientAddr, req.FileName, false)
 
-	var metadata TorrentMetadata
-	if err := json.Unmarshal(data, &metadata); err != nil {
-		return &pb.GenResponse{}, err
-	}
+	return &pb.GenResponse{Status: 200}, nil
+}
 
-	newPeer := req.ClientAddr
-	filteredPeers := make([]string, 0, len(metadata.Peers))
-	for _, peer := range metadata.Peers {
-		if peer != newPeer {
-			filteredPeers = append(filteredPeers, peer)
+func containsString(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
 		}
 	}
-	metadata.Peers = filteredPeers
+	return false
+}
 
-	// Marshal back and overwrite the .torrent file
-	updatedData, err := json.MarshalIndent(metadata, "", "  ")
-	if err != nil {
-		log.Printf("Failed to marshal updated torrent: %v", err)
-	} else {
-		err = os.WriteFile(torrent_file, updatedData, 0644)
-		if err != nil {
-			log.Printf("Failed to write updated torrent: %v", err)
+func removeString(list []string, value string) []string {
+	filtered := make([]string, 0, len(list))
+	for _, item := range list {
+		if item != value {
+			filtered = append(filtered, item)
 		}
 	}
+	return filtered
+}
 
-	return &pb.GenResponse{}, nil
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
 }
 
-// SearchFile returns a list of peer addresses that host the requested file.
+// SearchFile returns every song matching the query, most relevant first,
+// along with their live seeders. A federated server also returns the matches
+// of the indexes it peers with, each annotated with its origin server.
 func (s *CentralServer) SearchFile(ctx context.Context, req *pb.SearchRequest) (*pb.SearchResponse, error) {
-	
-	files, err := os.ReadDir(TORRENTS_DIR)
-	if err != nil {
-		return nil, err
+	results := s.rankedResults(req.Query, searchFilter{onlyAvailable: req.OnlyAvailable})
+	if s.federation != nil {
+		results = s.federatedResults(ctx, req, results)
 	}
+	if req.MaxResults > 0 && len(results) > int(req.MaxResults) {
+		results = results[:req.MaxResults]
+	}
+	return &pb.SearchResponse{Results: results}, nil
+}
 
-	var results []*pb.SongInfo
-	query := strings.ToLower(req.Query)
+// songInfo builds the search result for a song, counting only the peers
+// alive reports as holding a lease, and the contributors and shard holders
+// contributing reports as holding a contributor lease.
+func (s *CentralServer) songInfo(song *IndexedSong, alive func(peer string) bool, contributing func(addr string) bool) *pb.SongInfo {
+	entry, _ := s.store.Get(song.FileName)
 
-	for _, file := range files {
-		if strings.HasSuffix(file.Name(), ".torrent") {
-			data, err := os.ReadFile(filepath.Join(TORRENTS_DIR, file.Name()))
-			if err != nil {
-				continue
-			}
-			var metadata TorrentMetadata
-			if err := json.Unmarshal(data, &metadata); err != nil {
-				continue
-			}
-			songName := strings.ToLower(metadata.FileName)
-			artist := strings.ToLower(metadata.ArtistName)
-
-			if fuzzy.MatchNormalized(query, songName) || fuzzy.MatchNormalized(query, artist){
-				results = append(results, &pb.SongInfo{
-					FileName:     metadata.FileName,
-					ArtistName:   metadata.ArtistName,
-					PeerAddresses: metadata.Peers,
-					CreatedAt:    metadata.CreatedAt,
-				})
-			}
+	var livePeers []string
+	for _, peer := range song.Peers {
+		if alive(peer) {
+			livePeers = append(livePeers, peer)
+		}
+	}
+	liveContributors := 0
+	for _, contributor := range entry.Contributors {
+		if contributing(contributor) {
+			liveContributors++
+		}
+	}
+	liveShards := 0
+	for _, holder := range entry.Shards {
+		if holder != "" && contributing(holder) {
+			liveShards++
 		}
 	}
 
-	return &pb.SearchResponse{Results: results}, nil
+	return &pb.SongInfo{
+		FileName:      song.FileName,
+		ArtistName:    song.ArtistName,
+		PeerAddresses: livePeers,
+		CreatedAt:     song.CreatedAt,
+		Duration:      strconv.FormatInt(song.Duration, 10),
+		LiveSeeders:   int32(len(livePeers)),
+		Contributors:  int32(liveContributors),
+		Available:     len(livePeers) > 0 || (entry.DataShards > 0 && liveShards >= entry.DataShards),
+	}
 }
 
-// MonitorPeers periodically checks the health of registered peers.
+// MonitorPeers periodically expires peers whose lease was not renewed and
+// removes them from the peer lists of their torrents.
 func (s *CentralServer) MonitorPeers() {
 	for {
+		var expired []string
+		now := time.Now()
+
 		s.mu.Lock()
-		for peer := range s.peerStatus {
-			if !s.CheckPeerHealth(peer) {
-				s.peerStatus[peer] = false
-				if debug_mode {
-					log.Printf("Peer %s is offline", peer)
-				}
-			} else {
-				s.peerStatus[peer] = true
+		for peer, lease := range s.peerStatus {
+			if now.After(lease.Expires) {
+				lease.Alive = false
+				expired = append(expired, peer)
+				delete(s.peerStatus, peer)
 			}
 		}
 		s.mu.Unlock()
+
+		for _, peer := range expired {
+			if debug_mode {
+				log.Printf("Peer %s is offline, lease expired", peer)
+			}
+			s.expirePeer(peer)
+		}
 		time.Sleep(5 * time.Second)
 	}
 }
@@ -350,15 +593,12 @@ func (s *CentralServer) CheckPeerHealth(peerAddr string) bool {
 }
 
 func (s *CentralServer) GetTorrent(ctx context.Context, req *pb.SearchRequest) (*pb.TorrentResponse, error) {
-	s.mu.Lock()
-	defer s.mu.Unlock()
-
-	torrentPath, exists := s.fileMap[req.Query]
+	entry, exists := s.store.Get(req.Query)
 	if !exists {
 		return &pb.TorrentResponse{ Status: 404 }, nil
 	}
 	
-	torrentPath = filepath.Join(TORRENTS_DIR, torrentPath)
+	torrentPath := filepath.Join(TORRENTS_DIR, entry.TorrentFile)
 	log.Print(torrentPath)
 
 	content, err := os.ReadFile(torrentPath)
@@ -376,19 +616,94 @@ func (s *CentralServer) GetTorrent(ctx context.Context, req *pb.SearchRequest) (
 
 func main() {
 	port := flag.String("port", "50051", "Port to run the central server")
+	dataDir := flag.String("data", "./index", "Directory for the durable file index")
+	erasureSpec := flag.String("erasure", "", "Erasure-code new uploads as k data + m parity shards, e.g. 4+2 (default: full replication)")
+	clusterSpec := flag.String("cluster", "", "Comma-separated addresses of every central server of the cluster, this one included (default: run alone)")
+	advertise := flag.String("advertise", "", "Address other servers and clients reach this one at (default: localhost:<port>)")
+	federate := flag.String("federate", "", "Comma-separated addresses of central servers of other indexes to forward searches to")
+	federationHops := flag.Int("federation-hops", defaultFederationHops, "How many times a search may be forwarded between federated servers")
 	flag.Parse()
 
+	dataShards, parityShards, err := parseErasureSpec(*erasureSpec)
+	if err != nil {
+		log.Fatalf("%v", err)
+	}
+
+	if *advertise == "" {
+		*advertise = "localhost:" + *port
+	}
+	var clusterPeers []string
+	if *clusterSpec != "" {
+		clusterPeers, err = parseCluster(*clusterSpec, *advertise)
+		if err != nil {
+			log.Fatalf("%v", err)
+		}
+		// Every server of a cluster keeps its own copy of the torrents, so
+		// several of them can run from the same directory.
+		TORRENTS_DIR = filepath.Join(*dataDir, "torrents")
+	}
+
+	store, err := OpenStore(*dataDir)
+	if err != nil {
+		log.Fatalf("Failed to open index store: %v", err)
+	}
+	defer store.Close()
+
 	listener, err := net.Listen("tcp", ":"+*port)
 	if err != nil {
 		log.Fatalf("Failed to listen: %v", err)
 	}
 
-	server := grpc.NewServer()
-	centralServer := NewCentralServer()
+	centralServer := NewCentralServer(store)
+	centralServer.dataShards, centralServer.parityShards = dataShards, parityShards
+	if err := centralServer.RebuildIndex(); err != nil {
+		log.Fatalf("Failed to rebuild index: %v", err)
+	}
+
+	if *federate != "" {
+		federation, err := NewFederation(*advertise, parseFederation(*federate), *federationHops)
+		if err != nil {
+			log.Fatalf("%v", err)
+		}
+		centralServer.federation = federation
+		log.Printf("Federating searches with %s", strings.Join(federation.addrs, ", "))
+	}
+
+	var server *grpc.Server
+	if *clusterSpec != "" {
+		raft, err := NewRaft(*advertise, clusterPeers, *dataDir, centralServer, centralServer.leadershipChanged)
+		if err != nil {
+			log.Fatalf("Failed to open the consensus log: %v", err)
+		}
+		centralServer.raft = raft
+		defer raft.Stop()
+		server = grpc.NewServer(
+			grpc.UnaryInterceptor(centralServer.leaderUnaryInterceptor),
+			grpc.StreamInterceptor(centralServer.leaderStreamInterceptor),
+		)
+		pb.RegisterConsensusServer(server, raft)
+		go raft.Run()
+		log.Printf("Joining cluster as %s with %d other servers", *advertise, len(clusterPeers))
+	} else {
+		server = grpc.NewServer()
+	}
 	pb.RegisterCentralServerServer(server, centralServer)
 
 	// Start monitoring peer health.
 	go centralServer.MonitorPeers()
+	// Evict contributors that stop heartbeating.
+	go centralServer.MonitorContributors()
+	// Keep every file on replicationFactor contributors.
+	go centralServer.replication.Run()
+
+	// Stop gracefully on Ctrl+C so the store gets compacted on the way out.
+	go func() {
+		sigs := make(chan os.Signal, 1)
+		signal.Notify(sigs, os.Interrupt, syscall.SIGTERM)
+		<-sigs
+		log.Printf("Shutting down...")
+		server.GracefulStop()
+	}()
 	log.Printf("Central Server running on port %s...", *port)
 	if err := server.Serve(listener); err != nil {
 		log.Fatalf("Failed to serve: %v", err)
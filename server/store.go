@@ -0,0 +1,361 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// IndexEntry is the durable record kept for every uploaded file.
+type IndexEntry struct {
+	FileName     string   `json:"file_name"`
+	TorrentFile  string   `json:"torrent_file"` // name of the .torrent inside TORRENTS_DIR
+	Peers        []string `json:"peers"`
+	Contributors []string `json:"contributors"`
+	Shards       []string `json:"shards,omitempty"`      // erasure-coded files: shard number -> holder
+	DataShards   int      `json:"data_shards,omitempty"` // shards needed to rebuild the file
+}
+
+// storeRecord is a single line of the write-ahead log.
+type storeRecord struct {
+	Op    string     `json:"op"` // "put" or "del"
+	Entry IndexEntry `json:"entry"`
+}
+
+const (
+	storeLogFile      = "index.log"
+	storeSnapshotFile = "index.snapshot"
+	compactEvery      = 1000 // log records before the log is folded into the snapshot
+)
+
+// Store is a small embedded key-value store for the file index.
+// Every mutation is appended to a checksummed log and fsynced before it is
+// applied in memory; the log is periodically compacted into a snapshot that
+// is replaced atomically. A torn record at the end of the log (crash while
+// writing) is detected by its checksum and truncated on open; one left by a
+// failed write is cut off right away, so later records never follow it.
+type Store struct {
+	mu         sync.RWMutex
+	dir        string
+	entries    map[string]IndexEntry
+	logFile    *os.File
+	logOffset  int64 // end of the last intact record
+	logRecords int
+	broken     error // set when a failed write could not be undone
+}
+
+// OpenStore loads the snapshot and replays the log found in dir.
+func OpenStore(dir string) (*Store, error) {
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		return nil, err
+	}
+	s := &Store{
+		dir:     dir,
+		entries: make(map[string]IndexEntry),
+	}
+
+	if err := s.loadSnapshot(); err != nil {
+		return nil, err
+	}
+	if err := s.replayLog(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *Store) loadSnapshot() error {
+	data, err := os.ReadFile(filepath.Join(s.dir, storeSnapshotFile))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	var entries []IndexEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return fmt.Errorf("corrupt snapshot: %v", err)
+	}
+	for _, e := range entries {
+		s.entries[e.FileName] = e
+	}
+	return nil
+}
+
+// replayLog applies every intact log record and truncates anything after the
+// first damaged one.
+func (s *Store) replayLog() error {
+	path := filepath.Join(s.dir, storeLogFile)
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return err
+	}
+
+	reader := bufio.NewReader(f)
+	var offset int64
+	for {
+		line, err := reader.ReadString('\n')
+		if err == io.EOF {
+			if len(line) > 0 {
+				log.Printf("Store: dropping incomplete log record at offset %d", offset)
+			}
+			break
+		}
+		if err != nil {
+			f.Close()
+			return err
+		}
+		rec, ok := decodeRecord(line)
+		if !ok {
+			log.Printf("Store: dropping damaged log record at offset %d", offset)
+			break
+		}
+		s.apply(rec)
+		s.logRecords++
+		offset += int64(len(line))
+	}
+
+	if err := f.Truncate(offset); err != nil {
+		f.Close()
+		return err
+	}
+	if _, err := f.Seek(offset, io.SeekStart); err != nil {
+		f.Close()
+		return err
+	}
+	s.logFile = f
+	s.logOffset = offset
+	return nil
+}
+
+// encodeRecord formats a record as "<crc32> <json>\n".
+func encodeRecord(rec storeRecord) ([]byte, error) {
+	payload, err := json.Marshal(rec)
+	if err != nil {
+		return nil, err
+	}
+	return checksumLine(payload), nil
+}
+
+func decodeRecord(line string) (storeRecord, bool) {
+	var rec storeRecord
+	payload, ok := verifyLine(line)
+	if !ok {
+		return rec, false
+	}
+	if err := json.Unmarshal(payload, &rec); err != nil {
+		return rec, false
+	}
+	return rec, true
+}
+
+// checksumLine prefixes payload with its CRC-32 so a torn write can be told
+// apart from an intact line.
+func checksumLine(payload []byte) []byte {
+	return []byte(fmt.Sprintf("%08x %s\n", crc32.ChecksumIEEE(payload), payload))
+}
+
+// verifyLine returns the payload of a line written by checksumLine.
+func verifyLine(line string) ([]byte, bool) {
+	line = strings.TrimSuffix(line, "\n")
+	sum, payload, found := strings.Cut(line, " ")
+	if !found || fmt.Sprintf("%08x", crc32.ChecksumIEEE([]byte(payload))) != sum {
+		return nil, false
+	}
+	return []byte(payload), true
+}
+
+func (s *Store) apply(rec storeRecord) {
+	switch rec.Op {
+	case "put":
+		s.entries[rec.Entry.FileName] = rec.Entry
+	case "del":
+		delete(s.entries, rec.Entry.FileName)
+	}
+}
+
+func (s *Store) write(rec storeRecord) error {
+	data, err := encodeRecord(rec)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.broken != nil {
+		return fmt.Errorf("store needs reopening: %v", s.broken)
+	}
+	if _, err := s.logFile.Write(data); err != nil {
+		s.rollback(err)
+		return err
+	}
+	if err := s.logFile.Sync(); err != nil {
+		s.rollback(err)
+		return err
+	}
+	s.apply(rec)
+	s.logRecords++
+	s.logOffset += int64(len(data))
+
+	if s.logRecords >= compactEvery {
+		if err := s.compact(); err != nil {
+			log.Printf("Store: compaction failed: %v", err)
+		}
+	}
+	return nil
+}
+
+// rollback cuts the log back to its last intact record after a write failed
+// partway (a full disk, say), so the next record is not appended after torn
+// bytes that would hide it on the next open. If that fails as well, the
+// store takes no more writes until it is reopened. Callers must hold s.mu.
+func (s *Store) rollback(cause error) {
+	if err := s.logFile.Truncate(s.logOffset); err != nil {
+		s.broken = fmt.Errorf("%v, then truncating the log: %v", cause, err)
+	} else if _, err := s.logFile.Seek(s.logOffset, io.SeekStart); err != nil {
+		s.broken = fmt.Errorf("%v, then seeking the log: %v", cause, err)
+	}
+	if s.broken != nil {
+		log.Printf("Store: refusing writes: %v", s.broken)
+	}
+}
+
+// compact writes the current state to a new snapshot and empties the log.
+// Callers must hold s.mu.
+func (s *Store) compact() error {
+	entries := make([]IndexEntry, 0, len(s.entries))
+	for _, e := range s.entries {
+		entries = append(entries, e)
+	}
+	data, err := json.Marshal(entries)
+	if err != nil {
+		return err
+	}
+	if err := writeFileAtomic(filepath.Join(s.dir, storeSnapshotFile), data); err != nil {
+		return err
+	}
+	if err := s.logFile.Truncate(0); err != nil {
+		return err
+	}
+	if _, err := s.logFile.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	s.logOffset = 0
+	s.logRecords = 0
+	return s.logFile.Sync()
+}
+
+// Put inserts or replaces the entry for e.FileName.
+func (s *Store) Put(e IndexEntry) error {
+	return s.write(storeRecord{Op: "put", Entry: e})
+}
+
+// Delete removes the entry for fileName.
+func (s *Store) Delete(fileName string) error {
+	return s.write(storeRecord{Op: "del", Entry: IndexEntry{FileName: fileName}})
+}
+
+// Reset replaces every entry with entries, which become the new snapshot.
+func (s *Store) Reset(entries []IndexEntry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.broken != nil {
+		return fmt.Errorf("store needs reopening: %v", s.broken)
+	}
+	old := s.entries
+	s.entries = make(map[string]IndexEntry, len(entries))
+	for _, e := range entries {
+		s.entries[e.FileName] = cloneEntry(e)
+	}
+	if err := s.compact(); err != nil {
+		s.entries = old
+		return err
+	}
+	return nil
+}
+
+// Get returns a copy of the entry for fileName.
+func (s *Store) Get(fileName string) (IndexEntry, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	e, ok := s.entries[fileName]
+	if !ok {
+		return IndexEntry{}, false
+	}
+	return cloneEntry(e), true
+}
+
+// All returns a copy of every entry, ordered by file name.
+func (s *Store) All() []IndexEntry {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	entries := make([]IndexEntry, 0, len(s.entries))
+	for _, e := range s.entries {
+		entries = append(entries, cloneEntry(e))
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].FileName < entries[j].FileName
+	})
+	return entries
+}
+
+// Close compacts the log and releases the log file.
+func (s *Store) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.compact(); err != nil {
+		log.Printf("Store: compaction on close failed: %v", err)
+	}
+	return s.logFile.Close()
+}
+
+func cloneEntry(e IndexEntry) IndexEntry {
+	e.Peers = append([]string(nil), e.Peers...)
+	e.Contributors = append([]string(nil), e.Contributors...)
+	if e.Shards != nil {
+		e.Shards = append([]string(nil), e.Shards...)
+	}
+	return e
+}
+
+// writeFileAtomic writes data to a temporary file, fsyncs it and renames it
+// over path, so readers never observe a partially written file.
+func writeFileAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	tmpPath := tmp.Name()
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := os.Chmod(tmpPath, 0644); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+	return os.Rename(tmpPath, path)
+}
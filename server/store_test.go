@@ -0,0 +1,137 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func storeNames(s *Store) []string {
+	var names []string
+	for _, e := range s.All() {
+		names = append(names, e.FileName)
+	}
+	return names
+}
+
+func openTestStore(t testing.TB, dir string) *Store {
+	t.Helper()
+	s, err := OpenStore(dir)
+	if err != nil {
+		t.Fatalf("OpenStore: %v", err)
+	}
+	return s
+}
+
+func TestStoreRecoversDamagedLogTail(t *testing.T) {
+	tests := []struct {
+		name string
+		tail string // appended to a log holding a.mp3 and b.mp3
+	}{
+		{"incomplete record", `1234abcd {"op":"put","entry":{"file_na`},
+		{"bad checksum", "00000000 {\"op\":\"put\",\"entry\":{\"file_name\":\"c.mp3\"}}\n"},
+		{"garbage line", "not a record\n"},
+		{"no checksum", "{\"op\":\"put\",\"entry\":{\"file_name\":\"c.mp3\"}}\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			s := openTestStore(t, dir)
+			for _, name := range []string{"a.mp3", "b.mp3"} {
+				if err := s.Put(IndexEntry{FileName: name, Peers: []string{"localhost:7001"}}); err != nil {
+					t.Fatalf("Put %s: %v", name, err)
+				}
+			}
+			// Leave the log as a crash would, without compacting it on Close.
+			s.logFile.Close()
+
+			f, err := os.OpenFile(filepath.Join(dir, storeLogFile), os.O_APPEND|os.O_WRONLY, 0644)
+			if err != nil {
+				t.Fatal(err)
+			}
+			f.WriteString(tt.tail)
+			f.Close()
+
+			s = openTestStore(t, dir)
+			if got := storeNames(s); !slices.Equal(got, []string{"a.mp3", "b.mp3"}) {
+				t.Fatalf("entries after reopen = %v, want [a.mp3 b.mp3]", got)
+			}
+			if err := s.Put(IndexEntry{FileName: "d.mp3"}); err != nil {
+				t.Fatalf("Put after recovery: %v", err)
+			}
+			s.logFile.Close()
+
+			s = openTestStore(t, dir)
+			defer s.Close()
+			if got := storeNames(s); !slices.Equal(got, []string{"a.mp3", "b.mp3", "d.mp3"}) {
+				t.Fatalf("entries after second reopen = %v, want [a.mp3 b.mp3 d.mp3]", got)
+			}
+		})
+	}
+}
+
+func TestStoreRollsBackFailedWrite(t *testing.T) {
+	dir := t.TempDir()
+	s := openTestStore(t, dir)
+	if err := s.Put(IndexEntry{FileName: "a.mp3"}); err != nil {
+		t.Fatal(err)
+	}
+
+	// A write that failed partway leaves part of its record behind.
+	s.mu.Lock()
+	s.logFile.WriteString(`1234abcd {"op":"put","ent`)
+	s.rollback(errors.New("no space left on device"))
+	s.mu.Unlock()
+
+	if err := s.Put(IndexEntry{FileName: "b.mp3"}); err != nil {
+		t.Fatalf("Put after rollback: %v", err)
+	}
+	s.logFile.Close()
+
+	s = openTestStore(t, dir)
+	defer s.Close()
+	if got := storeNames(s); !slices.Equal(got, []string{"a.mp3", "b.mp3"}) {
+		t.Fatalf("entries after reopen = %v, want [a.mp3 b.mp3]", got)
+	}
+}
+
+func TestStoreRefusesWritesWhenRollbackFails(t *testing.T) {
+	s := openTestStore(t, t.TempDir())
+	s.logFile.Close() // every write and truncate now fails
+
+	if err := s.Put(IndexEntry{FileName: "a.mp3"}); err == nil {
+		t.Fatal("Put on a closed log succeeded")
+	}
+	if s.broken == nil {
+		t.Fatal("store still takes writes after a failed rollback")
+	}
+	if err := s.Put(IndexEntry{FileName: "b.mp3"}); err == nil {
+		t.Fatal("Put on a broken store succeeded")
+	}
+}
+
+func TestStoreCompactsAndReopens(t *testing.T) {
+	dir := t.TempDir()
+	s := openTestStore(t, dir)
+	for i := range compactEvery + 5 {
+		name := "song" + string(rune('a'+i%26)) + ".mp3"
+		if err := s.Put(IndexEntry{FileName: name}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := s.Delete("songa.mp3"); err != nil {
+		t.Fatal(err)
+	}
+	want := storeNames(s)
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	s = openTestStore(t, dir)
+	defer s.Close()
+	if got := storeNames(s); !slices.Equal(got, want) {
+		t.Fatalf("entries after reopen = %v, want %v", got, want)
+	}
+}
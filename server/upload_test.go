@@ -0,0 +1,220 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"regexp"
+	"testing"
+
+	"google.golang.org/grpc"
+
+	pb "napster"
+	"napster/client"
+)
+
+// uploadStream feeds UploadFile a prepared list of chunks.
+type uploadStream struct {
+	grpc.ServerStream
+	chunks []*pb.FileChunk
+	res    *pb.UploadResponse
+}
+
+func (u *uploadStream) Context() context.Context { return context.Background() }
+
+func (u *uploadStream) Recv() (*pb.FileChunk, error) {
+	if len(u.chunks) == 0 {
+		return nil, io.EOF
+	}
+	chunk := u.chunks[0]
+	u.chunks = u.chunks[1:]
+	return chunk, nil
+}
+
+func (u *uploadStream) SendAndClose(res *pb.UploadResponse) error {
+	u.res = res
+	return nil
+}
+
+// uploadChunks splits data as a peer uploads it, declaring size and checksum
+// of declared (the data itself unless a forgery is tested).
+func uploadChunks(peer string, data []byte, declared []byte) []*pb.FileChunk {
+	sum := sha256.Sum256(declared)
+	var chunks []*pb.FileChunk
+	for offset := 0; offset < len(data); offset += ChunkSize {
+		chunks = append(chunks, &pb.FileChunk{ChunkData: data[offset:min(offset+ChunkSize, len(data))]})
+	}
+	chunks[0].FileName = "song.mp3"
+	chunks[0].PeerAddress = peer
+	chunks[0].DeclaredSize = int64(len(declared))
+	chunks[0].Checksum = hex.EncodeToString(sum[:])
+	return chunks
+}
+
+func TestUploadDeduplication(t *testing.T) {
+	oldTorrents := TORRENTS_DIR
+	TORRENTS_DIR = t.TempDir()
+	defer func() { TORRENTS_DIR = oldTorrents }()
+
+	store := openTestStore(t, t.TempDir())
+	defer store.Close()
+	s := NewCentralServer(store)
+
+	song := bytes.Repeat([]byte("la"), ChunkSize+1000) // three chunks
+	forged := bytes.Clone(song)
+	forged[len(forged)-1] ^= 1
+
+	stream := &uploadStream{chunks: uploadChunks("uploader", song, song)}
+	if err := s.UploadFile(stream); err != nil || stream.res.Status != 200 || stream.res.Deduplicated {
+		t.Fatalf("first upload: %v %+v", err, stream.res)
+	}
+	canonical := stream.res.RenamedFileName
+
+	tests := []struct {
+		name    string
+		peer    string
+		chunks  []*pb.FileChunk
+		dedup   bool
+		seeding bool
+	}{
+		{"same content", "second", uploadChunks("second", song, song), true, true},
+		{"checksum only", "liar1", uploadChunks("liar1", song[:ChunkSize], song), false, false},
+		{"other content", "liar2", uploadChunks("liar2", forged, song), false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stream := &uploadStream{chunks: tt.chunks}
+			if err := s.UploadFile(stream); err != nil {
+				t.Fatal(err)
+			}
+			if stream.res.Deduplicated != tt.dedup || (tt.dedup && stream.res.RenamedFileName != canonical) {
+				t.Errorf("response = %+v, want deduplicated %v into %s", stream.res, tt.dedup, canonical)
+			}
+			entry, _ := store.Get(canonical)
+			if containsString(entry.Peers, tt.peer) != tt.seeding {
+				t.Errorf("peers of %s = %v, %s seeding should be %v", canonical, entry.Peers, tt.peer, tt.seeding)
+			}
+		})
+	}
+	if n := len(store.All()); n != 1 {
+		t.Errorf("%d files indexed, want 1", n)
+	}
+}
+
+func TestUploadValidation(t *testing.T) {
+	oldTorrents := TORRENTS_DIR
+	TORRENTS_DIR = t.TempDir()
+	defer func() { TORRENTS_DIR = oldTorrents }()
+
+	store := openTestStore(t, t.TempDir())
+	defer store.Close()
+	s := NewCentralServer(store)
+
+	song := bytes.Repeat([]byte("do"), ChunkSize+1000) // three chunks
+	other := bytes.Clone(song)
+	other[0] ^= 1
+
+	// resized re-splits an upload of song into chunks of the given sizes,
+	// the last one taking the rest.
+	resized := func(sizes ...int) []*pb.FileChunk {
+		first := uploadChunks("uploader", song, song)[0]
+		data := song
+		var chunks []*pb.FileChunk
+		for i, size := range sizes {
+			if i == len(sizes)-1 {
+				size = len(data)
+			}
+			chunks = append(chunks, &pb.FileChunk{ChunkData: data[:size]})
+			data = data[size:]
+		}
+		first.ChunkData = chunks[0].ChunkData
+		chunks[0] = first
+		return chunks
+	}
+	declaring := func(size int64) []*pb.FileChunk {
+		chunks := uploadChunks("uploader", song, song)
+		chunks[0].DeclaredSize = size
+		return chunks
+	}
+
+	tests := []struct {
+		name   string
+		chunks []*pb.FileChunk
+		status int32
+	}{
+		{"valid", uploadChunks("uploader", song, song), 200},
+		{"declares more", declaring(int64(len(song)) + 1), 422},
+		{"declares less", declaring(int64(len(song)) - 1), 422},
+		{"no declared size", declaring(0), 400},
+		{"checksum mismatch", uploadChunks("uploader", other, song), 422},
+		{"short chunk before the last", resized(ChunkSize-1, ChunkSize, 0), 400},
+		{"oversized chunk", resized(ChunkSize+1, 0), 400},
+		{"empty chunk", resized(ChunkSize, 0, ChunkSize, 0), 400},
+		{"empty file", nil, 400},
+		{"empty first chunk", []*pb.FileChunk{{FileName: "song.mp3", PeerAddress: "uploader", DeclaredSize: 1, Checksum: "00"}}, 400},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			indexed := len(store.All())
+			stream := &uploadStream{chunks: tt.chunks}
+			if err := s.UploadFile(stream); err != nil {
+				t.Fatal(err)
+			}
+			if stream.res.Status != tt.status {
+				t.Fatalf("status = %d (%s), want %d", stream.res.Status, stream.res.Message, tt.status)
+			}
+			if added := len(store.All()) - indexed; tt.status != 200 && added != 0 {
+				t.Errorf("rejected upload indexed %d files", added)
+			}
+		})
+	}
+}
+
+// Uploads keep their file name unless it is taken; same-named songs get
+// distinct names, so their chunks never share a key.
+func TestUploadFileNames(t *testing.T) {
+	oldTorrents := TORRENTS_DIR
+	TORRENTS_DIR = t.TempDir()
+	defer func() { TORRENTS_DIR = oldTorrents }()
+
+	store := openTestStore(t, t.TempDir())
+	defer store.Close()
+	s := NewCentralServer(store)
+
+	var names []string
+	for i, word := range []string{"la", "si", "do"} {
+		song := bytes.Repeat([]byte(word), 1000)
+		stream := &uploadStream{chunks: uploadChunks(fmt.Sprintf("uploader%d", i), song, song)}
+		if err := s.UploadFile(stream); err != nil || stream.res.Status != 200 {
+			t.Fatalf("upload %d: %v %+v", i, err, stream.res)
+		}
+		names = append(names, stream.res.RenamedFileName)
+	}
+	if names[0] != "song.mp3" {
+		t.Errorf("first upload renamed to %s", names[0])
+	}
+	suffixed := regexp.MustCompile(`^song_[a-zA-Z0-9]{6}\.mp3$`)
+	chunkKeys := make(map[string]bool)
+	for i, name := range names {
+		if i > 0 && !suffixed.MatchString(name) {
+			t.Errorf("upload %d named %s, want song_XXXXXX.mp3", i, name)
+		}
+		chunkKeys[client.GetChunkName(name, 0)] = true
+	}
+	if len(chunkKeys) != len(names) || len(store.All()) != len(names) {
+		t.Errorf("names %v give %d chunk keys, %d files indexed", names, len(chunkKeys), len(store.All()))
+	}
+
+	// A name stays claimed while its upload is in progress.
+	first, second := s.uniqueFileName("live.mp3"), s.uniqueFileName("live.mp3")
+	if first != "live.mp3" || second == first {
+		t.Errorf("concurrent uploads named %s and %s", first, second)
+	}
+	s.releaseFileName(first)
+	if name := s.uniqueFileName("live.mp3"); name != "live.mp3" {
+		t.Errorf("released name not reused: %s", name)
+	}
+}